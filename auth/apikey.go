@@ -0,0 +1,358 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	errorutil "goyave.dev/goyave/v5/util/errors"
+)
+
+const (
+	// MetaAPIKeyScopes the `APIKeyAuthenticator` requires the API key to have
+	// all the scopes listed in this meta. The value can be a `string` or a `[]string`.
+	//
+	//	route.SetMeta(auth.MetaAPIKeyScopes, []string{"orders:read", "orders:write"})
+	MetaAPIKeyScopes = "goyave.api-key-scopes"
+
+	// DefaultAPIKeyHeader the default name of the header containing the API key.
+	DefaultAPIKeyHeader = "X-API-Key"
+
+	apiKeySeparator = "."
+
+	// apiKeyUsageInterval the minimum duration between two updates of the last-used
+	// timestamp of an API key.
+	apiKeyUsageInterval = time.Minute
+
+	// apiKeyUsageQueueSize the maximum number of API keys waiting for their last-used
+	// timestamp to be updated. Further updates are dropped until the queue drains.
+	apiKeyUsageQueueSize = 256
+)
+
+// ExtraAPIKey when using the built-in `APIKeyAuthenticator`, this key can be used
+// to retrieve the authenticated `*APIKey[T]` in the request's `Extra`.
+type ExtraAPIKey struct{}
+
+// APIKey a stored API key. The key itself is never stored: only its public prefix,
+// used to find the record, and its hash.
+type APIKey[T any] struct {
+	// ExpiresAt the time after which the key is not valid anymore.
+	// The key never expires if zero.
+	ExpiresAt time.Time
+
+	// LastUsedAt the time at which the key was last used, as recorded by
+	// `APIKeyService.UpdateLastUsed`. The timestamp is not updated if it is
+	// less than a minute old.
+	LastUsedAt time.Time
+
+	// User the owner of the key, set as the request's `User` on successful authentication.
+	User *T
+
+	// ID an optional identifier, useful for `APIKeyService.UpdateLastUsed`.
+	ID any
+
+	// Prefix the public part of the key.
+	Prefix string
+
+	// Hash the hex-encoded SHA-256 hash of the full key, as returned by `HashAPIKey`.
+	Hash string
+
+	// Scopes the permissions granted to this key.
+	Scopes []string
+}
+
+// HasScope returns true if the API key is granted the given scope.
+func (k *APIKey[T]) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// APIKeyService is the dependency of `APIKeyAuthenticator` used to retrieve
+// API keys and record their usage.
+type APIKeyService[T any] interface {
+	// FindByPrefix returns the API key identified by the given public prefix.
+	// If the record could not be found, the error returned should be of type `gorm.ErrRecordNotFound`.
+	FindByPrefix(ctx context.Context, prefix string) (*APIKey[T], error)
+
+	// UpdateLastUsed records the time at which the given key was last used.
+	// This is executed in the background and doesn't block the request. The updates
+	// of all the keys are executed sequentially, at most once a minute for each key.
+	UpdateLastUsed(ctx context.Context, key *APIKey[T], lastUsed time.Time) error
+}
+
+// GeneratedAPIKey a new API key returned by `GenerateAPIKey`.
+type GeneratedAPIKey struct {
+	// Key the full key. It should be shown to the client once and never be stored.
+	Key string
+
+	// Prefix the public part of the key, to store alongside the hash.
+	Prefix string
+
+	// Hash the hex-encoded SHA-256 hash of the key, to store.
+	Hash string
+}
+
+// GenerateAPIKey generates a new random API key. The key has the following format:
+//
+//	{namespace}_{random prefix}.{secret}
+//
+// The namespace helps identifying the origin of leaked keys (e.g.: "myapp_live"). It
+// must not contain a dot. The secret contains 256 bits of entropy.
+func GenerateAPIKey(namespace string) (*GeneratedAPIKey, error) {
+	if strings.Contains(namespace, apiKeySeparator) {
+		return nil, errorutil.Errorf("API key namespace %q must not contain %q", namespace, apiKeySeparator)
+	}
+	b := make([]byte, 8+32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errorutil.New(err)
+	}
+	prefix := hex.EncodeToString(b[:8])
+	if namespace != "" {
+		prefix = namespace + "_" + prefix
+	}
+	key := prefix + apiKeySeparator + base64.RawURLEncoding.EncodeToString(b[8:])
+	return &GeneratedAPIKey{
+		Key:    key,
+		Prefix: prefix,
+		Hash:   HashAPIKey(key),
+	}, nil
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of the given key. API keys have
+// high entropy so a fast hash is sufficient and makes lookups cheap.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyScopeError returned by `APIKeyAuthenticator` when the key is valid
+// but lacks a required scope.
+type apiKeyScopeError struct {
+	message string
+}
+
+func (e *apiKeyScopeError) Error() string {
+	return e.message
+}
+
+// APIKeyAuthenticator implementation of Authenticator for machine-to-machine clients
+// using API keys generated with `GenerateAPIKey`.
+//
+// The key is read from the `HeaderName` header or, if enabled, the `QueryParam` query parameter.
+// The record is found using the key's prefix and the key is compared to the stored hash
+// in constant time. Expired keys are rejected.
+//
+// If the matched route has the `MetaAPIKeyScopes` meta, the key must have all the listed scopes.
+// Otherwise, the request is answered with "403 Forbidden".
+//
+// On success, the `*APIKey[T]` is added to `request.Extra` with the key `ExtraAPIKey` and
+// its last-used timestamp is updated in the background if it is more than a minute old.
+// The updates are queued and executed one at a time. If the queue is full, the update is
+// dropped. The remaining updates are executed when the server stops.
+//
+// The T parameter represents the user DTO and should not be a pointer.
+type APIKeyAuthenticator[T any] struct {
+	goyave.Component
+
+	KeyService APIKeyService[T]
+
+	// HeaderName the name of the header containing the key.
+	// Defaults to `DefaultAPIKeyHeader`.
+	HeaderName string
+
+	// QueryParam the name of the query parameter containing the key. Reading
+	// the key from the query is disabled if empty. Keys passed in URLs may end up in
+	// logs and browser history: prefer headers whenever possible.
+	QueryParam string
+
+	// Optional defines if the authenticator allows requests that
+	// don't provide credentials. Handlers should therefore check
+	// if `request.User` is not `nil` before accessing it.
+	Optional bool
+
+	usage *apiKeyUsageWriter[T]
+}
+
+// NewAPIKeyAuthenticator create a new authenticator for API keys read from the `X-API-Key` header.
+func NewAPIKeyAuthenticator[T any](keyService APIKeyService[T]) *APIKeyAuthenticator[T] {
+	return &APIKeyAuthenticator[T]{
+		KeyService: keyService,
+	}
+}
+
+// Init the authenticator and start the background writer updating the last-used timestamps.
+// The writer is stopped by a shutdown hook once its queue is drained.
+func (a *APIKeyAuthenticator[T]) Init(server *goyave.Server) {
+	a.Component.Init(server)
+	if a.usage != nil {
+		return
+	}
+	a.usage = newAPIKeyUsageWriter(a.KeyService, apiKeyUsageQueueSize)
+	a.usage.Init(server)
+	server.RegisterShutdownHook(func(_ *goyave.Server) {
+		a.usage.Close()
+	})
+}
+
+// Authenticate fetch the user owning the API key found in the given request and returns it.
+// If no user can be authenticated, returns an error.
+func (a *APIKeyAuthenticator[T]) Authenticate(request *goyave.Request) (*T, error) {
+	key := a.getKey(request)
+	if key == "" {
+		if a.Optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s", request.Lang.Get("auth.api-key-required"))
+	}
+
+	prefix, _, ok := strings.Cut(key, apiKeySeparator)
+	if !ok {
+		return nil, fmt.Errorf("%s", request.Lang.Get("auth.api-key-invalid"))
+	}
+
+	apiKey, err := a.KeyService.FindByPrefix(request.Context(), prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", request.Lang.Get("auth.api-key-invalid"))
+		}
+		panic(errorutil.New(err))
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(apiKey.Hash)) != 1 {
+		return nil, fmt.Errorf("%s", request.Lang.Get("auth.api-key-invalid"))
+	}
+
+	now := time.Now()
+	if !apiKey.ExpiresAt.IsZero() && now.After(apiKey.ExpiresAt) {
+		return nil, fmt.Errorf("%s", request.Lang.Get("auth.api-key-expired"))
+	}
+
+	if scopes := lookupMetaList(request, MetaAPIKeyScopes); scopes != nil {
+		for _, scope := range scopes {
+			if !apiKey.HasScope(scope) {
+				return nil, &apiKeyScopeError{message: request.Lang.Get("auth.api-key-forbidden")}
+			}
+		}
+	}
+
+	request.Extra[ExtraAPIKey{}] = apiKey
+	if now.Sub(apiKey.LastUsedAt) >= apiKeyUsageInterval {
+		a.usage.record(apiKey, now)
+	}
+	return apiKey.User, nil
+}
+
+func (a *APIKeyAuthenticator[T]) getKey(request *goyave.Request) string {
+	headerName := a.HeaderName
+	if headerName == "" {
+		headerName = DefaultAPIKeyHeader
+	}
+	if key := strings.TrimSpace(request.Header().Get(headerName)); key != "" {
+		return key
+	}
+	if a.QueryParam != "" {
+		return request.URL().Query().Get(a.QueryParam)
+	}
+	return ""
+}
+
+// HasCredentials returns true if the request contains an API key.
+func (a *APIKeyAuthenticator[T]) HasCredentials(request *goyave.Request) bool {
+	return a.getKey(request) != ""
+}
+
+// OnUnauthorized responds with "403 Forbidden" if the key lacks a required scope,
+// and with "401 Unauthorized" otherwise.
+func (a *APIKeyAuthenticator[T]) OnUnauthorized(response *goyave.Response, _ *goyave.Request, err error) {
+	status := http.StatusUnauthorized
+	var scopeErr *apiKeyScopeError
+	if errors.As(err, &scopeErr) {
+		status = http.StatusForbidden
+	}
+	response.JSON(status, map[string]string{"error": err.Error()})
+}
+
+type apiKeyUsage[T any] struct {
+	lastUsed time.Time
+	key      *APIKey[T]
+}
+
+// apiKeyUsageWriter updates the last-used timestamps of the API keys in a single
+// background goroutine. The updates are coalesced per key prefix: if a key is already
+// waiting in the queue, only its timestamp is updated.
+type apiKeyUsageWriter[T any] struct {
+	goyave.Component
+	service APIKeyService[T]
+	queue   chan string
+	pending map[string]apiKeyUsage[T]
+	done    chan struct{}
+	mu      sync.Mutex
+	closed  bool
+}
+
+func newAPIKeyUsageWriter[T any](service APIKeyService[T], queueSize int) *apiKeyUsageWriter[T] {
+	w := &apiKeyUsageWriter[T]{
+		service: service,
+		queue:   make(chan string, queueSize),
+		pending: make(map[string]apiKeyUsage[T], queueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// record queues the update of the last-used timestamp of the given key. The update is
+// dropped if the queue is full or if the writer is closed.
+func (w *apiKeyUsageWriter[T]) record(key *APIKey[T], lastUsed time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	if _, ok := w.pending[key.Prefix]; !ok {
+		select {
+		case w.queue <- key.Prefix:
+		default:
+			return
+		}
+	}
+	w.pending[key.Prefix] = apiKeyUsage[T]{key: key, lastUsed: lastUsed}
+}
+
+func (w *apiKeyUsageWriter[T]) run() {
+	defer close(w.done)
+	for prefix := range w.queue {
+		w.mu.Lock()
+		usage := w.pending[prefix]
+		delete(w.pending, prefix)
+		w.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := w.service.UpdateLastUsed(ctx, usage.key, usage.lastUsed); err != nil {
+			w.Logger().Error(errorutil.New(err))
+		}
+		cancel()
+	}
+}
+
+// Close stops accepting new updates and waits for the queued updates to be executed.
+func (w *apiKeyUsageWriter[T]) Close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
@@ -0,0 +1,313 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+type mockAPIKeyService struct {
+	keys     map[string]*APIKey[TestUser]
+	findErr  error
+	usageErr error
+	used     chan time.Time
+}
+
+func (s *mockAPIKeyService) FindByPrefix(_ context.Context, prefix string) (*APIKey[TestUser], error) {
+	if s.findErr != nil {
+		return nil, s.findErr
+	}
+	key, ok := s.keys[prefix]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return key, nil
+}
+
+func (s *mockAPIKeyService) UpdateLastUsed(_ context.Context, _ *APIKey[TestUser], lastUsed time.Time) error {
+	s.used <- lastUsed
+	return s.usageErr
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := GenerateAPIKey("myapp_live")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key.Prefix, "myapp_live_"))
+	assert.Len(t, key.Prefix, len("myapp_live_")+16)
+	assert.True(t, strings.HasPrefix(key.Key, key.Prefix+"."))
+	assert.Len(t, key.Key, len(key.Prefix)+1+43)
+	assert.Equal(t, HashAPIKey(key.Key), key.Hash)
+	assert.NotContains(t, key.Hash, key.Key)
+
+	key2, err := GenerateAPIKey("")
+	require.NoError(t, err)
+	assert.Len(t, key2.Prefix, 16)
+	assert.NotEqual(t, key.Key, key2.Key)
+
+	key3, err := GenerateAPIKey("my.app")
+	assert.Nil(t, key3)
+	require.Error(t, err)
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	prepare := func(t *testing.T) (*testutil.TestServer, *mockAPIKeyService, *GeneratedAPIKey, *TestUser) {
+		cfg := config.LoadDefault()
+		cfg.Set("app.debug", false)
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+		generated, err := GenerateAPIKey("test")
+		require.NoError(t, err)
+		user := &TestUser{Name: "machine"}
+		service := &mockAPIKeyService{
+			keys: map[string]*APIKey[TestUser]{
+				generated.Prefix: {
+					ID:     1,
+					User:   user,
+					Prefix: generated.Prefix,
+					Hash:   generated.Hash,
+					Scopes: []string{"orders:read"},
+				},
+			},
+			used: make(chan time.Time, 10),
+		}
+		return server, service, generated, user
+	}
+
+	t.Run("header", func(t *testing.T) {
+		server, service, generated, user := prepare(t)
+		authenticator := NewAPIKeyAuthenticator[TestUser](service)
+		authenticator.Init(server.Server)
+
+		request := server.NewTestRequest(http.MethodGet, "/", nil)
+		request.Header().Set(DefaultAPIKeyHeader, generated.Key)
+		u, err := authenticator.Authenticate(request)
+		require.NoError(t, err)
+		assert.Same(t, user, u)
+		assert.Same(t, service.keys[generated.Prefix], request.Extra[ExtraAPIKey{}])
+
+		select {
+		case lastUsed := <-service.used:
+			assert.WithinDuration(t, time.Now(), lastUsed, time.Second)
+		case <-time.After(time.Second):
+			assert.Fail(t, "last used timestamp not updated")
+		}
+	})
+
+	t.Run("custom_header_and_query", func(t *testing.T) {
+		server, service, generated, user := prepare(t)
+		authenticator := &APIKeyAuthenticator[TestUser]{KeyService: service, HeaderName: "X-Custom-Key", QueryParam: "api_key"}
+		authenticator.Init(server.Server)
+
+		request := server.NewTestRequest(http.MethodGet, "/", nil)
+		request.Header().Set("X-Custom-Key", generated.Key)
+		u, err := authenticator.Authenticate(request)
+		require.NoError(t, err)
+		assert.Same(t, user, u)
+
+		request = server.NewTestRequest(http.MethodGet, "/?api_key="+generated.Key, nil)
+		u, err = authenticator.Authenticate(request)
+		require.NoError(t, err)
+		assert.Same(t, user, u)
+
+		// Query disabled by default
+		authenticator.QueryParam = ""
+		request = server.NewTestRequest(http.MethodGet, "/?api_key="+generated.Key, nil)
+		u, err = authenticator.Authenticate(request)
+		assert.Nil(t, u)
+		require.EqualError(t, err, server.Lang.GetDefault().Get("auth.api-key-required"))
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		server, service, _, _ := prepare(t)
+		authenticator := &APIKeyAuthenticator[TestUser]{KeyService: service, Optional: true}
+		authenticator.Init(server.Server)
+
+		u, err := authenticator.Authenticate(server.NewTestRequest(http.MethodGet, "/", nil))
+		assert.Nil(t, u)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid_keys", func(t *testing.T) {
+		server, service, generated, _ := prepare(t)
+		authenticator := NewAPIKeyAuthenticator[TestUser](service)
+		authenticator.Init(server.Server)
+		other, err := GenerateAPIKey("test")
+		require.NoError(t, err)
+
+		for _, key := range []string{"no-separator", other.Key, generated.Prefix + ".wrongsecret"} {
+			request := server.NewTestRequest(http.MethodGet, "/", nil)
+			request.Header().Set(DefaultAPIKeyHeader, key)
+			u, err := authenticator.Authenticate(request)
+			assert.Nil(t, u)
+			require.EqualError(t, err, server.Lang.GetDefault().Get("auth.api-key-invalid"))
+			assert.NotContains(t, request.Extra, ExtraAPIKey{})
+		}
+		assert.Empty(t, service.used)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		server, service, generated, _ := prepare(t)
+		authenticator := NewAPIKeyAuthenticator[TestUser](service)
+		authenticator.Init(server.Server)
+
+		service.keys[generated.Prefix].ExpiresAt = time.Now().Add(-time.Minute)
+		request := server.NewTestRequest(http.MethodGet, "/", nil)
+		request.Header().Set(DefaultAPIKeyHeader, generated.Key)
+		u, err := authenticator.Authenticate(request)
+		assert.Nil(t, u)
+		require.EqualError(t, err, server.Lang.GetDefault().Get("auth.api-key-expired"))
+
+		service.keys[generated.Prefix].ExpiresAt = time.Now().Add(time.Minute)
+		u, err = authenticator.Authenticate(request)
+		require.NoError(t, err)
+		assert.NotNil(t, u)
+	})
+
+	t.Run("service_error", func(t *testing.T) {
+		server, service, generated, _ := prepare(t)
+		authenticator := NewAPIKeyAuthenticator[TestUser](service)
+		authenticator.Init(server.Server)
+
+		service.findErr = fmt.Errorf("test error")
+		request := server.NewTestRequest(http.MethodGet, "/", nil)
+		request.Header().Set(DefaultAPIKeyHeader, generated.Key)
+		assert.Panics(t, func() {
+			_, _ = authenticator.Authenticate(request)
+		})
+	})
+
+	t.Run("usage_error_is_logged", func(t *testing.T) {
+		server, service, generated, _ := prepare(t)
+		authenticator := NewAPIKeyAuthenticator[TestUser](service)
+		authenticator.Init(server.Server)
+		service.usageErr = fmt.Errorf("test error")
+
+		request := server.NewTestRequest(http.MethodGet, "/", nil)
+		request.Header().Set(DefaultAPIKeyHeader, generated.Key)
+		_, err := authenticator.Authenticate(request)
+		require.NoError(t, err)
+		<-service.used
+	})
+
+	t.Run("last_used_throttled", func(t *testing.T) {
+		server, service, generated, _ := prepare(t)
+		authenticator := NewAPIKeyAuthenticator[TestUser](service)
+		authenticator.Init(server.Server)
+		usage := authenticator.usage
+		authenticator.Init(server.Server) // The writer is only created once
+		assert.Same(t, usage, authenticator.usage)
+
+		service.keys[generated.Prefix].LastUsedAt = time.Now().Add(-30 * time.Second)
+		request := server.NewTestRequest(http.MethodGet, "/", nil)
+		request.Header().Set(DefaultAPIKeyHeader, generated.Key)
+		_, err := authenticator.Authenticate(request)
+		require.NoError(t, err)
+
+		service.keys[generated.Prefix].LastUsedAt = time.Now().Add(-2 * time.Minute)
+		_, err = authenticator.Authenticate(request)
+		require.NoError(t, err)
+
+		authenticator.usage.Close()
+		assert.Len(t, service.used, 1)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		server, service, generated, _ := prepare(t)
+		router := goyave.NewRouter(server.Server)
+		router.GlobalMiddleware(Middleware[TestUser](NewAPIKeyAuthenticator[TestUser](service)))
+		router.SetMeta(MetaAuth, true)
+		handler := func(response *goyave.Response, _ *goyave.Request) {
+			response.Status(http.StatusNoContent)
+		}
+		router.Get("/orders", handler).SetMeta(MetaAPIKeyScopes, "orders:read")
+		router.Post("/orders", handler).SetMeta(MetaAPIKeyScopes, []string{"orders:read", "orders:write"})
+		router.Get("/profile", handler)
+
+		do := func(method, path, key string) *httptest.ResponseRecorder {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, nil)
+			if key != "" {
+				req.Header.Set(DefaultAPIKeyHeader, key)
+			}
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "/orders", generated.Key).Code)
+		assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "/profile", generated.Key).Code)
+
+		recorder := do(http.MethodPost, "/orders", generated.Key)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.JSONEq(t, `{"error":"`+server.Lang.GetDefault().Get("auth.api-key-forbidden")+`"}`, recorder.Body.String())
+
+		recorder = do(http.MethodGet, "/orders", "")
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.JSONEq(t, `{"error":"`+server.Lang.GetDefault().Get("auth.api-key-required")+`"}`, recorder.Body.String())
+
+		service.keys[generated.Prefix].Scopes = append(service.keys[generated.Prefix].Scopes, "orders:write")
+		assert.Equal(t, http.StatusNoContent, do(http.MethodPost, "/orders", generated.Key).Code)
+	})
+}
+
+type blockingAPIKeyService struct {
+	mockAPIKeyService
+	release chan struct{}
+	updated []string
+	mu      sync.Mutex
+}
+
+func (s *blockingAPIKeyService) UpdateLastUsed(_ context.Context, key *APIKey[TestUser], _ time.Time) error {
+	<-s.release
+	s.mu.Lock()
+	s.updated = append(s.updated, key.Prefix)
+	s.mu.Unlock()
+	return nil
+}
+
+func TestAPIKeyUsageWriter(t *testing.T) {
+	server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+	service := &blockingAPIKeyService{release: make(chan struct{})}
+	writer := newAPIKeyUsageWriter[TestUser](service, 2)
+	writer.Init(server.Server)
+
+	keyA := &APIKey[TestUser]{Prefix: "a"}
+	keyB := &APIKey[TestUser]{Prefix: "b"}
+	keyC := &APIKey[TestUser]{Prefix: "c"}
+
+	writer.record(keyA, time.Now())
+	require.Eventually(t, func() bool { // Picked up by the worker, blocked in UpdateLastUsed
+		writer.mu.Lock()
+		defer writer.mu.Unlock()
+		return len(writer.pending) == 0
+	}, time.Second, time.Millisecond)
+
+	lastUsed := time.Now()
+	writer.record(keyB, lastUsed.Add(-time.Second))
+	writer.record(keyB, lastUsed) // Coalesced
+	writer.record(keyC, lastUsed)
+	writer.record(keyA, lastUsed) // Queue full: dropped
+	writer.mu.Lock()
+	assert.Len(t, writer.pending, 2)
+	assert.Equal(t, lastUsed, writer.pending["b"].lastUsed)
+	writer.mu.Unlock()
+
+	close(service.release)
+	writer.Close()
+	assert.Equal(t, []string{"a", "b", "c"}, service.updated)
+
+	// Closed: further updates are ignored
+	writer.record(keyA, time.Now())
+	writer.Close()
+	assert.Len(t, service.updated, 3)
+}
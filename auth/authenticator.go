@@ -2,8 +2,8 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
 
 	"goyave.dev/goyave/v5"
 )
@@ -82,10 +82,12 @@ type Handler[T any] struct {
 // context using `UserFromContext`.
 //
 // Blocks if the authentication is not successful.
-// If the authenticator implements `SchemeAuthenticator`, add the `WWW-Authenticate` header
-// to the response.
+// If the authenticator implements `SchemeAuthenticator` or `MultiSchemeAuthenticator`, add the
+// `WWW-Authenticate` header to the response.
 // If the authenticator implements `Unauthorizer`, `OnUnauthorized` is called,
-// otherwise returns a default `401 Unauthorized` error.
+// otherwise returns a default `401 Unauthorized` error. If the error is a `*LockoutError`,
+// the `Retry-After` header is added instead of `WWW-Authenticate` and the default
+// response is `429 Too Many Requests`.
 // If the matched route doesn't contain the `MetaAuth` or if it's not equal to `true`,
 // the middleware is skipped.
 func (m *Handler[T]) Handle(next goyave.Handler) goyave.Handler {
@@ -95,30 +97,54 @@ func (m *Handler[T]) Handle(next goyave.Handler) goyave.Handler {
 			return
 		}
 
-		user, err := m.Authenticate(request)
-		if err != nil {
-			if authenticateHeader := m.getAuthenticateHeader(); authenticateHeader != "" {
-				response.Header().Set("WWW-Authenticate", authenticateHeader)
-			}
-			if unauthorizer, ok := m.Authenticator.(Unauthorizer); ok {
-				unauthorizer.OnUnauthorized(response, request, err)
-				return
-			}
-			response.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		if !m.AuthenticateRequest(response, request) {
 			return
 		}
-		request.User = user
-		request.WithContext(ContextWithUser(request.Context(), user))
 		next(response, request)
 	}
 }
 
-func (m *Handler[T]) getAuthenticateHeader() string {
-	sa, ok := m.Authenticator.(SchemeAuthenticator)
-	if !ok {
-		return ""
+// AuthenticateRequest authenticates the given request regardless of the `MetaAuth` route meta.
+// Returns true on success, after setting the request's `User` and injecting it in the
+// request's `context.Context`.
+//
+// On failure, writes the unauthorized response the same way `Handle` does and returns false.
+func (m *Handler[T]) AuthenticateRequest(response *goyave.Response, request *goyave.Request) bool {
+	user, err := m.Authenticate(request)
+	if err != nil {
+		var lockoutErr *LockoutError
+		if errors.As(err, &lockoutErr) {
+			response.Header().Set("Retry-After", lockoutErr.retryAfterHeader())
+		} else {
+			for _, authenticateHeader := range m.getAuthenticateHeaders() {
+				response.Header().Add("WWW-Authenticate", authenticateHeader)
+			}
+		}
+		if unauthorizer, ok := m.Authenticator.(Unauthorizer); ok {
+			unauthorizer.OnUnauthorized(response, request, err)
+			return false
+		}
+		writeUnauthorized(response, err)
+		return false
+	}
+	request.User = user
+	request.WithContext(ContextWithUser(request.Context(), user))
+	return true
+}
+
+func (m *Handler[T]) getAuthenticateHeaders() []string {
+	var schemes []string
+	switch sa := m.Authenticator.(type) {
+	case MultiSchemeAuthenticator:
+		schemes = sa.Schemes()
+	case SchemeAuthenticator:
+		schemes = []string{sa.Scheme()}
+	}
+	headers := make([]string, 0, len(schemes))
+	for _, scheme := range schemes {
+		headers = append(headers, fmt.Sprintf(`%s realm="%s", charset="UTF-8"`, scheme, m.Realm))
 	}
-	return fmt.Sprintf(`%s realm="%s", charset="UTF-8"`, sa.Scheme(), m.Realm)
+	return headers
 }
 
 // Middleware returns an authentication middleware which will use the given
@@ -131,6 +131,34 @@ func TestAuthenticator(t *testing.T) {
 		assert.Empty(t, resp.Header.Get("WWW-Authenticate"))
 	})
 
+	t.Run("AuthenticateRequest", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		t.Cleanup(func() { server.CloseDB() })
+
+		mockUserService := &MockUserService[TestUser]{user: user}
+		authenticator := Middleware(NewBasicAuthenticator(mockUserService, "Password"))
+		authenticator.Init(server.Server)
+
+		// Doesn't depend on MetaAuth
+		request := server.NewTestRequest(http.MethodGet, "/protected", nil)
+		request.Request().SetBasicAuth(user.Email, "secret")
+		response, _ := server.NewTestResponse(request)
+		assert.True(t, authenticator.AuthenticateRequest(response, request))
+		assert.Same(t, user, request.User)
+		assert.Same(t, user, UserFromContext[TestUser](request.Context()))
+		assert.True(t, response.IsEmpty())
+
+		request = server.NewTestRequest(http.MethodGet, "/protected", nil)
+		request.Request().SetBasicAuth(user.Email, "incorrect password")
+		response, recorder := server.NewTestResponse(request)
+		assert.False(t, authenticator.AuthenticateRequest(response, request))
+		assert.Nil(t, request.User)
+		res := recorder.Result()
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, `Basic realm="Authorization required", charset="UTF-8"`, res.Header.Get("WWW-Authenticate"))
+	})
+
 	t.Run("MiddlewareUnauthorizer", func(t *testing.T) {
 		server, user := prepareAuthenticatorTest(t)
 		t.Cleanup(func() { server.CloseDB() })
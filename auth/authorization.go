@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"net/http"
+	"reflect"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+const (
+	// PolicyServiceName identifier for the `PolicyService`.
+	PolicyServiceName = "goyave.policies"
+
+	// MetaRoles the authorization middleware requires the authenticated user to have
+	// at least one of the roles listed in this meta. The value can be a `string` or a `[]string`.
+	//
+	//	router.SetMeta(auth.MetaRoles, []string{"admin", "moderator"})
+	MetaRoles = "goyave.roles"
+
+	// MetaPermissions the authorization middleware requires the authenticated user to have
+	// all the permissions listed in this meta. The value can be a `string` or a `[]string`.
+	//
+	//	route.SetMeta(auth.MetaPermissions, []string{"articles.create", "articles.publish"})
+	MetaPermissions = "goyave.permissions"
+)
+
+// RoleHolder can be implemented by user DTOs to support the `MetaRoles` route meta.
+type RoleHolder interface {
+	HasRole(role string) bool
+}
+
+// PermissionHolder can be implemented by user DTOs to support the `MetaPermissions` route meta.
+type PermissionHolder interface {
+	HasPermission(permission string) bool
+}
+
+// Policy defines the abilities users of type `U` have on resources of type `R`.
+// The keys are the names of the abilities (e.g.: "view", "update", "delete") and the
+// values are functions returning true if the given user is allowed to perform the action.
+//
+// The user is `nil` if the request is not authenticated. The resource may be `nil` for
+// abilities that are not tied to a specific record (e.g.: "create").
+//
+// Both type parameters should be DTOs or models and not be pointers.
+//
+//	auth.Policy[dto.User, model.Article]{
+//		"update": func(_ *goyave.Request, user *dto.User, article *model.Article) bool {
+//			return user != nil && user.ID == article.AuthorID
+//		},
+//	}
+type Policy[U, R any] map[string]func(request *goyave.Request, user *U, resource *R) bool
+
+type policyFunc func(request *goyave.Request, ability string, resource any) bool
+
+// PolicyService holds the policies of the application, identified by their resource type.
+// Retrieve it in controllers with `server.Service(auth.PolicyServiceName)`.
+type PolicyService struct {
+	policies map[reflect.Type]policyFunc
+}
+
+// NewPolicyService create a new empty policy service.
+func NewPolicyService() *PolicyService {
+	return &PolicyService{
+		policies: map[reflect.Type]policyFunc{},
+	}
+}
+
+// Name returns the name of the service.
+func (s *PolicyService) Name() string {
+	return PolicyServiceName
+}
+
+// RegisterPolicy registers the given policy in the service. If a policy is already
+// registered for the resource type `R`, it is replaced.
+func RegisterPolicy[U, R any](service *PolicyService, policy Policy[U, R]) {
+	t := reflect.TypeOf((*R)(nil))
+	service.policies[t] = func(request *goyave.Request, ability string, resource any) bool {
+		check, ok := policy[ability]
+		if !ok {
+			return false
+		}
+		user, _ := request.User.(*U)
+		return check(request, user, resource.(*R))
+	}
+}
+
+// Can returns true if the authenticated user of the given request is allowed to perform the
+// given ability on the resource. The resource must be a pointer to a type for which a
+// policy has been registered. A typed `nil` can be used for abilities that are not tied
+// to a specific record:
+//
+//	service.Can(request, "create", (*model.Article)(nil))
+//
+// Unknown abilities are always denied.
+// Panics if there is no policy registered for the type of the resource.
+func (s *PolicyService) Can(request *goyave.Request, ability string, resource any) bool {
+	policy, ok := s.policies[reflect.TypeOf(resource)]
+	if !ok {
+		panic(errors.Errorf("no policy registered for resource type %T", resource))
+	}
+	return policy(request, ability, resource)
+}
+
+// Authorize checks if the authenticated user of the given request is allowed to perform the
+// given ability on the resource, like `Can`. If not, sets the response status to
+// "403 Forbidden", sets the request's `goyave.ExtraForbiddenReason` and returns false.
+// Handlers should return immediately in this case so the `StatusHandler` writes the response.
+//
+//	if !policies.Authorize(response, request, "update", article) {
+//		return
+//	}
+func (s *PolicyService) Authorize(response *goyave.Response, request *goyave.Request, ability string, resource any) bool {
+	if s.Can(request, ability, resource) {
+		return true
+	}
+	forbid(response, request)
+	return false
+}
+
+func forbid(response *goyave.Response, request *goyave.Request) {
+	request.Extra[goyave.ExtraForbiddenReason{}] = request.Lang.Get("auth.forbidden")
+	response.Status(http.StatusForbidden)
+}
+
+// AuthorizationHandler a middleware enforcing the roles and permissions declared with
+// the `MetaRoles` and `MetaPermissions` route meta. If none of these meta is present
+// in the matched route or any of its parents, the middleware is skipped.
+//
+// The user DTO must implement `RoleHolder` and/or `PermissionHolder`. Requests without
+// an authenticated user, or with a user that doesn't implement the required interface,
+// are denied.
+//
+// This middleware should be executed after the authentication middleware.
+// Denied requests are answered with "403 Forbidden". Register `goyave.ForbiddenStatusHandler`
+// to return a translated message.
+type AuthorizationHandler struct {
+	goyave.Component
+}
+
+// AuthorizationMiddleware returns a new middleware enforcing the roles and permissions
+// declared with the `MetaRoles` and `MetaPermissions` route meta.
+//
+//	router.GlobalMiddleware(auth.Middleware(authenticator), auth.AuthorizationMiddleware())
+//	router.StatusHandler(&goyave.ForbiddenStatusHandler{}, http.StatusForbidden)
+func AuthorizationMiddleware() *AuthorizationHandler {
+	return &AuthorizationHandler{}
+}
+
+// Handle implementation of `goyave.Middleware`.
+func (m *AuthorizationHandler) Handle(next goyave.Handler) goyave.Handler {
+	return func(response *goyave.Response, request *goyave.Request) {
+		if roles := lookupMetaList(request, MetaRoles); roles != nil {
+			holder, ok := request.User.(RoleHolder)
+			if !ok || !hasAnyRole(holder, roles) {
+				forbid(response, request)
+				return
+			}
+		}
+
+		if permissions := lookupMetaList(request, MetaPermissions); permissions != nil {
+			holder, _ := request.User.(PermissionHolder)
+			for _, p := range permissions {
+				if holder == nil || !holder.HasPermission(p) {
+					forbid(response, request)
+					return
+				}
+			}
+		}
+
+		next(response, request)
+	}
+}
+
+func hasAnyRole(holder RoleHolder, roles []string) bool {
+	for _, role := range roles {
+		if holder.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupMetaList(request *goyave.Request, key string) []string {
+	if request.Route == nil {
+		return nil
+	}
+	meta, ok := request.Route.LookupMeta(key)
+	if !ok {
+		return nil
+	}
+	switch v := meta.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	default:
+		panic(errors.Errorf("invalid %q meta: expected string or []string, got %T", key, meta))
+	}
+}
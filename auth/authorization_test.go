@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+type testAuthorizedUser struct {
+	Roles       []string
+	Permissions []string
+	ID          uint
+}
+
+func (u *testAuthorizedUser) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+func (u *testAuthorizedUser) HasPermission(permission string) bool {
+	return slices.Contains(u.Permissions, permission)
+}
+
+type testArticle struct {
+	AuthorID uint
+}
+
+func preparePolicyService() *PolicyService {
+	service := NewPolicyService()
+	RegisterPolicy(service, Policy[testAuthorizedUser, testArticle]{
+		"view": func(_ *goyave.Request, _ *testAuthorizedUser, _ *testArticle) bool {
+			return true
+		},
+		"create": func(_ *goyave.Request, user *testAuthorizedUser, _ *testArticle) bool {
+			return user != nil
+		},
+		"update": func(_ *goyave.Request, user *testAuthorizedUser, article *testArticle) bool {
+			return user != nil && user.ID == article.AuthorID
+		},
+	})
+	return service
+}
+
+func TestPolicyService(t *testing.T) {
+	service := preparePolicyService()
+	assert.Equal(t, PolicyServiceName, service.Name())
+
+	user := &testAuthorizedUser{ID: 1}
+	article := &testArticle{AuthorID: 1}
+	otherArticle := &testArticle{AuthorID: 2}
+
+	t.Run("can", func(t *testing.T) {
+		request := testutil.NewTestRequest(http.MethodGet, "/", nil)
+		assert.True(t, service.Can(request, "view", article))
+		assert.False(t, service.Can(request, "create", (*testArticle)(nil)))
+		assert.False(t, service.Can(request, "unknown", article))
+
+		request.User = user
+		assert.True(t, service.Can(request, "create", (*testArticle)(nil)))
+		assert.True(t, service.Can(request, "update", article))
+		assert.False(t, service.Can(request, "update", otherArticle))
+	})
+
+	t.Run("unknown_resource_type", func(t *testing.T) {
+		request := testutil.NewTestRequest(http.MethodGet, "/", nil)
+		assert.Panics(t, func() {
+			service.Can(request, "view", &TestUser{})
+		})
+		assert.Panics(t, func() {
+			service.Can(request, "view", testArticle{})
+		})
+	})
+
+	t.Run("authorize", func(t *testing.T) {
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+		server.RegisterService(service)
+
+		router := goyave.NewRouter(server.Server)
+		router.StatusHandler(&goyave.ForbiddenStatusHandler{}, http.StatusForbidden)
+		router.Put("/articles/{id}", func(response *goyave.Response, request *goyave.Request) {
+			request.User = user
+			a := article
+			if request.RouteParams["id"] != "1" {
+				a = otherArticle
+			}
+			policies := server.Service(PolicyServiceName).(*PolicyService)
+			if !policies.Authorize(response, request, "update", a) {
+				return
+			}
+			response.Status(http.StatusNoContent)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/articles/1", nil))
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/articles/2", nil))
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.JSONEq(t, `{"error":"`+server.Lang.GetDefault().Get("auth.forbidden")+`"}`, recorder.Body.String())
+	})
+}
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	prepare := func(t *testing.T, user any) (*goyave.Router, *goyave.Router) {
+		cfg := config.LoadDefault()
+		cfg.Set("app.debug", false)
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+		router := goyave.NewRouter(server.Server)
+		router.StatusHandler(&goyave.ForbiddenStatusHandler{}, http.StatusForbidden)
+		router.GlobalMiddleware(&testUserMiddleware{user: user}, AuthorizationMiddleware())
+		handler := func(response *goyave.Response, _ *goyave.Request) {
+			response.Status(http.StatusNoContent)
+		}
+		router.Get("/public", handler)
+		admin := router.Subrouter("/admin")
+		admin.SetMeta(MetaRoles, []string{"admin", "moderator"})
+		admin.Get("/", handler)
+		admin.Post("/articles", handler).SetMeta(MetaPermissions, []string{"articles.create", "articles.publish"})
+		admin.Delete("/articles", handler).SetMeta(MetaPermissions, "articles.delete")
+		admin.Get("/open", handler).SetMeta(MetaRoles, []string{})
+		router.Get("/forbidden", func(response *goyave.Response, _ *goyave.Request) {
+			response.Status(http.StatusForbidden)
+		})
+		router.Get("/invalid", handler).SetMeta(MetaRoles, 1)
+		return router, admin
+	}
+
+	request := func(router *goyave.Router, method, path string) (int, string) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		resp := recorder.Result()
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return resp.StatusCode, string(body)
+	}
+
+	forbidden := `{"error":"You are not allowed to perform this action."}`
+
+	cases := []struct {
+		user   any
+		desc   string
+		method string
+		path   string
+		want   string
+		status int
+	}{
+		{desc: "no_meta_guest", method: http.MethodGet, path: "/public", status: http.StatusNoContent},
+		{desc: "role_guest", method: http.MethodGet, path: "/admin", status: http.StatusForbidden, want: forbidden},
+		{desc: "role_not_holder", user: &TestUser{}, method: http.MethodGet, path: "/admin", status: http.StatusForbidden, want: forbidden},
+		{desc: "role_missing", user: &testAuthorizedUser{Roles: []string{"user"}}, method: http.MethodGet, path: "/admin", status: http.StatusForbidden, want: forbidden},
+		{desc: "role_any", user: &testAuthorizedUser{Roles: []string{"moderator"}}, method: http.MethodGet, path: "/admin", status: http.StatusNoContent},
+		{desc: "empty_roles", user: &testAuthorizedUser{}, method: http.MethodGet, path: "/admin/open", status: http.StatusNoContent},
+		{desc: "permissions_all", user: &testAuthorizedUser{Roles: []string{"admin"}, Permissions: []string{"articles.create", "articles.publish"}}, method: http.MethodPost, path: "/admin/articles", status: http.StatusNoContent},
+		{desc: "permissions_partial", user: &testAuthorizedUser{Roles: []string{"admin"}, Permissions: []string{"articles.create"}}, method: http.MethodPost, path: "/admin/articles", status: http.StatusForbidden, want: forbidden},
+		{desc: "permission_string", user: &testAuthorizedUser{Roles: []string{"admin"}, Permissions: []string{"articles.delete"}}, method: http.MethodDelete, path: "/admin/articles", status: http.StatusNoContent},
+		{desc: "forbidden_without_authorization_error", method: http.MethodGet, path: "/forbidden", status: http.StatusForbidden, want: `{"error":"Forbidden"}`},
+		{desc: "invalid_meta", user: &testAuthorizedUser{}, method: http.MethodGet, path: "/invalid", status: http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			router, _ := prepare(t, c.user)
+			status, body := request(router, c.method, c.path)
+			assert.Equal(t, c.status, status)
+			if c.want != "" {
+				assert.JSONEq(t, c.want, body)
+			}
+		})
+	}
+
+	t.Run("permission_without_role_meta", func(t *testing.T) {
+		router, admin := prepare(t, &testAuthorizedUser{Permissions: []string{"articles.publish"}})
+		admin.RemoveMeta(MetaRoles)
+		status, _ := request(router, http.MethodPost, "/admin/articles")
+		require.Equal(t, http.StatusForbidden, status)
+
+		router, admin = prepare(t, &TestUser{})
+		admin.RemoveMeta(MetaRoles)
+		status, _ = request(router, http.MethodDelete, "/admin/articles")
+		require.Equal(t, http.StatusForbidden, status)
+	})
+}
+
+type testUserMiddleware struct {
+	goyave.Component
+	user any
+}
+
+func (m *testUserMiddleware) Handle(next goyave.Handler) goyave.Handler {
+	return func(response *goyave.Response, request *goyave.Request) {
+		request.User = m.user
+		next(response, request)
+	}
+}
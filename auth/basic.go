@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"reflect"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"goyave.dev/goyave/v5"
 	"goyave.dev/goyave/v5/config"
@@ -27,6 +26,14 @@ type BasicAuthenticator[T any] struct {
 	// It will be used to compare the password hash with the user input.
 	PasswordField string
 
+	// PasswordHasher used to verify the user's password.
+	// Defaults to `DefaultPasswordHasher()`.
+	PasswordHasher PasswordHasher
+
+	// Throttler if not nil, limits failed attempts per username and per IP address
+	// to protect against brute-force attacks.
+	Throttler *LoginThrottler
+
 	// Optional defines if the authenticator allows requests that
 	// don't provide credentials. Handlers should therefore check
 	// if `request.User` is not `nil` before accessing it.
@@ -50,7 +57,10 @@ func NewBasicAuthenticator[T any](userService UserService[T], passwordField stri
 // Authenticate fetch the user corresponding to the credentials
 // found in the given request and returns it.
 // If no user can be authenticated, returns an error.
-// The password is checked using bcrypt.
+// The password is checked using the authenticator's `PasswordHasher`. If the `UserService`
+// implements `PasswordRehasher`, outdated hashes are upgraded on success.
+// If the authenticator has a `Throttler` and the username or the client's IP address is
+// locked out, returns a `*LockoutError`.
 func (a *BasicAuthenticator[T]) Authenticate(request *goyave.Request) (*T, error) {
 	username, password, ok := request.BasicAuth()
 
@@ -61,6 +71,12 @@ func (a *BasicAuthenticator[T]) Authenticate(request *goyave.Request) (*T, error
 		return nil, fmt.Errorf("%s", request.Lang.Get("auth.no-credentials-provided"))
 	}
 
+	if a.Throttler != nil {
+		if err := a.Throttler.Check(request, username); err != nil {
+			return nil, throttleError(err)
+		}
+	}
+
 	user, err := a.UserService.FindByUsername(request.Context(), username)
 
 	notFound := errors.Is(err, gorm.ErrRecordNotFound)
@@ -77,10 +93,20 @@ func (a *BasicAuthenticator[T]) Authenticate(request *goyave.Request) (*T, error
 		panic(errorutil.Errorf("could not find valid field/column %q in type %T", a.PasswordField, user))
 	}
 
-	if notFound || bcrypt.CompareHashAndPassword([]byte(pass.String()), []byte(password)) != nil {
+	if notFound || !checkPassword(request, a.Logger(), a.PasswordHasher, a.UserService, user, pass.String(), password) {
+		if a.Throttler != nil {
+			if err := a.Throttler.Failure(request, username); err != nil {
+				return nil, throttleError(err)
+			}
+		}
 		return nil, fmt.Errorf("%s", request.Lang.Get("auth.invalid-credentials"))
 	}
 
+	if a.Throttler != nil {
+		if err := a.Throttler.Success(request, username); err != nil {
+			panic(err)
+		}
+	}
 	return user, nil
 }
 
@@ -88,6 +114,12 @@ func (a *BasicAuthenticator[T]) Scheme() string {
 	return "Basic"
 }
 
+// HasCredentials returns true if the request contains Basic credentials.
+func (a *BasicAuthenticator[T]) HasCredentials(request *goyave.Request) bool {
+	_, _, ok := request.BasicAuth()
+	return ok
+}
+
 //--------------------------------------------
 
 func init() {
@@ -139,6 +171,12 @@ func (a *ConfigBasicAuthenticator) Scheme() string {
 	return "Basic"
 }
 
+// HasCredentials returns true if the request contains Basic credentials.
+func (a *ConfigBasicAuthenticator) HasCredentials(request *goyave.Request) bool {
+	_, _, ok := request.BasicAuth()
+	return ok
+}
+
 // ConfigBasicAuth create a new authenticator middleware for
 // config-based Basic authentication. On auth success, the request
 // user is set to a `*BasicUser`.
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -93,6 +94,49 @@ func TestBasicAuthenticator(t *testing.T) {
 		assert.Empty(t, resp.Header.Get("WWW-Authenticate"))
 	})
 
+	t.Run("rehash", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		buf := &bytes.Buffer{}
+		server.Logger = slog.New(slog.NewHandler(false, buf))
+		legacyHash := user.Password
+		userService := &mockRehashUserService{MockUserService: MockUserService[TestUser]{user: user}}
+		a := NewBasicAuthenticator[TestUser](userService, "Password")
+		a.PasswordHasher = &MultiPasswordHasher{
+			Default: &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+			Others:  []PasswordHasher{NewBcryptHasher()},
+		}
+		a.Init(server.Server)
+
+		request := server.NewTestRequest(http.MethodGet, "/protected", nil)
+		request.Request().SetBasicAuth(user.Email, "secret")
+		u, err := a.Authenticate(request)
+		require.NoError(t, err)
+		assert.Same(t, user, u)
+		require.Len(t, userService.hashes, 1)
+		assert.NotEqual(t, legacyHash, user.Password)
+		assert.True(t, strings.HasPrefix(user.Password, "$argon2id$"))
+
+		// Already up to date
+		_, err = a.Authenticate(request)
+		require.NoError(t, err)
+		assert.Len(t, userService.hashes, 1)
+
+		// Failures don't prevent authentication
+		user.Password = legacyHash
+		userService.updateErr = fmt.Errorf("update error")
+		u, err = a.Authenticate(request)
+		require.NoError(t, err)
+		assert.Same(t, user, u)
+		assert.Len(t, userService.hashes, 2)
+		assert.Contains(t, buf.String(), "update error")
+
+		// No rehash on failure
+		request.Request().SetBasicAuth(user.Email, "wrong password")
+		_, err = a.Authenticate(request)
+		require.Error(t, err)
+		assert.Len(t, userService.hashes, 2)
+	})
+
 	t.Run("optional_success", func(t *testing.T) {
 		server, user := prepareAuthenticatorTest(t)
 		mockUserService := &MockUserService[TestUser]{user: user}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"goyave.dev/goyave/v5"
+)
+
+// MultiSchemeAuthenticator let Authenticators supporting several authentication schemes
+// indicate them for use in the `WWW-Authenticate` header returned when authentication fails.
+// One challenge is added per scheme.
+type MultiSchemeAuthenticator interface {
+	// Schemes returns the authenticator's schemes, in order of preference.
+	Schemes() []string
+}
+
+// CredentialsDetector can be implemented by Authenticators to indicate if a request
+// contains credentials they handle. This is used by `CompositeAuthenticator` to find
+// the most meaningful error when all authenticators failed.
+type CredentialsDetector interface {
+	HasCredentials(request *goyave.Request) bool
+}
+
+// CompositeError the error returned by `CompositeAuthenticator` when the authentication
+// fails. It keeps track of the authenticator that returned the original error.
+type CompositeError[T any] struct {
+	Err           error
+	Authenticator Authenticator[T]
+}
+
+// Error returns the message of the original error.
+func (e *CompositeError[T]) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the original error.
+func (e *CompositeError[T]) Unwrap() error {
+	return e.Err
+}
+
+// CompositeAuthenticator implementation of Authenticator accepting several authentication
+// methods on the same routes. For example, an endpoint can accept a JWT, Basic credentials
+// or an API key.
+//
+// The authenticators are tried in order and the first one succeeding authenticates the user.
+// If `SelectByScheme` is enabled and the request's `Authorization` header uses the scheme of
+// one of the authenticators implementing `SchemeAuthenticator`, only this authenticator is used.
+//
+// If all the authenticators fail, the returned error is the first error returned by an
+// authenticator that detected credentials in the request (see `CredentialsDetector`),
+// or the first error otherwise. The error is a `*CompositeError[T]`: if the authenticator that
+// returned it implements `Unauthorizer`, its `OnUnauthorized` is called with the original error.
+//
+// The `WWW-Authenticate` header contains one challenge per scheme.
+//
+// The T parameter represents the user DTO and should not be a pointer.
+type CompositeAuthenticator[T any] struct {
+	goyave.Component
+
+	Authenticators []Authenticator[T]
+
+	// SelectByScheme if true and the request's `Authorization` header scheme matches
+	// the scheme of one of the authenticators, only this authenticator is used.
+	SelectByScheme bool
+
+	// Optional defines if the authenticator allows requests that
+	// don't provide credentials. Handlers should therefore check
+	// if `request.User` is not `nil` before accessing it.
+	// If an authenticator returns an error for credentials it detected,
+	// the request is still rejected.
+	Optional bool
+}
+
+// NewCompositeAuthenticator create a new authenticator trying each of the given authenticators in order.
+func NewCompositeAuthenticator[T any](authenticators ...Authenticator[T]) *CompositeAuthenticator[T] {
+	return &CompositeAuthenticator[T]{
+		Authenticators: authenticators,
+	}
+}
+
+// Init the authenticator and all its sub-authenticators.
+func (a *CompositeAuthenticator[T]) Init(server *goyave.Server) {
+	a.Component.Init(server)
+	for _, authenticator := range a.Authenticators {
+		authenticator.Init(server)
+	}
+}
+
+// Authenticate tries each authenticator and returns the user authenticated by the first
+// one succeeding. If no user can be authenticated, returns a `*CompositeError[T]`.
+func (a *CompositeAuthenticator[T]) Authenticate(request *goyave.Request) (*T, error) {
+	authenticators := a.Authenticators
+	if authenticator := a.selectByScheme(request); authenticator != nil {
+		authenticators = []Authenticator[T]{authenticator}
+	}
+
+	var firstErr *CompositeError[T]
+	var meaningfulErr *CompositeError[T]
+	for _, authenticator := range authenticators {
+		user, err := authenticator.Authenticate(request)
+		if err == nil {
+			if user == nil {
+				continue // Optional authenticator without credentials
+			}
+			return user, nil
+		}
+
+		compositeErr := &CompositeError[T]{Err: err, Authenticator: authenticator}
+		if firstErr == nil {
+			firstErr = compositeErr
+		}
+		if detector, ok := authenticator.(CredentialsDetector); ok && meaningfulErr == nil && detector.HasCredentials(request) {
+			meaningfulErr = compositeErr
+		}
+	}
+
+	switch {
+	case meaningfulErr != nil:
+		return nil, meaningfulErr
+	case a.Optional:
+		return nil, nil
+	case firstErr != nil:
+		return nil, firstErr
+	default:
+		return nil, &CompositeError[T]{Err: fmt.Errorf("%s", request.Lang.Get("auth.no-credentials-provided"))}
+	}
+}
+
+func (a *CompositeAuthenticator[T]) selectByScheme(request *goyave.Request) Authenticator[T] {
+	if !a.SelectByScheme {
+		return nil
+	}
+	scheme, _, ok := strings.Cut(request.Header().Get("Authorization"), " ")
+	if !ok {
+		return nil
+	}
+	for _, authenticator := range a.Authenticators {
+		if sa, ok := authenticator.(SchemeAuthenticator); ok && strings.EqualFold(sa.Scheme(), scheme) {
+			return authenticator
+		}
+	}
+	return nil
+}
+
+// Schemes returns the schemes of the authenticators implementing `SchemeAuthenticator`
+// or `MultiSchemeAuthenticator`, without duplicates.
+func (a *CompositeAuthenticator[T]) Schemes() []string {
+	schemes := []string{}
+	add := func(scheme string) {
+		for _, s := range schemes {
+			if strings.EqualFold(s, scheme) {
+				return
+			}
+		}
+		schemes = append(schemes, scheme)
+	}
+	for _, authenticator := range a.Authenticators {
+		switch sa := authenticator.(type) {
+		case MultiSchemeAuthenticator:
+			for _, scheme := range sa.Schemes() {
+				add(scheme)
+			}
+		case SchemeAuthenticator:
+			add(sa.Scheme())
+		}
+	}
+	return schemes
+}
+
+// HasCredentials returns true if at least one of the authenticators detects credentials in the request.
+func (a *CompositeAuthenticator[T]) HasCredentials(request *goyave.Request) bool {
+	for _, authenticator := range a.Authenticators {
+		if detector, ok := authenticator.(CredentialsDetector); ok && detector.HasCredentials(request) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnUnauthorized delegates to the `Unauthorizer` implementation of the authenticator
+// that returned the error, if any. Otherwise, returns a default `401 Unauthorized` error,
+// or `429 Too Many Requests` if the error is a `*LockoutError`.
+func (a *CompositeAuthenticator[T]) OnUnauthorized(response *goyave.Response, request *goyave.Request, err error) {
+	var compositeErr *CompositeError[T]
+	if errors.As(err, &compositeErr) {
+		if unauthorizer, ok := compositeErr.Authenticator.(Unauthorizer); ok {
+			unauthorizer.OnUnauthorized(response, request, compositeErr.Err)
+			return
+		}
+	}
+	writeUnauthorized(response, err)
+}
@@ -302,3 +302,9 @@ func (a *JWTAuthenticator[T]) makeError(language *lang.Language, err error) erro
 func (a *JWTAuthenticator[T]) Scheme() string {
 	return "Bearer"
 }
+
+// HasCredentials returns true if the request contains a bearer token.
+func (a *JWTAuthenticator[T]) HasCredentials(request *goyave.Request) bool {
+	token, ok := request.BearerToken()
+	return ok && token != ""
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/samber/lo"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"goyave.dev/goyave/v5"
 	"goyave.dev/goyave/v5/middleware/parse"
@@ -51,6 +50,13 @@ type JWTController[T any] struct { // TODO refresh token
 	// PasswordField the name of T's struct field that holds the user's hashed password.
 	// It will be used to compare the password hash with the user input.
 	PasswordField string
+	// PasswordHasher used to verify the user's password.
+	// Defaults to `DefaultPasswordHasher()`.
+	PasswordHasher PasswordHasher
+	// Throttler if not nil, limits failed attempts per username and per IP address
+	// to protect against brute-force attacks. Locked out clients receive a
+	// "429 Too Many Requests" response with the `Retry-After` header.
+	Throttler *LoginThrottler
 }
 
 // NewJWTController create a new JWTController that registers a login route returning a JWT for quick prototyping.
@@ -102,12 +108,22 @@ func (c *JWTController[T]) validationRules(_ *goyave.Request) validation.RuleSet
 // Login POST handler for token-based authentication.
 // Creates a new token for the user authenticated with the body fields
 // defined in the controller and returns it as a response.
-// The password is checked using bcrypt.
+// The password is checked using the controller's `PasswordHasher`. If the `UserService`
+// implements `PasswordRehasher`, outdated hashes are upgraded on success.
+// If the controller has a `Throttler`, failed attempts are recorded and locked
+// out clients receive a "429 Too Many Requests" response.
 func (c *JWTController[T]) Login(response *goyave.Response, request *goyave.Request) {
 	body := request.Data.(map[string]any)
 	username := body[lo.Ternary(c.UsernameRequestField == "", "username", c.UsernameRequestField)].(string)
 	password := body[lo.Ternary(c.PasswordRequestField == "", "password", c.PasswordRequestField)].(string)
 
+	if c.Throttler != nil {
+		if err := c.Throttler.Check(request, username); err != nil {
+			c.writeThrottleError(response, err)
+			return
+		}
+	}
+
 	user, err := c.UserService.FindByUsername(request.Context(), username)
 
 	notFound := errors.Is(err, gorm.ErrRecordNotFound)
@@ -117,7 +133,7 @@ func (c *JWTController[T]) Login(response *goyave.Response, request *goyave.Requ
 	}
 
 	if notFound {
-		response.JSON(http.StatusUnauthorized, map[string]string{"error": request.Lang.Get("auth.invalid-credentials")})
+		c.loginFailed(response, request, username)
 		return
 	}
 
@@ -131,7 +147,13 @@ func (c *JWTController[T]) Login(response *goyave.Response, request *goyave.Requ
 		return
 	}
 
-	if bcrypt.CompareHashAndPassword([]byte(pass.String()), []byte(password)) == nil {
+	if checkPassword(request, c.Logger(), c.PasswordHasher, c.UserService, user, pass.String(), password) {
+		if c.Throttler != nil {
+			if err := c.Throttler.Success(request, username); err != nil {
+				response.Error(err)
+				return
+			}
+		}
 		tokenFunc := lo.Ternary(c.TokenFunc == nil, c.defaultTokenFunc, c.TokenFunc)
 		token, err := tokenFunc(request, user)
 		if err != nil {
@@ -142,9 +164,27 @@ func (c *JWTController[T]) Login(response *goyave.Response, request *goyave.Requ
 		return
 	}
 
+	c.loginFailed(response, request, username)
+}
+
+func (c *JWTController[T]) loginFailed(response *goyave.Response, request *goyave.Request, username string) {
+	if c.Throttler != nil {
+		if err := c.Throttler.Failure(request, username); err != nil {
+			c.writeThrottleError(response, err)
+			return
+		}
+	}
 	response.JSON(http.StatusUnauthorized, map[string]string{"error": request.Lang.Get("auth.invalid-credentials")})
 }
 
+func (c *JWTController[T]) writeThrottleError(response *goyave.Response, err error) {
+	if _, ok := err.(*LockoutError); ok {
+		writeUnauthorized(response, err)
+		return
+	}
+	response.Error(err)
+}
+
 func (c *JWTController[T]) defaultTokenFunc(r *goyave.Request, _ *T) (string, error) {
 	signingMethod := c.SigningMethod
 	if signingMethod == nil {
@@ -44,6 +44,33 @@ func TestJWTController(t *testing.T) {
 		assert.NotEmpty(t, respBody["token"])
 	})
 
+	t.Run("Login_rehash", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		server.Config().Set("auth.jwt.secret", "secret")
+
+		userService := &mockRehashUserService{MockUserService: MockUserService[TestUser]{user: user}}
+		controller := NewJWTController[TestUser](userService, "Password")
+		controller.PasswordHasher = &MultiPasswordHasher{
+			Default: &ScryptHasher{N: 1 << 10, R: 8, P: 1, SaltLength: 16, KeyLength: 32},
+			Others:  []PasswordHasher{NewBcryptHasher()},
+		}
+		server.RegisterRoutes(func(_ *goyave.Server, router *goyave.Router) {
+			router.Controller(controller)
+		})
+
+		body, err := json.Marshal(map[string]any{"username": user.Email, "password": "secret"})
+		require.NoError(t, err)
+		request := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		resp := server.TestRequest(request)
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, userService.hashes, 1)
+		assert.Equal(t, userService.hashes[0], user.Password)
+		assert.True(t, controller.PasswordHasher.Supports(user.Password))
+		assert.False(t, controller.PasswordHasher.NeedsRehash(user.Password))
+	})
+
 	t.Run("Login_ptr", func(t *testing.T) {
 		server, user := prepareAuthenticatorTest(t)
 		server.Config().Set("auth.jwt.secret", "secret")
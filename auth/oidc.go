@@ -0,0 +1,456 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+func init() {
+	registerOIDCConfigEntry("auth.oidc.issuer", nil)
+	registerOIDCConfigEntry("auth.oidc.clientID", nil)
+	registerOIDCConfigEntry("auth.oidc.clientSecret", "")
+	registerOIDCConfigEntry("auth.oidc.redirectURL", nil)
+	config.Register("auth.oidc.scopes", config.Entry{
+		Value:            []string{"openid", "profile", "email"},
+		Type:             reflect.String,
+		IsSlice:          true,
+		AuthorizedValues: []any{},
+	})
+}
+
+func registerOIDCConfigEntry(name string, value any) {
+	config.Register(name, config.Entry{
+		Value:            value,
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+}
+
+var (
+	// ErrOIDCInvalidIDToken the ID token returned by the identity provider is
+	// malformed, expired, not signed by the provider or not issued for this client.
+	ErrOIDCInvalidIDToken = fmt.Errorf("oidc: invalid ID token")
+
+	// ErrOIDCNonceMismatch the nonce claim of the ID token doesn't match the nonce
+	// generated when the authorization request was initiated.
+	ErrOIDCNonceMismatch = fmt.Errorf("oidc: nonce mismatch")
+)
+
+// OIDCDiscovery the subset of the OpenID Provider metadata used by the relying party.
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type OIDCDiscovery struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserinfoEndpoint                 string   `json:"userinfo_endpoint"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	EndSessionEndpoint               string   `json:"end_session_endpoint"`
+	ScopesSupported                  []string `json:"scopes_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// OIDCTokens the tokens returned by the identity provider's token endpoint.
+type OIDCTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// OIDCClaims the claims of a verified ID token.
+//
+// The standard claims are exposed as fields. All the claims, including the
+// non-standard ones, are available in `Raw`.
+type OIDCClaims struct {
+	Raw               jwt.MapClaims `json:"-"`
+	Subject           string        `json:"sub"`
+	Issuer            string        `json:"iss"`
+	Nonce             string        `json:"nonce"`
+	Email             string        `json:"email"`
+	Name              string        `json:"name"`
+	GivenName         string        `json:"given_name"`
+	FamilyName        string        `json:"family_name"`
+	PreferredUsername string        `json:"preferred_username"`
+	Picture           string        `json:"picture"`
+	Locale            string        `json:"locale"`
+	EmailVerified     bool          `json:"email_verified"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// OIDCProvider an OpenID Connect relying party for a single identity provider.
+//
+// The provider metadata is loaded from the discovery document ("/.well-known/openid-configuration")
+// on first use and cached. The signing keys of the identity provider are cached as well and
+// reloaded when an ID token signed with an unknown key is received.
+type OIDCProvider struct {
+	discovery *OIDCDiscovery
+	keys      map[string]any
+
+	// HTTPClient the client used to communicate with the identity provider.
+	// Defaults to a client with a 10 seconds timeout.
+	HTTPClient *http.Client
+
+	// Issuer the identifier of the identity provider. The discovery document
+	// is retrieved from "{Issuer}/.well-known/openid-configuration".
+	Issuer string
+
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL the absolute URL of the callback route, as registered
+	// in the identity provider.
+	RedirectURL string
+
+	// Scopes requested in the authorization request. "openid" is always requested.
+	Scopes []string
+
+	mu sync.RWMutex
+}
+
+// NewOIDCProvider create a new OIDC provider using the `auth.oidc.*` config entries.
+func NewOIDCProvider(cfg *config.Config) *OIDCProvider {
+	return &OIDCProvider{
+		Issuer:       cfg.GetString("auth.oidc.issuer"),
+		ClientID:     cfg.GetString("auth.oidc.clientID"),
+		ClientSecret: cfg.GetString("auth.oidc.clientSecret"),
+		RedirectURL:  cfg.GetString("auth.oidc.redirectURL"),
+		Scopes:       cfg.GetStringSlice("auth.oidc.scopes"),
+	}
+}
+
+func (p *OIDCProvider) client() *http.Client {
+	if p.HTTPClient == nil {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	return p.HTTPClient
+}
+
+// Discovery returns the provider metadata. The discovery document is only retrieved
+// once: the result is cached for the lifetime of the provider.
+func (p *OIDCProvider) Discovery(ctx context.Context) (*OIDCDiscovery, error) {
+	p.mu.RLock()
+	discovery := p.discovery
+	p.mu.RUnlock()
+	if discovery != nil {
+		return discovery, nil
+	}
+
+	discovery = &OIDCDiscovery{}
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.Issuer, "/")+"/.well-known/openid-configuration", discovery); err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != p.Issuer {
+		return nil, errors.Errorf("oidc: discovery document issuer %q doesn't match the configured issuer %q", discovery.Issuer, p.Issuer)
+	}
+
+	p.mu.Lock()
+	p.discovery = discovery
+	p.mu.Unlock()
+	return discovery, nil
+}
+
+// AuthCodeURL returns the URL of the identity provider's authorization endpoint
+// for the authorization code flow with PKCE (S256).
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	discovery, err := p.Discovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.Scopes
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	u, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", errors.New(err)
+	}
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.ClientID)
+	query.Set("redirect_uri", p.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Exchange the given authorization code for tokens at the identity provider's token
+// endpoint. The client authenticates using HTTP Basic authentication ("client_secret_basic")
+// if a client secret is defined.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier string) (*OIDCTokens, error) {
+	discovery, err := p.Discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.ClientSecret == "" {
+		form.Set("client_id", p.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	tokens := &OIDCTokens{}
+	if err := p.doJSON(req, tokens); err != nil {
+		return nil, err
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("oidc: token response doesn't contain an ID token")
+	}
+	return tokens, nil
+}
+
+// VerifyIDToken parses the given ID token, checks its signature using the identity provider's
+// keys and validates its claims: issuer, audience, authorized party, expiry and nonce.
+//
+// Returns an error wrapping `ErrOIDCInvalidIDToken` or `ErrOIDCNonceMismatch` if the token
+// is not valid. Other errors are unexpected errors (e.g.: the identity provider is unreachable).
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*OIDCClaims, error) {
+	discovery, err := p.Discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := discovery.IDTokenSigningAlgValuesSupported
+	if len(methods) == 0 {
+		methods = []string{"RS256"}
+	}
+	var keyErr error
+	token, err := jwt.Parse(rawIDToken, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.getKey(ctx, kid)
+		if err != nil {
+			keyErr = err
+		}
+		return key, err
+	},
+		jwt.WithValidMethods(slices.DeleteFunc(slices.Clone(methods), func(m string) bool {
+			// Symmetric algorithms would require the client secret as key. Not supported.
+			return m == "none" || strings.HasPrefix(m, "HS")
+		})),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if err != nil {
+		return nil, errors.Errorf("%w: %w", ErrOIDCInvalidIDToken, err)
+	}
+
+	mapClaims := token.Claims.(jwt.MapClaims)
+	if aud, _ := mapClaims.GetAudience(); len(aud) > 1 {
+		if azp, _ := mapClaims["azp"].(string); azp != p.ClientID {
+			return nil, errors.Errorf("%w: invalid authorized party", ErrOIDCInvalidIDToken)
+		}
+	}
+
+	claims := &OIDCClaims{}
+	b, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	if err := json.Unmarshal(b, claims); err != nil {
+		return nil, errors.Errorf("%w: %w", ErrOIDCInvalidIDToken, err)
+	}
+	claims.Raw = mapClaims
+
+	if claims.Subject == "" {
+		return nil, errors.Errorf("%w: missing subject", ErrOIDCInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New(ErrOIDCNonceMismatch)
+	}
+	return claims, nil
+}
+
+// getKey returns the signing key identified by the given key ID. The key set is
+// reloaded if the key is unknown, in case the identity provider rotated its keys.
+func (p *OIDCProvider) getKey(ctx context.Context, kid string) (any, error) {
+	p.mu.RLock()
+	key, ok := p.findKey(kid)
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := p.loadKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	key, ok = p.findKey(kid)
+	if !ok {
+		return nil, errors.Errorf("%w: unknown signing key %q", ErrOIDCInvalidIDToken, kid)
+	}
+	return key, nil
+}
+
+func (p *OIDCProvider) findKey(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		// Tokens without key ID are accepted if the provider has a single key.
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *OIDCProvider) loadKeys(ctx context.Context) error {
+	discovery, err := p.Discovery(ctx)
+	if err != nil {
+		return err
+	}
+
+	set := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := p.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue // Ignore unsupported key types
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, url string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.New(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	return p.doJSON(req, dest)
+}
+
+func (p *OIDCProvider) doJSON(req *http.Request, dest any) error {
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return errors.New(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return errors.New(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("oidc: %s %s: unexpected status %d: %s", req.Method, req.URL, resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		return errors.Errorf("oidc: %s %s: %w", req.Method, req.URL, err)
+	}
+	return nil
+}
+
+func codeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	errorutil "goyave.dev/goyave/v5/util/errors"
+)
+
+// OIDCSessionKey the session key holding the state, nonce and PKCE code verifier
+// of the pending authorization request.
+const OIDCSessionKey = "goyave.auth.oidc"
+
+// OIDCUserService is the dependency of `OIDCController` used to map the claims of a
+// verified ID token to the application's user. Implementations usually find the user
+// by `claims.Subject` and create it (just-in-time provisioning) if it doesn't exist yet.
+//
+// If the user cannot be authenticated (e.g.: no provisioning and unknown subject),
+// the error returned should be of type `gorm.ErrRecordNotFound`.
+//
+// `SessionKey` returns the "username" of the given user, stored in the session by the
+// default `LoginFunc`. It must be the value the `UserService` of the `SessionAuthenticator`
+// expects in `FindByUsername` (e.g. the user's email or ID), which is usually not the
+// ID token's subject.
+type OIDCUserService[T any] interface {
+	FindByClaims(ctx context.Context, claims *OIDCClaims) (*T, error)
+	SessionKey(user *T) any
+}
+
+// OIDCLoginFunc is the function used by `OIDCController` to log the user in
+// once the identity provider authenticated them.
+type OIDCLoginFunc[T any] func(response *goyave.Response, request *goyave.Request, user *T, claims *OIDCClaims, tokens *OIDCTokens)
+
+// OIDCController controller adding the routes for the OpenID Connect authorization code flow
+// with PKCE. The user is redirected to the identity provider and back to the callback route,
+// where the ID token is verified and its claims are mapped to the application's user.
+//
+// The state, nonce and code verifier are stored in the session: the session `Middleware` must be
+// executed for the controller's routes.
+//
+// The T parameter represents the user DTO and should not be a pointer.
+type OIDCController[T any] struct {
+	goyave.Component
+
+	// Provider the identity provider. If `nil`, a provider is created from
+	// the `auth.oidc.*` config entries when the controller is initialized.
+	Provider *OIDCProvider
+
+	UserService OIDCUserService[T]
+
+	// LoginFunc is called after a successful authentication. Defaults to
+	// logging the user in with `SessionLogin`, using the value returned by
+	// `UserService.SessionKey()` as username, then redirecting to `RedirectAfterLogin`.
+	LoginFunc OIDCLoginFunc[T]
+
+	// RedirectAfterLogin the URL the user is redirected to by the default `LoginFunc`.
+	// Defaults to "/".
+	RedirectAfterLogin string
+}
+
+// NewOIDCController create a new OIDCController using the given provider and user service.
+func NewOIDCController[T any](provider *OIDCProvider, userService OIDCUserService[T]) *OIDCController[T] {
+	return &OIDCController[T]{
+		Provider:    provider,
+		UserService: userService,
+	}
+}
+
+// Init the controller. Creates the provider from the config if not set.
+func (c *OIDCController[T]) Init(server *goyave.Server) {
+	c.Component.Init(server)
+	if c.Provider == nil {
+		c.Provider = NewOIDCProvider(server.Config())
+	}
+}
+
+// RegisterRoutes register the "/login" and "/callback" routes on the given router.
+// The callback route should match the provider's `RedirectURL`.
+func (c *OIDCController[T]) RegisterRoutes(router *goyave.Router) {
+	router.Get("/login", c.Login).Name("oidc.login").SetMeta(MetaAuth, false)
+	router.Get("/callback", c.Callback).Name("oidc.callback").SetMeta(MetaAuth, false)
+}
+
+// Login GET handler initiating the authorization code flow. Generates a new state,
+// nonce and PKCE code verifier, stores them in the session and redirects the user
+// to the identity provider's authorization endpoint.
+func (c *OIDCController[T]) Login(response *goyave.Response, request *goyave.Request) {
+	s := mustGetSession(request)
+	state := generateRandomString()
+	nonce := generateRandomString()
+	verifier := generateRandomString()
+
+	authURL, err := c.Provider.AuthCodeURL(request.Context(), state, nonce, verifier)
+	if err != nil {
+		response.Error(err)
+		return
+	}
+
+	s.Set(OIDCSessionKey, map[string]any{
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+	})
+	response.Header().Set("Location", authURL)
+	response.Status(http.StatusFound)
+}
+
+// Callback GET handler completing the authorization code flow. Checks the state,
+// exchanges the authorization code for tokens, verifies the ID token and
+// maps its claims to the application's user using the `UserService`.
+//
+// Responds with "401 Unauthorized" if the identity provider returned an error,
+// if the state is invalid or if the ID token is invalid.
+func (c *OIDCController[T]) Callback(response *goyave.Response, request *goyave.Request) {
+	s := mustGetSession(request)
+	pending, _ := s.Get(OIDCSessionKey)
+	s.Delete(OIDCSessionKey) // The state can only be used once
+	pendingMap, _ := pending.(map[string]any)
+	state, _ := pendingMap["state"].(string)
+	nonce, _ := pendingMap["nonce"].(string)
+	verifier, _ := pendingMap["verifier"].(string)
+
+	query := request.URL().Query()
+	if query.Get("error") != "" {
+		c.unauthorized(response, request, "auth.oidc-failed")
+		return
+	}
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		c.unauthorized(response, request, "auth.oidc-invalid-state")
+		return
+	}
+	code := query.Get("code")
+	if code == "" {
+		c.unauthorized(response, request, "auth.oidc-failed")
+		return
+	}
+
+	tokens, err := c.Provider.Exchange(request.Context(), code, verifier)
+	if err != nil {
+		response.Error(err)
+		return
+	}
+
+	claims, err := c.Provider.VerifyIDToken(request.Context(), tokens.IDToken, nonce)
+	if err != nil {
+		if errors.Is(err, ErrOIDCInvalidIDToken) || errors.Is(err, ErrOIDCNonceMismatch) {
+			c.unauthorized(response, request, "auth.oidc-invalid-token")
+			return
+		}
+		response.Error(err)
+		return
+	}
+
+	user, err := c.UserService.FindByClaims(request.Context(), claims)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.unauthorized(response, request, "auth.invalid-credentials")
+			return
+		}
+		response.Error(errorutil.New(err))
+		return
+	}
+
+	loginFunc := c.LoginFunc
+	if loginFunc == nil {
+		loginFunc = c.defaultLoginFunc
+	}
+	loginFunc(response, request, user, claims, tokens)
+}
+
+func (c *OIDCController[T]) unauthorized(response *goyave.Response, request *goyave.Request, langEntry string) {
+	response.JSON(http.StatusUnauthorized, map[string]string{"error": request.Lang.Get(langEntry)})
+}
+
+func (c *OIDCController[T]) defaultLoginFunc(response *goyave.Response, request *goyave.Request, user *T, _ *OIDCClaims, _ *OIDCTokens) {
+	if err := SessionLogin(request, c.UserService.SessionKey(user)); err != nil {
+		response.Error(err)
+		return
+	}
+	redirect := c.RedirectAfterLogin
+	if redirect == "" {
+		redirect = "/"
+	}
+	response.Header().Set("Location", redirect)
+	response.Status(http.StatusFound)
+}
+
+func generateRandomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(errorutil.New(err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,613 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/middleware/session"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+// stubIdentityProvider a minimal OpenID provider for testing purposes.
+type stubIdentityProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// claims overrides the claims of the issued ID tokens
+	claims jwt.MapClaims
+
+	// codes the pending authorization requests, identified by their code
+	codes map[string]url.Values
+
+	kid            string
+	discoveryCalls int
+	jwksCalls      int
+	mu             sync.Mutex
+}
+
+func newStubIdentityProvider(t *testing.T) *stubIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idp := &stubIdentityProvider{
+		key:   key,
+		kid:   "key-1",
+		codes: map[string]url.Values{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		idp.mu.Lock()
+		idp.discoveryCalls++
+		idp.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256", "ES256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		idp.jwksCalls++
+		writeJSON(w, http.StatusOK, map[string]any{
+			"keys": []map[string]any{
+				{
+					"kty": "RSA",
+					"kid": idp.kid,
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+				},
+				{"kty": "oct", "kid": "symmetric"},
+				{"kty": "RSA", "kid": "encryption", "use": "enc"},
+			},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != "client-id" || secret != "client-secret" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+			return
+		}
+		idp.mu.Lock()
+		authRequest, ok := idp.codes[r.PostForm.Get("code")]
+		delete(idp.codes, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+		if !ok ||
+			r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("redirect_uri") != authRequest.Get("redirect_uri") ||
+			codeChallenge(r.PostForm.Get("code_verifier")) != authRequest.Get("code_challenge") {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idp.signIDToken(t, authRequest.Get("nonce")),
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize simulates the user authenticating on the identity provider. Returns the
+// code that would be sent to the relying party's callback.
+func (idp *stubIdentityProvider) authorize(t *testing.T, authURL string) (code string, query url.Values) {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	query = u.Query()
+	code = generateRandomString()
+	idp.mu.Lock()
+	idp.codes[code] = query
+	idp.mu.Unlock()
+	return code, query
+}
+
+func (idp *stubIdentityProvider) signIDToken(t *testing.T, nonce string) string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"sub":   "user-123",
+		"aud":   "client-id",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": nonce,
+		"email": "johndoe@example.org",
+		"name":  "John Doe",
+		"roles": []any{"admin"},
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.kid
+	s, err := token.SignedString(idp.key)
+	require.NoError(t, err)
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+type mockOIDCUserService struct {
+	err  error
+	user *TestUser
+}
+
+func (s *mockOIDCUserService) FindByClaims(_ context.Context, claims *OIDCClaims) (*TestUser, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.user = &TestUser{Name: claims.Name, Email: claims.Email}
+	return s.user, nil
+}
+
+func (s *mockOIDCUserService) SessionKey(user *TestUser) any {
+	return user.Email
+}
+
+// oidcSessionUserService finds the users provisioned by a `mockOIDCUserService` by email.
+type oidcSessionUserService struct {
+	oidcUsers *mockOIDCUserService
+}
+
+func (s *oidcSessionUserService) FindByUsername(_ context.Context, username any) (*TestUser, error) {
+	if s.oidcUsers.user == nil || s.oidcUsers.user.Email != username {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.oidcUsers.user, nil
+}
+
+func newTestOIDCProvider(idp *stubIdentityProvider) *OIDCProvider {
+	return &OIDCProvider{
+		Issuer:       idp.server.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://example.com/oidc/callback",
+		Scopes:       []string{"email"},
+	}
+}
+
+func TestOIDCProvider(t *testing.T) {
+	t.Run("config", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("auth.oidc.issuer", "https://idp.example.org")
+		cfg.Set("auth.oidc.clientID", "client-id")
+		cfg.Set("auth.oidc.clientSecret", "client-secret")
+		cfg.Set("auth.oidc.redirectURL", "https://example.com/callback")
+		provider := NewOIDCProvider(cfg)
+		assert.Equal(t, &OIDCProvider{
+			Issuer:       "https://idp.example.org",
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			RedirectURL:  "https://example.com/callback",
+			Scopes:       []string{"openid", "profile", "email"},
+		}, provider)
+	})
+
+	t.Run("discovery", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+		discovery, err := provider.Discovery(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, idp.server.URL+"/token", discovery.TokenEndpoint)
+		assert.Equal(t, idp.server.URL+"/jwks", discovery.JWKSURI)
+
+		// Cached
+		discovery2, err := provider.Discovery(context.Background())
+		require.NoError(t, err)
+		assert.Same(t, discovery, discovery2)
+		assert.Equal(t, 1, idp.discoveryCalls)
+	})
+
+	t.Run("discovery_issuer_mismatch", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+		provider.Issuer += "/"
+		_, err := provider.Discovery(context.Background())
+		require.ErrorContains(t, err, "doesn't match the configured issuer")
+	})
+
+	t.Run("discovery_error", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+		provider.Issuer = idp.server.URL + "/unknown"
+		_, err := provider.Discovery(context.Background())
+		require.ErrorContains(t, err, "unexpected status 404")
+	})
+
+	t.Run("auth_code_url", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+		require.NoError(t, err)
+		_, query := idp.authorize(t, authURL)
+		assert.Equal(t, url.Values{
+			"response_type":         {"code"},
+			"client_id":             {"client-id"},
+			"redirect_uri":          {"http://example.com/oidc/callback"},
+			"scope":                 {"openid email"},
+			"state":                 {"state"},
+			"nonce":                 {"nonce"},
+			"code_challenge":        {codeChallenge("verifier")},
+			"code_challenge_method": {"S256"},
+		}, query)
+	})
+
+	t.Run("exchange", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+		require.NoError(t, err)
+		code, _ := idp.authorize(t, authURL)
+
+		_, err = provider.Exchange(context.Background(), code, "wrong verifier")
+		require.ErrorContains(t, err, "invalid_grant")
+
+		code, _ = idp.authorize(t, authURL)
+		tokens, err := provider.Exchange(context.Background(), code, "verifier")
+		require.NoError(t, err)
+		assert.Equal(t, "access-token", tokens.AccessToken)
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.Equal(t, int64(3600), tokens.ExpiresIn)
+		assert.NotEmpty(t, tokens.IDToken)
+
+		provider.ClientSecret = "wrong"
+		code, _ = idp.authorize(t, authURL)
+		_, err = provider.Exchange(context.Background(), code, "verifier")
+		require.ErrorContains(t, err, "invalid_client")
+	})
+
+	t.Run("verify_id_token", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+
+		claims, err := provider.VerifyIDToken(context.Background(), idp.signIDToken(t, "nonce"), "nonce")
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", claims.Subject)
+		assert.Equal(t, idp.server.URL, claims.Issuer)
+		assert.Equal(t, "johndoe@example.org", claims.Email)
+		assert.Equal(t, "John Doe", claims.Name)
+		assert.Equal(t, []any{"admin"}, claims.Raw["roles"])
+
+		// Keys are cached
+		_, err = provider.VerifyIDToken(context.Background(), idp.signIDToken(t, "nonce"), "nonce")
+		require.NoError(t, err)
+		assert.Equal(t, 1, idp.jwksCalls)
+	})
+
+	t.Run("key_rotation", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+		_, err := provider.VerifyIDToken(context.Background(), idp.signIDToken(t, "nonce"), "nonce")
+		require.NoError(t, err)
+
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		idp.mu.Lock()
+		idp.key = key
+		idp.kid = "key-2"
+		idp.mu.Unlock()
+
+		_, err = provider.VerifyIDToken(context.Background(), idp.signIDToken(t, "nonce"), "nonce")
+		require.NoError(t, err)
+		assert.Equal(t, 2, idp.jwksCalls)
+	})
+
+	t.Run("invalid_id_tokens", func(t *testing.T) {
+		idp := newStubIdentityProvider(t)
+		provider := newTestOIDCProvider(idp)
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		sign := func(method jwt.SigningMethod, key any, claims jwt.MapClaims, kid string) string {
+			token := jwt.NewWithClaims(method, claims)
+			token.Header["kid"] = kid
+			s, err := token.SignedString(key)
+			require.NoError(t, err)
+			return s
+		}
+		validClaims := func(overrides jwt.MapClaims) jwt.MapClaims {
+			claims := jwt.MapClaims{
+				"iss":   idp.server.URL,
+				"sub":   "user-123",
+				"aud":   "client-id",
+				"iat":   time.Now().Unix(),
+				"exp":   time.Now().Add(time.Hour).Unix(),
+				"nonce": "nonce",
+			}
+			for k, v := range overrides {
+				if v == nil {
+					delete(claims, k)
+					continue
+				}
+				claims[k] = v
+			}
+			return claims
+		}
+
+		cases := []struct {
+			want  error
+			desc  string
+			token string
+		}{
+			{desc: "malformed", token: "not a token", want: ErrOIDCInvalidIDToken},
+			{desc: "wrong_issuer", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(jwt.MapClaims{"iss": "https://evil.example.org"}), "key-1"), want: ErrOIDCInvalidIDToken},
+			{desc: "wrong_audience", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(jwt.MapClaims{"aud": "other-client"}), "key-1"), want: ErrOIDCInvalidIDToken},
+			{desc: "wrong_authorized_party", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(jwt.MapClaims{"aud": []string{"client-id", "other-client"}, "azp": "other-client"}), "key-1"), want: ErrOIDCInvalidIDToken},
+			{desc: "expired", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}), "key-1"), want: ErrOIDCInvalidIDToken},
+			{desc: "no_expiry", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(jwt.MapClaims{"exp": nil}), "key-1"), want: ErrOIDCInvalidIDToken},
+			{desc: "no_subject", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(jwt.MapClaims{"sub": nil}), "key-1"), want: ErrOIDCInvalidIDToken},
+			{desc: "nonce_mismatch", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(jwt.MapClaims{"nonce": "other"}), "key-1"), want: ErrOIDCNonceMismatch},
+			{desc: "unknown_key", token: sign(jwt.SigningMethodRS256, idp.key, validClaims(nil), "unknown"), want: ErrOIDCInvalidIDToken},
+			{desc: "wrong_key", token: sign(jwt.SigningMethodES256, ecKey, validClaims(nil), "key-1"), want: ErrOIDCInvalidIDToken},
+			{desc: "hmac", token: sign(jwt.SigningMethodHS256, []byte("client-secret"), validClaims(nil), "key-1"), want: ErrOIDCInvalidIDToken},
+		}
+
+		for _, c := range cases {
+			t.Run(c.desc, func(t *testing.T) {
+				claims, err := provider.VerifyIDToken(context.Background(), c.token, "nonce")
+				assert.Nil(t, claims)
+				require.ErrorIs(t, err, c.want)
+			})
+		}
+	})
+
+	t.Run("json_web_keys", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+		jwk := jsonWebKey{
+			Kty: "EC",
+			Crv: "P-384",
+			X:   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+			Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+		}
+		key, err := jwk.publicKey()
+		require.NoError(t, err)
+		assert.True(t, ecKey.PublicKey.Equal(key))
+
+		jwk.Crv = "P-192"
+		_, err = jwk.publicKey()
+		require.Error(t, err)
+
+		_, err = jsonWebKey{Kty: "RSA", N: "!!!"}.publicKey()
+		require.Error(t, err)
+		_, err = jsonWebKey{Kty: "oct"}.publicKey()
+		require.Error(t, err)
+	})
+}
+
+type oidcTestClient struct {
+	server  *testutil.TestServer
+	router  *goyave.Router
+	cookies map[string]*http.Cookie
+}
+
+func (c *oidcTestClient) get(t *testing.T, path string) (*http.Response, string) {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	c.router.ServeHTTP(recorder, req)
+	resp := recorder.Result()
+	for _, cookie := range resp.Cookies() {
+		c.cookies[cookie.Name] = cookie
+	}
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	return resp, string(b)
+}
+
+func TestOIDCController(t *testing.T) {
+	prepare := func(t *testing.T, userService *mockOIDCUserService) (*stubIdentityProvider, *OIDCController[TestUser], *oidcTestClient) {
+		idp := newStubIdentityProvider(t)
+		cfg := config.LoadDefault()
+		cfg.Set("app.debug", false)
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+		router := goyave.NewRouter(server.Server)
+		router.GlobalMiddleware(&session.Middleware{Store: session.NewMemoryStore()})
+		controller := NewOIDCController[TestUser](newTestOIDCProvider(idp), userService)
+		router.Subrouter("/oidc").Controller(controller)
+		return idp, controller, &oidcTestClient{server: server, router: router, cookies: map[string]*http.Cookie{}}
+	}
+
+	login := func(t *testing.T, idp *stubIdentityProvider, client *oidcTestClient) (string, url.Values) {
+		resp, _ := client.get(t, "/oidc/login")
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		return idp.authorize(t, resp.Header.Get("Location"))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		userService := &mockOIDCUserService{}
+		idp, _, client := prepare(t, userService)
+		code, query := login(t, idp, client)
+		sessionID := client.cookies["goyave_session"].Value
+
+		resp, _ := client.get(t, "/oidc/callback?code="+code+"&state="+query.Get("state"))
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.Equal(t, "/", resp.Header.Get("Location"))
+		assert.NotEqual(t, sessionID, client.cookies["goyave_session"].Value) // Regenerated by SessionLogin
+		require.NotNil(t, userService.user)
+		assert.Equal(t, "johndoe@example.org", userService.user.Email)
+		assert.Equal(t, "John Doe", userService.user.Name)
+	})
+
+	t.Run("session_authentication", func(t *testing.T) {
+		userService := &mockOIDCUserService{}
+		idp, _, client := prepare(t, userService)
+		client.router.Get("/me", func(response *goyave.Response, request *goyave.Request) {
+			response.JSON(http.StatusOK, map[string]string{"email": request.User.(*TestUser).Email})
+		}).SetMeta(MetaAuth, true).Middleware(Middleware(NewSessionAuthenticator(&oidcSessionUserService{oidcUsers: userService})))
+
+		code, query := login(t, idp, client)
+		resp, _ := client.get(t, "/oidc/callback?code="+code+"&state="+query.Get("state"))
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+
+		// The username (email) differs from the ID token's subject
+		resp, body := client.get(t, "/me")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"email":"johndoe@example.org"}`, body)
+	})
+
+	t.Run("custom_login_func", func(t *testing.T) {
+		idp, controller, client := prepare(t, &mockOIDCUserService{})
+		controller.LoginFunc = func(response *goyave.Response, request *goyave.Request, user *TestUser, claims *OIDCClaims, tokens *OIDCTokens) {
+			require.NoError(t, SessionLogin(request, claims.Subject))
+			response.JSON(http.StatusOK, map[string]string{"email": user.Email, "accessToken": tokens.AccessToken})
+		}
+		code, query := login(t, idp, client)
+		resp, body := client.get(t, "/oidc/callback?code="+code+"&state="+query.Get("state"))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"email":"johndoe@example.org","accessToken":"access-token"}`, body)
+	})
+
+	t.Run("redirect_after_login", func(t *testing.T) {
+		idp, controller, client := prepare(t, &mockOIDCUserService{})
+		controller.RedirectAfterLogin = "/dashboard"
+		code, query := login(t, idp, client)
+		resp, _ := client.get(t, "/oidc/callback?code="+code+"&state="+query.Get("state"))
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.Equal(t, "/dashboard", resp.Header.Get("Location"))
+	})
+
+	t.Run("state_is_single_use", func(t *testing.T) {
+		idp, _, client := prepare(t, &mockOIDCUserService{})
+		code, query := login(t, idp, client)
+		resp, _ := client.get(t, "/oidc/callback?code="+code+"&state="+query.Get("state"))
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+
+		resp, body := client.get(t, "/oidc/callback?code="+code+"&state="+query.Get("state"))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.JSONEq(t, `{"error":"The authentication request is invalid or expired, please try again."}`, body)
+	})
+
+	cases := []struct {
+		userService *mockOIDCUserService
+		claims      jwt.MapClaims
+		callback    func(code string, query url.Values) string
+		desc        string
+		want        string
+		status      int
+	}{
+		{
+			desc:     "invalid_state",
+			callback: func(code string, _ url.Values) string { return "code=" + code + "&state=wrong" },
+			status:   http.StatusUnauthorized,
+			want:     "auth.oidc-invalid-state",
+		},
+		{
+			desc:     "provider_error",
+			callback: func(_ string, query url.Values) string { return "error=access_denied&state=" + query.Get("state") },
+			status:   http.StatusUnauthorized,
+			want:     "auth.oidc-failed",
+		},
+		{
+			desc:     "missing_code",
+			callback: func(_ string, query url.Values) string { return "state=" + query.Get("state") },
+			status:   http.StatusUnauthorized,
+			want:     "auth.oidc-failed",
+		},
+		{
+			desc:     "invalid_code",
+			callback: func(_ string, query url.Values) string { return "code=invalid&state=" + query.Get("state") },
+			status:   http.StatusInternalServerError,
+		},
+		{
+			desc:     "invalid_id_token",
+			claims:   jwt.MapClaims{"aud": "other-client"},
+			callback: func(code string, query url.Values) string { return "code=" + code + "&state=" + query.Get("state") },
+			status:   http.StatusUnauthorized,
+			want:     "auth.oidc-invalid-token",
+		},
+		{
+			desc:     "nonce_mismatch",
+			claims:   jwt.MapClaims{"nonce": "other"},
+			callback: func(code string, query url.Values) string { return "code=" + code + "&state=" + query.Get("state") },
+			status:   http.StatusUnauthorized,
+			want:     "auth.oidc-invalid-token",
+		},
+		{
+			desc:        "user_not_found",
+			userService: &mockOIDCUserService{err: gorm.ErrRecordNotFound},
+			callback:    func(code string, query url.Values) string { return "code=" + code + "&state=" + query.Get("state") },
+			status:      http.StatusUnauthorized,
+			want:        "auth.invalid-credentials",
+		},
+		{
+			desc:        "user_service_error",
+			userService: &mockOIDCUserService{err: fmt.Errorf("test error")},
+			callback:    func(code string, query url.Values) string { return "code=" + code + "&state=" + query.Get("state") },
+			status:      http.StatusInternalServerError,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			userService := c.userService
+			if userService == nil {
+				userService = &mockOIDCUserService{}
+			}
+			idp, _, client := prepare(t, userService)
+			idp.claims = c.claims
+			code, query := login(t, idp, client)
+			resp, body := client.get(t, "/oidc/callback?"+c.callback(code, query))
+			assert.Equal(t, c.status, resp.StatusCode)
+			if c.want != "" {
+				assert.JSONEq(t, `{"error":"`+client.server.Lang.GetDefault().Get(c.want)+`"}`, body)
+			}
+		})
+	}
+
+	t.Run("discovery_error", func(t *testing.T) {
+		idp, controller, client := prepare(t, &mockOIDCUserService{})
+		controller.Provider.Issuer = idp.server.URL + "/unknown"
+		resp, _ := client.get(t, "/oidc/login")
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("provider_from_config", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("auth.oidc.issuer", "https://idp.example.org")
+		cfg.Set("auth.oidc.clientID", "client-id")
+		cfg.Set("auth.oidc.redirectURL", "https://example.com/callback")
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+		controller := &OIDCController[TestUser]{}
+		controller.Init(server.Server)
+		require.NotNil(t, controller.Provider)
+		assert.Equal(t, "https://idp.example.org", controller.Provider.Issuer)
+	})
+}
@@ -0,0 +1,389 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/slog"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+//
+// Hashes are self-describing: they contain the identifier of the algorithm and the
+// parameters used to generate them, so a hasher can tell if it supports a hash and
+// if the hash should be upgraded.
+type PasswordHasher interface {
+	// Hash returns the hash of the given password.
+	Hash(password string) (string, error)
+
+	// Verify returns true if the given password matches the hash.
+	// Returns an error if the hash is malformed or not supported.
+	Verify(hash, password string) (bool, error)
+
+	// Supports returns true if the given hash was generated by an algorithm
+	// this hasher can verify.
+	Supports(hash string) bool
+
+	// NeedsRehash returns true if the given hash was not generated with the
+	// algorithm and parameters currently used by this hasher.
+	NeedsRehash(hash string) bool
+}
+
+// PasswordRehasher can be implemented by a `UserService` to upgrade stored
+// password hashes. After a successful authentication, if the `PasswordHasher` reports
+// that the stored hash needs to be rehashed, the authenticators call `UpdatePasswordHash`
+// with a new hash of the password. Failures are logged and don't fail the authentication.
+type PasswordRehasher[T any] interface {
+	UpdatePasswordHash(ctx context.Context, user *T, hash string) error
+}
+
+// DefaultPasswordHasher returns the hasher used when none is specified: new hashes
+// are generated with argon2id, bcrypt and scrypt hashes can still be verified.
+func DefaultPasswordHasher() *MultiPasswordHasher {
+	return &MultiPasswordHasher{
+		Default: NewArgon2idHasher(),
+		Others:  []PasswordHasher{NewBcryptHasher(), NewScryptHasher()},
+	}
+}
+
+// MultiPasswordHasher generates hashes using the `Default` hasher and verifies
+// hashes using whichever hasher supports them. This allows to migrate to a new
+// algorithm progressively: hashes generated by one of the `Others` hashers
+// need to be rehashed.
+type MultiPasswordHasher struct {
+	Default PasswordHasher
+	Others  []PasswordHasher
+}
+
+// Hash returns the hash of the given password generated by the `Default` hasher.
+func (h *MultiPasswordHasher) Hash(password string) (string, error) {
+	return h.Default.Hash(password)
+}
+
+// Verify the password using the first hasher supporting the given hash.
+func (h *MultiPasswordHasher) Verify(hash, password string) (bool, error) {
+	hasher := h.find(hash)
+	if hasher == nil {
+		return false, errors.New("unsupported password hash format")
+	}
+	return hasher.Verify(hash, password)
+}
+
+// Supports returns true if at least one of the hashers supports the given hash.
+func (h *MultiPasswordHasher) Supports(hash string) bool {
+	return h.find(hash) != nil
+}
+
+// NeedsRehash returns true if the given hash is not supported by the `Default` hasher
+// or if the `Default` hasher reports it needs to be rehashed.
+func (h *MultiPasswordHasher) NeedsRehash(hash string) bool {
+	return !h.Default.Supports(hash) || h.Default.NeedsRehash(hash)
+}
+
+func (h *MultiPasswordHasher) find(hash string) PasswordHasher {
+	if h.Default.Supports(hash) {
+		return h.Default
+	}
+	for _, hasher := range h.Others {
+		if hasher.Supports(hash) {
+			return hasher
+		}
+	}
+	return nil
+}
+
+// checkPassword verifies the password using the given hasher, or `DefaultPasswordHasher` if nil.
+// If the password matches, the user service implements `PasswordRehasher` and the hash
+// needs to be rehashed, the user service is given a new hash. Rehash failures are logged.
+func checkPassword[T any](request *goyave.Request, logger *slog.Logger, hasher PasswordHasher, userService UserService[T], user *T, hash, password string) bool {
+	if hasher == nil {
+		hasher = DefaultPasswordHasher()
+	}
+	ok, err := hasher.Verify(hash, password)
+	if err != nil || !ok {
+		return false
+	}
+
+	rehasher, isRehasher := userService.(PasswordRehasher[T])
+	if !isRehasher || !hasher.NeedsRehash(hash) {
+		return true
+	}
+	newHash, err := hasher.Hash(password)
+	if err == nil {
+		err = rehasher.UpdatePasswordHash(request.Context(), user, newHash)
+	}
+	if err != nil {
+		logger.Error(errors.New(err))
+	}
+	return true
+}
+
+//--------------------------------------------
+
+// BcryptHasher implementation of `PasswordHasher` using bcrypt.
+// Hashes use the standard "$2a$" format.
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher create a new bcrypt hasher using `bcrypt.DefaultCost`.
+func NewBcryptHasher() *BcryptHasher {
+	return &BcryptHasher{Cost: bcrypt.DefaultCost}
+}
+
+// Hash returns the bcrypt hash of the given password.
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hash), errors.New(err)
+}
+
+// Verify returns true if the given password matches the bcrypt hash.
+func (h *BcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch err {
+	case nil:
+		return true, nil
+	case bcrypt.ErrMismatchedHashAndPassword:
+		return false, nil
+	default:
+		return false, errors.New(err)
+	}
+}
+
+// Supports returns true if the given hash is a bcrypt hash.
+func (h *BcryptHasher) Supports(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// NeedsRehash returns true if the cost of the given hash is different from the hasher's cost.
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+//--------------------------------------------
+
+// maxArgon2idMemory the maximum memory parameter (in KiB) accepted when decoding an
+// argon2id hash, so a malformed hash cannot cause a huge allocation. 4 GiB.
+const maxArgon2idMemory = 4 * 1024 * 1024
+
+// Argon2idHasher implementation of `PasswordHasher` using argon2id.
+// Hashes use the PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+type Argon2idHasher struct {
+	// Memory the amount of memory used by the algorithm, in KiB.
+	Memory uint32
+
+	// Iterations the number of passes over the memory.
+	Iterations uint32
+
+	SaltLength uint32
+	KeyLength  uint32
+
+	// Parallelism the number of threads used by the algorithm.
+	Parallelism uint8
+}
+
+// NewArgon2idHasher create a new argon2id hasher using the parameters recommended by
+// OWASP: 64 MiB of memory, 3 iterations and a parallelism of 2.
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Hash returns the argon2id hash of the given password.
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt, err := generateSalt(h.SaltLength)
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify returns true if the given password matches the argon2id hash.
+func (h *Argon2idHasher) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := h.decode(hash)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// Supports returns true if the given hash is an argon2id hash.
+func (h *Argon2idHasher) Supports(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// NeedsRehash returns true if the parameters of the given hash are different from the hasher's parameters.
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, salt, key, err := h.decode(hash)
+	return err != nil ||
+		params.Memory != h.Memory ||
+		params.Iterations != h.Iterations ||
+		params.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength ||
+		uint32(len(key)) != h.KeyLength
+}
+
+func (h *Argon2idHasher) decode(hash string) (*Argon2idHasher, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, errors.New("invalid argon2id hash format")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, errors.New("unsupported argon2id version")
+	}
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, errors.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if params.Iterations < 1 || params.Parallelism < 1 || params.Memory > maxArgon2idMemory {
+		return nil, nil, nil, errors.New("invalid argon2id parameters")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, errors.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, errors.New("invalid argon2id key")
+	}
+	return params, salt, key, nil
+}
+
+//--------------------------------------------
+
+// maxScryptMemory the maximum memory (in bytes) scrypt can use to verify a decoded hash,
+// so a malformed hash cannot cause a huge allocation. 4 GiB, like `maxArgon2idMemory`.
+const maxScryptMemory = 4 << 30
+
+// ScryptHasher implementation of `PasswordHasher` using scrypt.
+// Hashes use a PHC-like string format where `ln` is the base-2 logarithm of N:
+//
+//	$scrypt$ln=15,r=8,p=1$<salt>$<key>
+type ScryptHasher struct {
+	// N the CPU/memory cost parameter. Must be a power of two greater than 1.
+	N int
+	R int
+	P int
+
+	SaltLength int
+	KeyLength  int
+}
+
+// NewScryptHasher create a new scrypt hasher with N=32768, r=8 and p=1.
+func NewScryptHasher() *ScryptHasher {
+	return &ScryptHasher{
+		N:          1 << 15,
+		R:          8,
+		P:          1,
+		SaltLength: 16,
+		KeyLength:  32,
+	}
+}
+
+// Hash returns the scrypt hash of the given password.
+func (h *ScryptHasher) Hash(password string) (string, error) {
+	salt, err := generateSalt(uint32(h.SaltLength))
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, h.N, h.R, h.P, h.KeyLength)
+	if err != nil {
+		return "", errors.New(err)
+	}
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		bits.TrailingZeros(uint(h.N)), h.R, h.P,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify returns true if the given password matches the scrypt hash.
+func (h *ScryptHasher) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := h.decode(hash)
+	if err != nil {
+		return false, err
+	}
+	other, err := scrypt.Key([]byte(password), salt, params.N, params.R, params.P, len(key))
+	if err != nil {
+		return false, errors.New(err)
+	}
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// Supports returns true if the given hash is a scrypt hash.
+func (h *ScryptHasher) Supports(hash string) bool {
+	return strings.HasPrefix(hash, "$scrypt$")
+}
+
+// NeedsRehash returns true if the parameters of the given hash are different from the hasher's parameters.
+func (h *ScryptHasher) NeedsRehash(hash string) bool {
+	params, salt, key, err := h.decode(hash)
+	return err != nil ||
+		params.N != h.N ||
+		params.R != h.R ||
+		params.P != h.P ||
+		len(salt) != h.SaltLength ||
+		len(key) != h.KeyLength
+}
+
+func (h *ScryptHasher) decode(hash string) (*ScryptHasher, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return nil, nil, nil, errors.New("invalid scrypt hash format")
+	}
+	var ln int
+	params := &ScryptHasher{}
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &ln, &params.R, &params.P); err != nil {
+		return nil, nil, nil, errors.Errorf("invalid scrypt parameters: %w", err)
+	}
+	if ln < 1 || ln > 30 {
+		return nil, nil, nil, errors.New("invalid scrypt cost parameter")
+	}
+	params.N = 1 << ln
+	// scrypt allocates 128*r*N bytes. Dividing avoids overflowing the product.
+	if params.R < 1 || params.P < 1 || params.R*params.P >= 1<<30 || params.R > maxScryptMemory/(128*params.N) {
+		return nil, nil, nil, errors.New("invalid scrypt parameters")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, nil, nil, errors.Errorf("invalid scrypt salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, errors.New("invalid scrypt key")
+	}
+	return params, salt, key, nil
+}
+
+func generateSalt(length uint32) ([]byte, error) {
+	salt := make([]byte, length)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.New(err)
+	}
+	return salt, nil
+}
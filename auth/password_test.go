@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockRehashUserService struct {
+	MockUserService[TestUser]
+	updateErr error
+	hashes    []string
+}
+
+func (s *mockRehashUserService) UpdatePasswordHash(_ context.Context, user *TestUser, hash string) error {
+	s.hashes = append(s.hashes, hash)
+	if s.updateErr != nil {
+		return s.updateErr
+	}
+	user.Password = hash
+	return nil
+}
+
+func TestPasswordHashers(t *testing.T) {
+	hashers := []struct {
+		hasher PasswordHasher
+		other  PasswordHasher
+		prefix string
+	}{
+		{prefix: "$2a$", hasher: &BcryptHasher{Cost: bcrypt.MinCost}, other: &BcryptHasher{Cost: bcrypt.MinCost + 1}},
+		{prefix: "$argon2id$v=19$m=1024,t=1,p=1$", hasher: &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}, other: &Argon2idHasher{Memory: 1024, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}},
+		{prefix: "$scrypt$ln=10,r=8,p=1$", hasher: &ScryptHasher{N: 1 << 10, R: 8, P: 1, SaltLength: 16, KeyLength: 32}, other: &ScryptHasher{N: 1 << 11, R: 8, P: 1, SaltLength: 16, KeyLength: 32}},
+	}
+
+	for _, h := range hashers {
+		t.Run(fmt.Sprintf("%T", h.hasher), func(t *testing.T) {
+			hash, err := h.hasher.Hash("secret")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, h.prefix), hash)
+			assert.True(t, h.hasher.Supports(hash))
+			assert.False(t, h.hasher.NeedsRehash(hash))
+			assert.True(t, h.other.NeedsRehash(hash))
+
+			other, err := h.hasher.Hash("secret")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, other, "hashes should be salted")
+
+			ok, err := h.hasher.Verify(hash, "secret")
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			// Parameters are read from the hash
+			ok, err = h.other.Verify(hash, "secret")
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = h.hasher.Verify(hash, "wrong")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			for _, invalid := range []string{"", "$argon2id$v=19$m=a,t=1,p=1$salt$key", "$scrypt$ln=0,r=8,p=1$salt$key", "$2a$invalid"} {
+				ok, err = h.hasher.Verify(invalid, "secret")
+				require.Error(t, err, invalid)
+				assert.False(t, ok)
+				assert.True(t, h.hasher.NeedsRehash(invalid))
+			}
+		})
+	}
+
+	t.Run("argon2id_invalid_parameters", func(t *testing.T) {
+		hasher := NewArgon2idHasher()
+		for _, invalid := range []string{
+			"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5",
+			"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdA$a2V5",
+		} {
+			ok, err := hasher.Verify(invalid, "secret")
+			require.Error(t, err, invalid)
+			assert.False(t, ok)
+			assert.True(t, hasher.NeedsRehash(invalid))
+		}
+	})
+
+	t.Run("scrypt_invalid_parameters", func(t *testing.T) {
+		hasher := NewScryptHasher()
+		for _, invalid := range []string{
+			"$scrypt$ln=10,r=0,p=1$c2FsdA$a2V5",
+			"$scrypt$ln=10,r=8,p=0$c2FsdA$a2V5",
+			"$scrypt$ln=10,r=-1,p=1$c2FsdA$a2V5",
+			"$scrypt$ln=1,r=1,p=1073741824$c2FsdA$a2V5",
+			"$scrypt$ln=1,r=32768,p=32768$c2FsdA$a2V5",
+			"$scrypt$ln=30,r=8,p=1$c2FsdA$a2V5",
+		} {
+			ok, err := hasher.Verify(invalid, "secret")
+			require.Error(t, err, invalid)
+			assert.False(t, ok)
+			assert.True(t, hasher.NeedsRehash(invalid))
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		bcryptHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+		require.NoError(t, err)
+		assert.True(t, NewBcryptHasher().Supports(string(bcryptHash)))
+		assert.False(t, NewArgon2idHasher().Supports(string(bcryptHash)))
+		assert.False(t, NewScryptHasher().Supports(string(bcryptHash)))
+		assert.False(t, NewBcryptHasher().Supports("$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$a2V5"))
+	})
+}
+
+func TestMultiPasswordHasher(t *testing.T) {
+	argon := &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	bcryptHasher := &BcryptHasher{Cost: bcrypt.MinCost}
+	hasher := &MultiPasswordHasher{
+		Default: argon,
+		Others:  []PasswordHasher{bcryptHasher},
+	}
+
+	hash, err := hasher.Hash("secret")
+	require.NoError(t, err)
+	assert.True(t, argon.Supports(hash))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	legacy, err := bcryptHasher.Hash("secret")
+	require.NoError(t, err)
+	assert.True(t, hasher.Supports(legacy))
+	assert.True(t, hasher.NeedsRehash(legacy))
+	ok, err := hasher.Verify(legacy, "secret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	scryptHash, err := (&ScryptHasher{N: 1 << 10, R: 8, P: 1, SaltLength: 16, KeyLength: 32}).Hash("secret")
+	require.NoError(t, err)
+	assert.False(t, hasher.Supports(scryptHash))
+	ok, err = hasher.Verify(scryptHash, "secret")
+	require.Error(t, err)
+	assert.False(t, ok)
+
+	defaultHasher := DefaultPasswordHasher()
+	assert.IsType(t, &Argon2idHasher{}, defaultHasher.Default)
+	assert.True(t, defaultHasher.Supports(legacy))
+	assert.True(t, defaultHasher.Supports(scryptHash))
+}
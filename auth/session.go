@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/middleware/session"
+	errorutil "goyave.dev/goyave/v5/util/errors"
+)
+
+// SessionUserKey the session key holding the "username" of the authenticated user.
+const SessionUserKey = "goyave.auth.user"
+
+// SessionAuthenticator implementation of Authenticator retrieving the user
+// from the current session. The session `Middleware` must be executed before
+// the authentication middleware.
+//
+// Users are logged in using `SessionLogin` and logged out using `SessionLogout`.
+//
+// The "username" stored in the session is passed to the `UserService`. Keep
+// in mind that session values are decoded as generic JSON values: numeric
+// identifiers are given as `float64` when using the built-in stores.
+//
+// The T parameter represents the user DTO and should not be a pointer.
+type SessionAuthenticator[T any] struct {
+	goyave.Component
+
+	UserService UserService[T]
+
+	// Optional defines if the authenticator allows requests that
+	// don't have an authenticated session. Handlers should therefore check
+	// if `request.User` is not `nil` before accessing it.
+	Optional bool
+}
+
+// NewSessionAuthenticator create a new authenticator retrieving the user from the current session.
+func NewSessionAuthenticator[T any](userService UserService[T]) *SessionAuthenticator[T] {
+	return &SessionAuthenticator[T]{
+		UserService: userService,
+	}
+}
+
+// Authenticate fetch the user corresponding to the "username" stored in the
+// current session. If the user doesn't exist anymore, it is removed from the session.
+//
+// Panics if the session middleware was not executed for this request.
+func (a *SessionAuthenticator[T]) Authenticate(request *goyave.Request) (*T, error) {
+	s := mustGetSession(request)
+
+	username, ok := s.Get(SessionUserKey)
+	if !ok {
+		if a.Optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s", request.Lang.Get("auth.session-required"))
+	}
+
+	user, err := a.UserService.FindByUsername(request.Context(), username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Delete(SessionUserKey)
+			return nil, fmt.Errorf("%s", request.Lang.Get("auth.session-invalid"))
+		}
+		panic(errorutil.New(err))
+	}
+
+	return user, nil
+}
+
+// HasCredentials returns true if the current session contains an authenticated user.
+func (a *SessionAuthenticator[T]) HasCredentials(request *goyave.Request) bool {
+	s := session.FromRequest(request)
+	if s == nil {
+		return false
+	}
+	_, ok := s.Get(SessionUserKey)
+	return ok
+}
+
+// SessionLogin stores the given "username" in the current session so the user
+// is authenticated by `SessionAuthenticator` in the next requests. The session ID is
+// regenerated to prevent session fixation attacks.
+//
+// Panics if the session middleware was not executed for this request.
+func SessionLogin(request *goyave.Request, username any) error {
+	s := mustGetSession(request)
+	if err := s.Regenerate(); err != nil {
+		return errorutil.New(err)
+	}
+	s.Set(SessionUserKey, username)
+	return nil
+}
+
+// SessionLogout removes the authenticated user from the current session and
+// regenerates the session ID. The other session values are kept.
+//
+// Panics if the session middleware was not executed for this request.
+func SessionLogout(request *goyave.Request) error {
+	s := mustGetSession(request)
+	s.Delete(SessionUserKey)
+	return errorutil.New(s.Regenerate())
+}
+
+func mustGetSession(request *goyave.Request) *session.Session {
+	s := session.FromRequest(request)
+	if s == nil {
+		panic(errorutil.New("auth: session middleware is required to use session authentication"))
+	}
+	return s
+}
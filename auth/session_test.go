@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/middleware/session"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+func prepareSessionRequest(t *testing.T, server *testutil.TestServer, values map[string]any) (*goyave.Request, *session.Session) {
+	store := session.NewMemoryStore()
+	request := server.NewTestRequest(http.MethodGet, "/protected", nil)
+	resp := server.TestMiddleware(&session.Middleware{Store: store}, request, func(_ *goyave.Response, r *goyave.Request) {
+		for k, v := range values {
+			session.FromRequest(r).Set(k, v)
+		}
+	})
+	assert.NoError(t, resp.Body.Close())
+	s := session.FromRequest(request)
+	require.NotNil(t, s)
+	return request, s
+}
+
+func TestSessionAuthenticator(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		authenticator := NewSessionAuthenticator[TestUser](&MockUserService[TestUser]{user: user})
+		authenticator.Init(server.Server)
+
+		request, _ := prepareSessionRequest(t, server, map[string]any{SessionUserKey: user.Email})
+		u, err := authenticator.Authenticate(request)
+		require.NoError(t, err)
+		assert.Same(t, user, u)
+	})
+
+	t.Run("not_logged_in", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		authenticator := NewSessionAuthenticator[TestUser](&MockUserService[TestUser]{user: user})
+		authenticator.Init(server.Server)
+
+		request, _ := prepareSessionRequest(t, server, nil)
+		u, err := authenticator.Authenticate(request)
+		assert.Nil(t, u)
+		require.EqualError(t, err, server.Lang.GetDefault().Get("auth.session-required"))
+
+		authenticator.Optional = true
+		u, err = authenticator.Authenticate(request)
+		assert.Nil(t, u)
+		require.NoError(t, err)
+	})
+
+	t.Run("user_not_found", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		authenticator := NewSessionAuthenticator[TestUser](&MockUserService[TestUser]{err: gorm.ErrRecordNotFound})
+		authenticator.Init(server.Server)
+
+		request, s := prepareSessionRequest(t, server, map[string]any{SessionUserKey: "johndoe@example.org"})
+		u, err := authenticator.Authenticate(request)
+		assert.Nil(t, u)
+		require.EqualError(t, err, server.Lang.GetDefault().Get("auth.session-invalid"))
+		_, ok := s.Get(SessionUserKey)
+		assert.False(t, ok)
+	})
+
+	t.Run("service_error", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		authenticator := NewSessionAuthenticator[TestUser](&MockUserService[TestUser]{err: fmt.Errorf("service error")})
+		authenticator.Init(server.Server)
+
+		request, _ := prepareSessionRequest(t, server, map[string]any{SessionUserKey: "johndoe@example.org"})
+		assert.Panics(t, func() {
+			_, _ = authenticator.Authenticate(request)
+		})
+	})
+
+	t.Run("no_session_middleware", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		authenticator := NewSessionAuthenticator[TestUser](&MockUserService[TestUser]{})
+		authenticator.Init(server.Server)
+		request := server.NewTestRequest(http.MethodGet, "/protected", nil)
+		assert.Panics(t, func() {
+			_, _ = authenticator.Authenticate(request)
+		})
+		assert.Panics(t, func() {
+			_ = SessionLogin(request, "johndoe")
+		})
+		assert.Panics(t, func() {
+			_ = SessionLogout(request)
+		})
+	})
+
+	t.Run("login_logout", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		authenticator := NewSessionAuthenticator[TestUser](&MockUserService[TestUser]{user: user})
+
+		router := goyave.NewRouter(server.Server)
+		router.GlobalMiddleware(&session.Middleware{Store: session.NewMemoryStore()}, Middleware(authenticator))
+		router.Post("/login", func(response *goyave.Response, request *goyave.Request) {
+			assert.NoError(t, SessionLogin(request, user.Email))
+			response.Status(http.StatusNoContent)
+		})
+		router.Post("/logout", func(response *goyave.Response, request *goyave.Request) {
+			assert.NoError(t, SessionLogout(request))
+			response.Status(http.StatusNoContent)
+		}).SetMeta(MetaAuth, true)
+		router.Get("/profile", func(response *goyave.Response, request *goyave.Request) {
+			response.String(http.StatusOK, request.User.(*TestUser).Email)
+		}).SetMeta(MetaAuth, true)
+
+		var cookie *http.Cookie
+		do := func(method, path string) *http.Response {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, nil)
+			if cookie != nil {
+				req.AddCookie(cookie)
+			}
+			router.ServeHTTP(recorder, req)
+			resp := recorder.Result()
+			for _, c := range resp.Cookies() {
+				if c.Name == "goyave_session" {
+					cookie = c
+				}
+			}
+			return resp
+		}
+
+		resp := do(http.MethodGet, "/profile")
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = do(http.MethodPost, "/login")
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.NotNil(t, cookie)
+		loginID := cookie.Value
+
+		resp = do(http.MethodGet, "/profile")
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, resp.Body.Close())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, user.Email, string(body))
+
+		resp = do(http.MethodPost, "/logout")
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.NotEqual(t, loginID, cookie.Value)
+
+		resp = do(http.MethodGet, "/profile")
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
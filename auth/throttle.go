@@ -0,0 +1,343 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"goyave.dev/goyave/v5"
+	errorutil "goyave.dev/goyave/v5/util/errors"
+)
+
+// LoginAttempts the failed login attempts recorded for a key (a username or an IP address).
+type LoginAttempts struct {
+	// LastFailure the time of the last failed attempt.
+	LastFailure time.Time
+
+	// LockedUntil the time until which the key is locked. Zero if not locked.
+	LockedUntil time.Time
+
+	// Failures the number of failed attempts since the last lockout.
+	Failures int
+
+	// Lockouts the number of consecutive lockouts. Used to compute the lockout duration.
+	Lockouts int
+}
+
+// LoginAttemptStore persists failed login attempts for `LoginThrottler`.
+//
+// Concurrent login requests can record failures for the same key at the same time,
+// so `Increment` must be atomic: implementations backed by a shared storage should
+// use its atomic operations (e.g. `INCR` with Redis, `UPDATE ... SET failures = failures + 1`
+// with SQL databases) instead of reading then writing the record.
+type LoginAttemptStore interface {
+	// Get returns the attempts recorded for the given key, or `nil, nil` if there are none.
+	Get(ctx context.Context, key string) (*LoginAttempts, error)
+
+	// Increment atomically increments the failures recorded for the given key, sets
+	// their last failure time to now and returns the updated attempts. A new record is
+	// created if there are none or if they expired. The record must be kept for at least
+	// the given time-to-live.
+	Increment(ctx context.Context, key string, ttl time.Duration) (*LoginAttempts, error)
+
+	// Save stores the attempts for the given key. The record can be discarded after
+	// the given time-to-live.
+	Save(ctx context.Context, key string, attempts *LoginAttempts, ttl time.Duration) error
+
+	// Delete removes the attempts recorded for the given key.
+	Delete(ctx context.Context, key string) error
+}
+
+// Lockout information about a key that has just been locked out by a `LoginThrottler`.
+type Lockout struct {
+	// Until the time at which the lockout ends.
+	Until time.Time
+
+	// Key the throttling key, prefixed with "user:" or "ip:".
+	Key string
+
+	// Username the username used in the last failed attempt.
+	Username string
+
+	// IP the IP address of the client that made the last failed attempt.
+	IP string
+
+	// Lockouts the number of consecutive lockouts of this key.
+	Lockouts int
+}
+
+// LockoutError returned when a login attempt is rejected because the username
+// or the client's IP address is locked out.
+type LockoutError struct {
+	message string
+
+	// RetryAfter the remaining duration of the lockout.
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return e.message
+}
+
+// retryAfterHeader returns the value of the `Retry-After` header, in seconds rounded up.
+func (e *LockoutError) retryAfterHeader() string {
+	return strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// writeUnauthorized responds with "429 Too Many Requests" and the `Retry-After` header
+// if the given error is a `*LockoutError`, and with "401 Unauthorized" otherwise.
+func writeUnauthorized(response *goyave.Response, err error) {
+	var lockoutErr *LockoutError
+	if errors.As(err, &lockoutErr) {
+		response.Header().Set("Retry-After", lockoutErr.retryAfterHeader())
+		response.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+		return
+	}
+	response.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+}
+
+// throttleError returns the given error if it is a `*LockoutError`. Panics otherwise
+// because other errors returned by `LoginThrottler` are unexpected store errors.
+func throttleError(err error) error {
+	if lockoutErr, ok := err.(*LockoutError); ok {
+		return lockoutErr
+	}
+	panic(err)
+}
+
+// LoginThrottler protects login endpoints against brute-force attacks by limiting
+// failed attempts per username and per client IP address.
+//
+// When a key reaches the maximum number of failures, it is locked out. Each
+// consecutive lockout doubles the lockout duration (exponential backoff), up to
+// `MaxLockoutDuration`. A successful login resets the username's attempts but not
+// the IP address's, so an attacker owning a valid account cannot clear its IP counter.
+//
+// The zero value is not usable: use `NewLoginThrottler`.
+type LoginThrottler struct {
+	Store LoginAttemptStore
+
+	// OnLockout if not nil, is called every time a username or an IP address is locked out.
+	OnLockout func(request *goyave.Request, lockout *Lockout)
+
+	// MaxAttempts the number of failed attempts allowed per username before lockout.
+	MaxAttempts int
+
+	// MaxAttemptsPerIP the number of failed attempts allowed per IP address before lockout.
+	// Should be higher than `MaxAttempts` because several users can share the same IP address.
+	// IP throttling is disabled if zero or negative.
+	MaxAttemptsPerIP int
+
+	// LockoutDuration the duration of the first lockout.
+	LockoutDuration time.Duration
+
+	// MaxLockoutDuration the maximum duration of a lockout.
+	MaxLockoutDuration time.Duration
+
+	// Window failures and lockout history older than this duration are forgotten.
+	Window time.Duration
+}
+
+// NewLoginThrottler create a new `LoginThrottler` allowing 5 failed attempts per username
+// and 20 per IP address in a 15 minutes window. The first lockout lasts 1 minute and
+// the duration doubles for each consecutive lockout, up to 1 hour.
+func NewLoginThrottler(store LoginAttemptStore) *LoginThrottler {
+	return &LoginThrottler{
+		Store:              store,
+		MaxAttempts:        5,
+		MaxAttemptsPerIP:   20,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+		Window:             15 * time.Minute,
+	}
+}
+
+// Check returns a `*LockoutError` if the given username or the client's IP address is locked out.
+// Other errors are unexpected store errors.
+func (t *LoginThrottler) Check(request *goyave.Request, username string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range t.keys(request, username) {
+		attempts, err := t.Store.Get(request.Context(), key)
+		if err != nil {
+			return errorutil.New(err)
+		}
+		if attempts != nil && attempts.LockedUntil.After(now) {
+			retryAfter = max(retryAfter, attempts.LockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return t.lockoutError(request, retryAfter)
+	}
+	return nil
+}
+
+// Failure records a failed attempt for the given username and the client's IP address.
+// If this attempt locks one of them out, returns a `*LockoutError`.
+// Other errors are unexpected store errors.
+func (t *LoginThrottler) Failure(request *goyave.Request, username string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for i, key := range t.keys(request, username) {
+		maxAttempts := t.MaxAttempts
+		if i > 0 {
+			maxAttempts = t.MaxAttemptsPerIP
+		}
+
+		attempts, err := t.Store.Increment(request.Context(), key, t.Window)
+		if err != nil {
+			return errorutil.New(err)
+		}
+		// Only the attempt reaching the maximum locks the key out, so concurrent
+		// failures don't result in several lockouts.
+		if attempts.Failures != maxAttempts {
+			continue
+		}
+
+		attempts.Failures = 0
+		attempts.Lockouts++
+		attempts.LockedUntil = now.Add(t.lockoutDuration(attempts.Lockouts))
+		retryAfter = max(retryAfter, attempts.LockedUntil.Sub(now))
+		if t.OnLockout != nil {
+			t.OnLockout(request, &Lockout{
+				Key:      key,
+				Username: username,
+				IP:       request.ClientIP(),
+				Until:    attempts.LockedUntil,
+				Lockouts: attempts.Lockouts,
+			})
+		}
+
+		ttl := attempts.LockedUntil.Sub(now) + t.Window
+		if err := t.Store.Save(request.Context(), key, attempts, ttl); err != nil {
+			return errorutil.New(err)
+		}
+	}
+	if retryAfter > 0 {
+		return t.lockoutError(request, retryAfter)
+	}
+	return nil
+}
+
+// Success resets the failed attempts of the given username.
+func (t *LoginThrottler) Success(request *goyave.Request, username string) error {
+	return errorutil.New(t.Store.Delete(request.Context(), t.keys(request, username)[0]))
+}
+
+func (t *LoginThrottler) keys(request *goyave.Request, username string) []string {
+	keys := []string{"user:" + strings.ToLower(username)}
+	if t.MaxAttemptsPerIP > 0 {
+		keys = append(keys, "ip:"+request.ClientIP())
+	}
+	return keys
+}
+
+func (t *LoginThrottler) lockoutDuration(lockouts int) time.Duration {
+	duration := t.LockoutDuration
+	for i := 1; i < lockouts && duration < t.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+	return min(duration, t.MaxLockoutDuration)
+}
+
+func (t *LoginThrottler) lockoutError(request *goyave.Request, retryAfter time.Duration) *LockoutError {
+	err := &LockoutError{RetryAfter: retryAfter}
+	err.message = request.Lang.Get("auth.too-many-attempts", ":seconds", err.retryAfterHeader())
+	return err
+}
+
+//--------------------------------------------
+
+type memoryAttempts struct {
+	expiresAt time.Time
+	attempts  LoginAttempts
+}
+
+// MemoryLoginAttemptStore a `LoginAttemptStore` keeping the attempts in memory. Expired
+// records are purged lazily when attempts are saved or incremented.
+//
+// The attempts are lost when the application restarts and are not shared between
+// instances. Use a shared store (e.g. a database or a cache) when running several instances.
+type MemoryLoginAttemptStore struct {
+	lastPurge time.Time
+	attempts  map[string]memoryAttempts
+	mu        sync.Mutex
+}
+
+// NewMemoryLoginAttemptStore create a new empty `MemoryLoginAttemptStore`.
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		attempts:  map[string]memoryAttempts{},
+		lastPurge: time.Now(),
+	}
+}
+
+// Get returns a copy of the attempts recorded for the given key, or `nil, nil` if
+// there are none or if they expired.
+func (s *MemoryLoginAttemptStore) Get(_ context.Context, key string) (*LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.attempts[key]
+	if !ok || !entry.expiresAt.After(time.Now()) {
+		return nil, nil
+	}
+	attempts := entry.attempts
+	return &attempts, nil
+}
+
+// Increment atomically increments the failures recorded for the given key and
+// returns a copy of the updated attempts.
+func (s *MemoryLoginAttemptStore) Increment(_ context.Context, key string, ttl time.Duration) (*LoginAttempts, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge(now)
+	entry, ok := s.attempts[key]
+	if !ok || !entry.expiresAt.After(now) {
+		entry = memoryAttempts{}
+	}
+	entry.attempts.Failures++
+	entry.attempts.LastFailure = now
+	if expiresAt := now.Add(ttl); expiresAt.After(entry.expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+	s.attempts[key] = entry
+	attempts := entry.attempts
+	return &attempts, nil
+}
+
+// Save stores a copy of the given attempts.
+func (s *MemoryLoginAttemptStore) Save(_ context.Context, key string, attempts *LoginAttempts, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge(now)
+	s.attempts[key] = memoryAttempts{attempts: *attempts, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// purge removes the expired records at most once per minute. The caller must hold the lock.
+func (s *MemoryLoginAttemptStore) purge(now time.Time) {
+	if now.Sub(s.lastPurge) < time.Minute {
+		return
+	}
+	for k, entry := range s.attempts {
+		if !entry.expiresAt.After(now) {
+			delete(s.attempts, k)
+		}
+	}
+	s.lastPurge = now
+}
+
+// Delete removes the attempts recorded for the given key.
+func (s *MemoryLoginAttemptStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.attempts, key)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,347 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+type errLoginAttemptStore struct {
+	*MemoryLoginAttemptStore
+	err error
+}
+
+func (s *errLoginAttemptStore) Get(_ context.Context, _ string) (*LoginAttempts, error) {
+	return nil, s.err
+}
+
+func (s *errLoginAttemptStore) Increment(_ context.Context, _ string, _ time.Duration) (*LoginAttempts, error) {
+	return nil, s.err
+}
+
+func (s *errLoginAttemptStore) Delete(_ context.Context, _ string) error {
+	return s.err
+}
+
+func newThrottleTestRequest(server *testutil.TestServer, ip string) *goyave.Request {
+	request := server.NewTestRequest(http.MethodPost, "/login", nil)
+	request.Request().RemoteAddr = ip + ":1234"
+	return request
+}
+
+func TestLoginThrottler(t *testing.T) {
+	t.Run("lockout_and_backoff", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		store := NewMemoryLoginAttemptStore()
+		throttler := NewLoginThrottler(store)
+		throttler.MaxAttempts = 3
+		lockouts := []*Lockout{}
+		throttler.OnLockout = func(_ *goyave.Request, lockout *Lockout) {
+			lockouts = append(lockouts, lockout)
+		}
+
+		request := newThrottleTestRequest(server, "192.0.2.10")
+		require.NoError(t, throttler.Check(request, "johndoe"))
+		require.NoError(t, throttler.Failure(request, "johndoe"))
+		require.NoError(t, throttler.Failure(request, "JohnDoe"))
+		assert.Empty(t, lockouts)
+
+		err := throttler.Failure(request, "johndoe")
+		require.Error(t, err)
+		lockoutErr, ok := err.(*LockoutError)
+		require.True(t, ok)
+		assert.Equal(t, time.Minute, lockoutErr.RetryAfter)
+		assert.Equal(t, server.Lang.GetDefault().Get("auth.too-many-attempts", ":seconds", "60"), lockoutErr.Error())
+		require.Len(t, lockouts, 1)
+		assert.Equal(t, "user:johndoe", lockouts[0].Key)
+		assert.Equal(t, "johndoe", lockouts[0].Username)
+		assert.Equal(t, "192.0.2.10", lockouts[0].IP)
+		assert.Equal(t, 1, lockouts[0].Lockouts)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), lockouts[0].Until, time.Second)
+
+		err = throttler.Check(request, "johndoe")
+		require.ErrorAs(t, err, &lockoutErr)
+		assert.InDelta(t, time.Minute, lockoutErr.RetryAfter, float64(time.Second))
+
+		// Other users are not affected
+		require.NoError(t, throttler.Check(request, "janedoe"))
+
+		// Lockout expired: the next lockout lasts twice as long
+		attempts, err := store.Get(context.Background(), "user:johndoe")
+		require.NoError(t, err)
+		attempts.LockedUntil = time.Now().Add(-time.Second)
+		require.NoError(t, store.Save(context.Background(), "user:johndoe", attempts, time.Minute))
+		require.NoError(t, throttler.Check(request, "johndoe"))
+		for range 2 {
+			require.NoError(t, throttler.Failure(request, "johndoe"))
+		}
+		err = throttler.Failure(request, "johndoe")
+		require.ErrorAs(t, err, &lockoutErr)
+		assert.Equal(t, 2*time.Minute, lockoutErr.RetryAfter)
+		assert.Equal(t, 2, lockouts[1].Lockouts)
+
+		// Success resets the user's attempts
+		require.NoError(t, throttler.Success(request, "johndoe"))
+		require.NoError(t, throttler.Check(request, "johndoe"))
+	})
+
+	t.Run("window", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		store := NewMemoryLoginAttemptStore()
+		throttler := NewLoginThrottler(store)
+		throttler.MaxAttempts = 2
+		request := newThrottleTestRequest(server, "192.0.2.10")
+
+		// The record expired because no failure happened during the window
+		require.NoError(t, store.Save(context.Background(), "user:johndoe", &LoginAttempts{
+			Failures:    1,
+			Lockouts:    3,
+			LastFailure: time.Now().Add(-throttler.Window - time.Second),
+		}, -time.Second))
+		require.NoError(t, throttler.Failure(request, "johndoe"))
+		attempts, err := store.Get(context.Background(), "user:johndoe")
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Failures)
+		assert.Equal(t, 0, attempts.Lockouts)
+	})
+
+	t.Run("concurrent_failures", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		store := NewMemoryLoginAttemptStore()
+		throttler := NewLoginThrottler(store)
+		throttler.MaxAttempts = 10
+		throttler.MaxAttemptsPerIP = 0
+		var mu sync.Mutex
+		lockouts := 0
+		throttler.OnLockout = func(_ *goyave.Request, _ *Lockout) {
+			mu.Lock()
+			lockouts++
+			mu.Unlock()
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 15)
+		for range 15 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- throttler.Failure(newThrottleTestRequest(server, "192.0.2.10"), "johndoe")
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		lockoutErrors := 0
+		for err := range errs {
+			if err != nil {
+				var lockoutErr *LockoutError
+				require.ErrorAs(t, err, &lockoutErr)
+				lockoutErrors++
+			}
+		}
+		// No failure is lost and the key is locked out exactly once
+		assert.Equal(t, 1, lockoutErrors)
+		assert.Equal(t, 1, lockouts)
+		attempts, err := store.Get(context.Background(), "user:johndoe")
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Lockouts)
+		assert.True(t, attempts.LockedUntil.After(time.Now()))
+	})
+
+	t.Run("max_lockout_duration", func(t *testing.T) {
+		throttler := NewLoginThrottler(nil)
+		assert.Equal(t, time.Minute, throttler.lockoutDuration(1))
+		assert.Equal(t, 32*time.Minute, throttler.lockoutDuration(6))
+		assert.Equal(t, time.Hour, throttler.lockoutDuration(7))
+		assert.Equal(t, time.Hour, throttler.lockoutDuration(100))
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		throttler := NewLoginThrottler(NewMemoryLoginAttemptStore())
+		throttler.MaxAttemptsPerIP = 3
+
+		request := newThrottleTestRequest(server, "192.0.2.10")
+		require.NoError(t, throttler.Failure(request, "user1"))
+		require.NoError(t, throttler.Failure(request, "user2"))
+		require.NoError(t, throttler.Success(request, "user2"))
+		err := throttler.Failure(request, "user3")
+		var lockoutErr *LockoutError
+		require.ErrorAs(t, err, &lockoutErr)
+
+		// Success doesn't reset the IP attempts
+		require.ErrorAs(t, throttler.Check(request, "user4"), &lockoutErr)
+		require.NoError(t, throttler.Check(newThrottleTestRequest(server, "192.0.2.11"), "user4"))
+
+		// Disabled
+		throttler.MaxAttemptsPerIP = 0
+		require.NoError(t, throttler.Check(request, "user4"))
+	})
+
+	t.Run("store_error", func(t *testing.T) {
+		server, _ := prepareAuthenticatorTest(t)
+		throttler := NewLoginThrottler(&errLoginAttemptStore{MemoryLoginAttemptStore: NewMemoryLoginAttemptStore(), err: fmt.Errorf("store error")})
+		request := newThrottleTestRequest(server, "192.0.2.10")
+
+		for _, err := range []error{throttler.Check(request, "johndoe"), throttler.Failure(request, "johndoe"), throttler.Success(request, "johndoe")} {
+			require.ErrorContains(t, err, "store error")
+			_, ok := err.(*LockoutError)
+			assert.False(t, ok)
+		}
+	})
+}
+
+func TestMemoryLoginAttemptStore(t *testing.T) {
+	store := NewMemoryLoginAttemptStore()
+	ctx := context.Background()
+
+	attempts, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, attempts)
+
+	saved := &LoginAttempts{Failures: 1}
+	require.NoError(t, store.Save(ctx, "key", saved, time.Minute))
+	attempts, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, saved, attempts)
+	assert.NotSame(t, saved, attempts)
+
+	require.NoError(t, store.Delete(ctx, "key"))
+	attempts, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, attempts)
+
+	// Expired
+	require.NoError(t, store.Save(ctx, "expired", saved, -time.Second))
+	attempts, err = store.Get(ctx, "expired")
+	require.NoError(t, err)
+	assert.Nil(t, attempts)
+
+	// Increment
+	attempts, err = store.Increment(ctx, "incr", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+	assert.WithinDuration(t, time.Now(), attempts.LastFailure, time.Second)
+	attempts, err = store.Increment(ctx, "incr", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts.Failures)
+	require.NoError(t, store.Save(ctx, "incr", &LoginAttempts{Failures: 1, Lockouts: 1}, time.Hour))
+	attempts, err = store.Increment(ctx, "incr", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, &LoginAttempts{Failures: 2, Lockouts: 1, LastFailure: attempts.LastFailure}, attempts)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), store.attempts["incr"].expiresAt, time.Second) // Not shortened
+	attempts, err = store.Increment(ctx, "expired", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+	require.NoError(t, store.Save(ctx, "expired", saved, -time.Second))
+
+	store.lastPurge = time.Now().Add(-2 * time.Minute)
+	require.NoError(t, store.Save(ctx, "key", saved, time.Minute))
+	assert.NotContains(t, store.attempts, "expired")
+	assert.Contains(t, store.attempts, "key")
+}
+
+func TestThrottledLogin(t *testing.T) {
+	t.Run("basic", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		a := NewBasicAuthenticator[TestUser](&MockUserService[TestUser]{user: user}, "Password")
+		a.Throttler = NewLoginThrottler(NewMemoryLoginAttemptStore())
+		a.Throttler.MaxAttempts = 2
+		middleware := Middleware[TestUser](a)
+
+		do := func(password string) *http.Response {
+			request := newThrottleTestRequest(server, "192.0.2.10")
+			request.Request().SetBasicAuth(user.Email, password)
+			request.Route = &goyave.Route{Meta: map[string]any{MetaAuth: true}}
+			resp := server.TestMiddleware(middleware, request, func(response *goyave.Response, _ *goyave.Request) {
+				response.Status(http.StatusOK)
+			})
+			assert.NoError(t, resp.Body.Close())
+			return resp
+		}
+
+		resp := do("wrong password")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+		assert.Empty(t, resp.Header.Get("Retry-After"))
+
+		resp = do("wrong password")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+		assert.Empty(t, resp.Header.Get("WWW-Authenticate"))
+
+		// Valid credentials are rejected during the lockout
+		resp = do("secret")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 60, retryAfter, 1)
+
+		a.Throttler.Store = &errLoginAttemptStore{err: fmt.Errorf("store error")}
+		request := newThrottleTestRequest(server, "192.0.2.10")
+		request.Request().SetBasicAuth(user.Email, "secret")
+		assert.Panics(t, func() {
+			_, _ = a.Authenticate(request)
+		})
+	})
+
+	t.Run("jwt_controller", func(t *testing.T) {
+		server, user := prepareAuthenticatorTest(t)
+		server.Config().Set("auth.jwt.secret", "secret")
+		controller := NewJWTController[TestUser](&MockUserService[TestUser]{user: user}, "Password")
+		controller.Throttler = NewLoginThrottler(NewMemoryLoginAttemptStore())
+		controller.Throttler.MaxAttempts = 2
+		server.RegisterRoutes(func(_ *goyave.Server, router *goyave.Router) {
+			router.Controller(controller)
+		})
+
+		do := func(username, password string) (*http.Response, map[string]string) {
+			body, err := json.Marshal(map[string]any{"username": username, "password": password})
+			require.NoError(t, err)
+			request := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			resp := server.TestRequest(request)
+			respBody, err := testutil.ReadJSONBody[map[string]string](resp.Body)
+			assert.NoError(t, resp.Body.Close())
+			require.NoError(t, err)
+			return resp, respBody
+		}
+
+		resp, _ := do(user.Email, "secret")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Unknown users are throttled too
+		controller.UserService = &MockUserService[TestUser]{err: gorm.ErrRecordNotFound}
+		resp, _ = do("unknown@example.org", "secret")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp, body := do("unknown@example.org", "secret")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+		assert.Equal(t, server.Lang.GetDefault().Get("auth.too-many-attempts", ":seconds", "60"), body["error"])
+
+		controller.UserService = &MockUserService[TestUser]{user: user}
+		resp, _ = do(user.Email, "wrong")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		resp, _ = do(user.Email, "wrong")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		resp, _ = do(user.Email, "secret")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+		controller.Throttler.Store = &errLoginAttemptStore{err: fmt.Errorf("store error")}
+		resp, _ = do(user.Email, "secret")
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
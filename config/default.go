@@ -18,7 +18,9 @@ var configDefaults = object{
 		"readHeaderTimeout":     &Entry{10, []any{}, reflect.Int, false, true},
 		"idleTimeout":           &Entry{20, []any{}, reflect.Int, false, true},
 		"websocketCloseTimeout": &Entry{10, []any{}, reflect.Int, false, true},
+		"shutdownTimeout":       &Entry{5, []any{}, reflect.Int, false, true},
 		"maxUploadSize":         &Entry{10.0, []any{}, reflect.Float64, false, true},
+		"trustedProxies":        &Entry{[]string{}, []any{}, reflect.String, true, true},
 		"proxy": object{
 			"protocol": &Entry{"http", []any{"http", "https"}, reflect.String, false, true},
 			"host":     &Entry{nil, []any{}, reflect.String, false, false},
@@ -10,10 +10,26 @@ var enUS = &Language{
 		"auth.jwt-invalid":               "Your authentication token is invalid.",
 		"auth.jwt-not-valid-yet":         "Your authentication token is not valid yet.",
 		"auth.jwt-expired":               "Your authentication token is expired.",
+		"auth.session-required":          "You must be logged in.",
+		"auth.session-invalid":           "Your session is invalid, please log in again.",
+		"auth.forbidden":                 "You are not allowed to perform this action.",
+		"auth.oidc-failed":               "The authentication with the identity provider failed.",
+		"auth.oidc-invalid-state":        "The authentication request is invalid or expired, please try again.",
+		"auth.oidc-invalid-token":        "The identity provider returned an invalid identity token.",
+		"auth.api-key-required":          "An API key is required.",
+		"auth.api-key-invalid":           "Your API key is invalid.",
+		"auth.api-key-expired":           "Your API key is expired.",
+		"auth.api-key-forbidden":         "Your API key is not allowed to perform this action.",
+		"auth.too-many-attempts":         "Too many failed login attempts. Please try again in :seconds seconds.",
+		"csrf.missing-token":             "The CSRF token is missing.",
+		"csrf.invalid-token":             "The CSRF token is invalid.",
+		"csrf.invalid-origin":            "The request origin is not allowed.",
 		"parse.invalid-query":            "Failed to parse query string due to invalid syntax or unexpected input format.",
 		"parse.json-invalid-body":        "The request Content-Type indicates JSON, but the request body is empty or invalid.",
 		"parse.invalid-content-for-type": "The request content does not match its type. E.g. invalid multipart/form-data or a problem with the file upload.",
 		"parse.error-in-request-body":    "Failed to read request body due to connection issues, timeouts, size mismatches, or corrupted data.",
+		"websocket.invalid-message":      "The message must be a JSON object with a \"type\" field and an optional \"payload\".",
+		"websocket.unknown-message-type": "Unknown message type.",
 	},
 	validation: validationLines{
 		rules: map[string]string{
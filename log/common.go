@@ -3,7 +3,6 @@ package log
 import (
 	"fmt"
 	"log/slog"
-	"net"
 	"strconv"
 
 	"github.com/samber/lo"
@@ -29,11 +28,7 @@ func CommonLogFormatter(ctx *Context) (string, []slog.Attr) {
 		}
 	}
 
-	host, _, err := net.SplitHostPort(req.RemoteAddr)
-
-	if err != nil {
-		host = req.RemoteAddr
-	}
+	host := ctx.Request.ClientIP()
 
 	uri := req.RequestURI
 
@@ -0,0 +1,71 @@
+package goyave
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RequestMatcher an additional condition a request must satisfy for a route or
+// a router to match. Matchers are checked after the path and before the method.
+// If one of them returns `false`, the route or router is skipped and the next
+// candidate matching the path is tried. This allows to register multiple routes
+// on the same path and method, for example for API versioning or content negotiation.
+//
+// The request given to the matcher is the raw request: it has not been parsed yet.
+type RequestMatcher func(request *http.Request) bool
+
+// HeaderMatcher returns a `RequestMatcher` checking that the request has the given header.
+//
+// If the value is not empty, at least one of the elements of the header's comma-separated
+// list must be equal to the value (case-insensitive). Unless the value contains a ";",
+// the elements' parameters are ignored. For example, `HeaderMatcher("Accept", "application/vnd.api.v2+json")`
+// matches "Accept: application/vnd.api.v2+json; q=0.9, application/json".
+func HeaderMatcher(name, value string) RequestMatcher {
+	ignoreParams := !strings.Contains(value, ";")
+	return func(request *http.Request) bool {
+		values := request.Header.Values(name)
+		if value == "" {
+			return len(values) > 0
+		}
+		for _, v := range values {
+			for element := range strings.SplitSeq(v, ",") {
+				if ignoreParams {
+					element, _, _ = strings.Cut(element, ";")
+				}
+				if strings.EqualFold(strings.TrimSpace(element), value) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// QueryMatcher returns a `RequestMatcher` checking that the query parameter
+// identified by the given name is present. If values are given, the parameter
+// must be equal to one of them.
+func QueryMatcher(name string, values ...string) RequestMatcher {
+	return func(request *http.Request) bool {
+		query := request.URL.Query()
+		if len(values) == 0 {
+			return query.Has(name)
+		}
+		for _, v := range query[name] {
+			for _, expected := range values {
+				if v == expected {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+func matchRequest(matchers []RequestMatcher, match *routeMatch) bool {
+	for _, m := range matchers {
+		if !m(match.httpRequest) {
+			return false
+		}
+	}
+	return true
+}
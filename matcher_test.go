@@ -0,0 +1,112 @@
+package goyave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderMatcher(t *testing.T) {
+	cases := []struct {
+		desc     string
+		name     string
+		value    string
+		header   []string
+		expected bool
+	}{
+		{desc: "presence", name: "X-Api-Version", value: "", header: []string{"2"}, expected: true},
+		{desc: "presence_missing", name: "X-Api-Version", value: "", expected: false},
+		{desc: "equal", name: "X-Api-Version", value: "2", header: []string{"2"}, expected: true},
+		{desc: "not_equal", name: "X-Api-Version", value: "2", header: []string{"1"}, expected: false},
+		{desc: "case_insensitive", name: "Accept", value: "application/vnd.api.v2+json", header: []string{"Application/VND.api.v2+json"}, expected: true},
+		{desc: "list", name: "Accept", value: "application/vnd.api.v2+json", header: []string{"text/html, application/vnd.api.v2+json"}, expected: true},
+		{desc: "multiple_headers", name: "Accept", value: "application/vnd.api.v2+json", header: []string{"text/html", "application/vnd.api.v2+json"}, expected: true},
+		{desc: "ignore_params", name: "Accept", value: "application/vnd.api.v2+json", header: []string{"application/vnd.api.v2+json; q=0.9, */*"}, expected: true},
+		{desc: "with_params", name: "Content-Type", value: "text/plain; charset=utf-8", header: []string{"text/plain; charset=utf-8"}, expected: true},
+		{desc: "with_params_not_equal", name: "Content-Type", value: "text/plain; charset=utf-8", header: []string{"text/plain"}, expected: false},
+		{desc: "missing", name: "Accept", value: "application/json", expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, v := range c.header {
+				req.Header.Add(c.name, v)
+			}
+			assert.Equal(t, c.expected, HeaderMatcher(c.name, c.value)(req))
+		})
+	}
+}
+
+func TestQueryMatcher(t *testing.T) {
+	cases := []struct {
+		desc     string
+		query    string
+		values   []string
+		expected bool
+	}{
+		{desc: "presence", query: "?version", expected: true},
+		{desc: "presence_with_value", query: "?version=2", expected: true},
+		{desc: "presence_missing", query: "?other=2", expected: false},
+		{desc: "value", query: "?version=2", values: []string{"2"}, expected: true},
+		{desc: "one_of_values", query: "?version=3", values: []string{"2", "3"}, expected: true},
+		{desc: "multiple", query: "?version=1&version=2", values: []string{"2"}, expected: true},
+		{desc: "not_equal", query: "?version=1", values: []string{"2"}, expected: false},
+		{desc: "missing", query: "", values: []string{"2"}, expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/"+c.query, nil)
+			assert.Equal(t, c.expected, QueryMatcher("version", c.values...)(req))
+		})
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	router := prepareRouterTest()
+
+	v2 := router.Subrouter("/api").Matcher(HeaderMatcher("Accept", "application/vnd.api.v2+json"))
+	v2.Get("/users", nil).Name("v2.users")
+
+	api := router.Subrouter("/api")
+	api.Get("/users", nil).Name("users.csv").Matcher(QueryMatcher("format", "csv"))
+	api.Get("/users", nil).Name("users.beta").Matcher(func(request *http.Request) bool {
+		return request.Header.Get("X-Beta") == "true"
+	}, QueryMatcher("beta"))
+	api.Get("/users", nil).Name("users")
+	api.Post("/only-json", nil).Name("only-json").Matcher(HeaderMatcher("Content-Type", "application/json"))
+
+	cases := []struct {
+		header        http.Header
+		method        string
+		url           string
+		expectedRoute string
+	}{
+		{method: http.MethodGet, url: "/api/users", header: http.Header{"Accept": {"application/vnd.api.v2+json"}}, expectedRoute: "v2.users"},
+		{method: http.MethodGet, url: "/api/users?format=csv", header: http.Header{"Accept": {"application/vnd.api.v2+json"}}, expectedRoute: "v2.users"},
+		{method: http.MethodGet, url: "/api/users?format=csv", expectedRoute: "users.csv"},
+		{method: http.MethodGet, url: "/api/users?format=json", expectedRoute: "users"},
+		{method: http.MethodGet, url: "/api/users?beta", header: http.Header{"X-Beta": {"true"}}, expectedRoute: "users.beta"},
+		{method: http.MethodGet, url: "/api/users", header: http.Header{"X-Beta": {"true"}}, expectedRoute: "users"},
+		{method: http.MethodGet, url: "/api/users", expectedRoute: "users"},
+		{method: http.MethodPost, url: "/api/only-json", header: http.Header{"Content-Type": {"application/json; charset=utf-8"}}, expectedRoute: "only-json"},
+		{method: http.MethodGet, url: "/api/only-json", header: http.Header{"Content-Type": {"application/json"}}, expectedRoute: RouteMethodNotAllowed},
+		{method: http.MethodPost, url: "/api/only-json", header: http.Header{"Content-Type": {"text/plain"}}, expectedRoute: RouteNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.expectedRoute, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.url, nil)
+			req.Header = c.header
+			if req.Header == nil {
+				req.Header = http.Header{}
+			}
+			match := routeMatch{currentPath: req.URL.Path, httpRequest: req}
+			router.match(c.method, &match)
+			assert.Equal(t, c.expectedRoute, match.route.name)
+		})
+	}
+}
@@ -130,8 +130,9 @@ func (m *languageMiddleware) Handle(next Handler) Handler {
 // This middleware requires the parse middleware.
 type validateRequestMiddleware struct {
 	Component
-	BodyRules  RuleSetFunc
-	QueryRules RuleSetFunc
+	BodyRules   RuleSetFunc
+	QueryRules  RuleSetFunc
+	ParamsRules RuleSetFunc
 }
 
 func (m *validateRequestMiddleware) Handle(next Handler) Handler {
@@ -147,7 +148,36 @@ func (m *validateRequestMiddleware) Handle(next Handler) Handler {
 		}
 		var errsBag *validation.Errors
 		var queryErrsBag *validation.Errors
+		var paramsErrsBag *validation.Errors
 		var errors []error
+		if m.ParamsRules != nil {
+			data := make(map[string]any, len(r.RouteParams))
+			for k, v := range r.RouteParams {
+				data[k] = v
+			}
+			opt := &validation.Options{
+				Context:  r.Context(),
+				Data:     data,
+				Rules:    m.ParamsRules(r).AsRules(),
+				Language: r.Lang,
+				DB:       db,
+				Config:   m.Config(),
+				Logger:   m.Logger(),
+				Extra:    extra,
+			}
+			r.Extra[ExtraParamsValidationRules{}] = opt.Rules
+			var err []error
+			paramsErrsBag, err = validation.Validate(opt)
+			if paramsErrsBag != nil {
+				r.Extra[ExtraParamsValidationError{}] = paramsErrsBag
+			}
+			if err != nil {
+				errors = append(errors, err...)
+			}
+			if paramsErrsBag == nil && err == nil {
+				r.Extra[ExtraValidatedParams{}] = opt.Data
+			}
+		}
 		if m.QueryRules != nil {
 			opt := &validation.Options{
 				Context:                  r.Context(),
@@ -199,7 +229,7 @@ func (m *validateRequestMiddleware) Handle(next Handler) Handler {
 			return
 		}
 
-		if errsBag != nil || queryErrsBag != nil {
+		if errsBag != nil || queryErrsBag != nil || paramsErrsBag != nil {
 			response.Status(http.StatusUnprocessableEntity)
 			return
 		}
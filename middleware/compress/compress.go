@@ -3,6 +3,7 @@ package compress
 import (
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/samber/lo"
 	"goyave.dev/goyave/v5"
@@ -36,6 +37,7 @@ type compressWriter struct {
 	childWriter    io.Writer
 	encoding       string
 	empty          bool
+	passthrough    bool
 }
 
 func (w *compressWriter) PreWrite(b []byte) {
@@ -47,14 +49,30 @@ func (w *compressWriter) PreWrite(b []byte) {
 	if h.Get("Content-Type") == "" {
 		h.Set("Content-Type", http.DetectContentType(b))
 	}
+	if isEventStream(h.Get("Content-Type")) {
+		// Streams must reach the client as soon as they are flushed.
+		// Compressing them would buffer the events in the encoder.
+		w.passthrough = true
+		return
+	}
 	h.Set("Content-Encoding", w.encoding)
 	h.Add("Vary", "Accept-Encoding")
 	h.Del("Content-Length")
 }
 
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		n, err := w.childWriter.Write(b)
+		return n, errors.New(err)
+	}
+	return w.CommonWriter.Write(b)
+}
+
 func (w *compressWriter) Flush() error {
-	if err := w.CommonWriter.Flush(); err != nil {
-		return errors.New(err)
+	if !w.passthrough {
+		if err := w.CommonWriter.Flush(); err != nil {
+			return errors.New(err)
+		}
 	}
 	switch flusher := w.childWriter.(type) {
 	case goyave.Flusher:
@@ -66,7 +84,7 @@ func (w *compressWriter) Flush() error {
 }
 
 func (w *compressWriter) Close() error {
-	if w.empty {
+	if w.empty || w.passthrough {
 		// Do not write gzip/br/... footer if nothing has been written to the response body.
 		if r, ok := w.Writer().(resettable); ok {
 			r.Reset(io.Discard)
@@ -81,6 +99,11 @@ func (w *compressWriter) Close() error {
 	return err
 }
 
+func isEventStream(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), "text/event-stream")
+}
+
 // Middleware compresses HTTP responses.
 //
 // This middleware supports multiple algorithms thanks to the `Encoders` slice.
@@ -103,6 +126,8 @@ func (w *compressWriter) Close() error {
 // and set the `Content-Type` header using `http.DetectContentType()`.
 //
 // The middleware ignores hijacked responses or requests containing the `Upgrade` header.
+// Responses with the `text/event-stream` content type are not compressed so
+// server-sent events are not buffered.
 //
 // **Example:**
 //
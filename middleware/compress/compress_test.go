@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -199,6 +200,74 @@ func TestCompressMiddleware(t *testing.T) {
 		assert.Empty(t, body)
 		assert.NoError(t, result.Body.Close())
 	})
+
+	t.Run("event_stream", func(t *testing.T) {
+		request := testutil.NewTestRequest(http.MethodGet, "/gzip", nil)
+		request.Header().Set("Accept-Encoding", "gzip")
+		result := server.TestMiddleware(compressMiddleware, request, func(r *goyave.Response, _ *goyave.Request) {
+			r.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			r.String(http.StatusOK, "data: first\n\n")
+			r.Flush()
+			r.String(http.StatusOK, "data: second\n\n")
+			r.Flush()
+		})
+
+		assert.Empty(t, result.Header.Get("Content-Encoding"))
+		assert.Empty(t, result.Header.Get("Vary"))
+		body, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "data: first\n\ndata: second\n\n", string(body))
+		assert.NoError(t, result.Body.Close())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		overrun := func(resp *goyave.Response, req *goyave.Request) {
+			<-req.Context().Done()
+			resp.String(http.StatusOK, "too late")
+		}
+
+		t.Run("registered_after", func(t *testing.T) {
+			router := goyave.NewRouter(server.Server)
+			router.Timeout(20 * time.Millisecond)
+			router.GlobalMiddleware(compressMiddleware)
+			router.Get("/overrun", overrun)
+
+			request := httptest.NewRequest(http.MethodGet, "/overrun", nil)
+			request.Header.Set("Accept-Encoding", "gzip")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			result := recorder.Result()
+
+			assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+			assert.Empty(t, result.Header.Get("Content-Encoding"))
+			body, err := io.ReadAll(result.Body)
+			require.NoError(t, err)
+			assert.Equal(t, "{\"error\":\"Service Unavailable\"}\n", string(body))
+			assert.NoError(t, result.Body.Close())
+		})
+
+		t.Run("registered_before", func(t *testing.T) {
+			router := goyave.NewRouter(server.Server)
+			router.GlobalMiddleware(compressMiddleware)
+			router.Timeout(20 * time.Millisecond)
+			router.Get("/overrun", overrun)
+
+			request := httptest.NewRequest(http.MethodGet, "/overrun", nil)
+			request.Header.Set("Accept-Encoding", "gzip")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+			result := recorder.Result()
+
+			assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+			assert.Equal(t, "gzip", result.Header.Get("Content-Encoding"))
+			reader, err := gzip.NewReader(result.Body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, "{\"error\":\"Service Unavailable\"}\n", string(body))
+			assert.NoError(t, result.Body.Close())
+		})
+	})
 }
 
 func TestCompressWriter(t *testing.T) {
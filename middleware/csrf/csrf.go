@@ -0,0 +1,393 @@
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/middleware/session"
+	errorutil "goyave.dev/goyave/v5/util/errors"
+)
+
+// MetaExempt the CSRF middleware skips the verification if this meta is present
+// in the matched route or any of its parent and is equal to `true`.
+const MetaExempt = "goyave.csrf-exempt"
+
+const (
+	// DefaultHeaderName the default name of the request header containing the token.
+	DefaultHeaderName = "X-CSRF-Token"
+
+	// DefaultFieldName the default name of the request body field containing the token.
+	DefaultFieldName = "_csrf"
+
+	// SessionKey the session key holding the token when using the `Synchronizer` mode.
+	SessionKey = "goyave.csrf.token"
+
+	tokenLength = 32
+)
+
+var (
+	// ErrMissingToken the request doesn't contain a CSRF token.
+	ErrMissingToken = fmt.Errorf("csrf: missing token")
+
+	// ErrInvalidToken the CSRF token of the request doesn't match the expected token.
+	ErrInvalidToken = fmt.Errorf("csrf: invalid token")
+
+	// ErrInvalidOrigin the `Origin` or `Referer` of the request is not trusted.
+	ErrInvalidOrigin = fmt.Errorf("csrf: invalid origin")
+)
+
+// ExtraToken the key used to store the masked CSRF token in the request's `Extra`.
+// Use `Token` to retrieve it.
+type ExtraToken struct{}
+
+// ExtraError the key used to store the reason why the CSRF verification
+// failed in the request's `Extra`. The value is one of `ErrMissingToken`,
+// `ErrInvalidToken` or `ErrInvalidOrigin`.
+type ExtraError struct{}
+
+// Mode defines where the expected CSRF token is stored.
+type Mode int
+
+const (
+	// DoubleSubmit the token is stored in a cookie. The token submitted with the request
+	// must match the value of the cookie. The cookie is not HttpOnly so client-side
+	// scripts can read it and send it in the request header.
+	DoubleSubmit Mode = iota
+
+	// Synchronizer the token is stored in the session. The session `Middleware`
+	// must be executed before the CSRF middleware.
+	Synchronizer
+)
+
+func init() {
+	config.Register("csrf.cookie.name", config.Entry{
+		Value:            "goyave_csrf",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("csrf.cookie.path", config.Entry{
+		Value:            "/",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("csrf.cookie.domain", config.Entry{
+		Value:            "",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("csrf.cookie.secure", config.Entry{
+		Value:            true,
+		Type:             reflect.Bool,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("csrf.cookie.sameSite", config.Entry{
+		Value:            "Lax",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{"Strict", "Lax", "None"},
+	})
+}
+
+// Middleware protecting routes against Cross-Site Request Forgery.
+//
+// Requests using a safe method (GET, HEAD, OPTIONS, TRACE) are not verified. For
+// the other requests:
+//  1. if the `Origin` header is present, it must match the request's scheme and host (`Request.Scheme()`
+//     and `Request.Host()`) or one of the `TrustedOrigins`. Otherwise, the `Referer` header is checked
+//     the same way if present.
+//  2. the token submitted in the `HeaderName` header, or in the `FieldName` field of the
+//     request body, must match the expected token. The expected token is stored
+//     in a cookie or in the session depending on the `Mode`.
+//
+// On failure, the middleware blocks with "403 Forbidden" and stores the reason in the
+// request's `Extra` with the key `ExtraError`. Register the `StatusHandler` to return
+// a translated message to the client.
+//
+// For every request, the token is generated if needed and made available with `Token`
+// so it can be embedded in forms. The returned token is masked differently each time to
+// mitigate BREACH attacks.
+//
+// Routes can opt out by setting the `MetaExempt` meta to `true`.
+//
+// The token is read from the body only if the parse middleware is executed before
+// the CSRF middleware.
+//
+//	router.GlobalMiddleware(&csrf.Middleware{})
+//	router.StatusHandler(&csrf.StatusHandler{}, http.StatusForbidden)
+type Middleware struct {
+	goyave.Component
+
+	// HeaderName the name of the request header containing the token.
+	// Defaults to `DefaultHeaderName`.
+	HeaderName string
+
+	// FieldName the name of the request body field containing the token.
+	// Defaults to `DefaultFieldName`.
+	FieldName string
+
+	// TrustedOrigins the origins allowed to send unsafe requests in addition to
+	// the request's host. Origins are in the "scheme://host[:port]" format.
+	TrustedOrigins []string
+
+	// Mode defines where the expected token is stored. Defaults to `DoubleSubmit`.
+	Mode Mode
+}
+
+// Handle implementation of `goyave.Middleware`.
+func (m *Middleware) Handle(next goyave.Handler) goyave.Handler {
+	return func(response *goyave.Response, request *goyave.Request) {
+		token := m.getToken(request)
+		if token == nil {
+			token = generateToken()
+			m.storeToken(response, request, token)
+		}
+		request.Extra[ExtraToken{}] = mask(token)
+
+		if isSafeMethod(request.Method()) {
+			next(response, request)
+			return
+		}
+		if exempt, ok := request.Route.LookupMeta(MetaExempt); ok && exempt == true {
+			next(response, request)
+			return
+		}
+
+		if err := m.verify(request, token); err != nil {
+			request.Extra[ExtraError{}] = err
+			request.Extra[goyave.ExtraForbiddenReason{}] = errorMessage(request, err)
+			response.Status(http.StatusForbidden)
+			return
+		}
+
+		next(response, request)
+	}
+}
+
+func (m *Middleware) verify(request *goyave.Request, expected []byte) error {
+	if !m.checkOrigin(request) {
+		return ErrInvalidOrigin
+	}
+
+	submitted := m.getSubmittedToken(request)
+	if submitted == "" {
+		return ErrMissingToken
+	}
+	token := unmask(submitted)
+	if token == nil || subtle.ConstantTimeCompare(token, expected) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func (m *Middleware) checkOrigin(request *goyave.Request) bool {
+	origin := request.Header().Get("Origin")
+	if origin == "" || origin == "null" {
+		referer := request.Header().Get("Referer")
+		if referer == "" {
+			// Neither header is present (non-browser clients, privacy settings).
+			// The token verification is still required.
+			return origin == ""
+		}
+		u, err := url.Parse(referer)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Scheme, request.Scheme()) && strings.EqualFold(u.Host, request.Host()) {
+		return true
+	}
+	for _, trusted := range m.TrustedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(trusted, "/"), u.Scheme+"://"+u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Middleware) getSubmittedToken(request *goyave.Request) string {
+	headerName := m.HeaderName
+	if headerName == "" {
+		headerName = DefaultHeaderName
+	}
+	if token := request.Header().Get(headerName); token != "" {
+		return token
+	}
+
+	fieldName := m.FieldName
+	if fieldName == "" {
+		fieldName = DefaultFieldName
+	}
+	if data, ok := request.Data.(map[string]any); ok {
+		if token, ok := data[fieldName].(string); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+func (m *Middleware) getToken(request *goyave.Request) []byte {
+	var value string
+	switch m.Mode {
+	case Synchronizer:
+		s := mustGetSession(request)
+		v, _ := s.Get(SessionKey)
+		value, _ = v.(string)
+	default:
+		cookie, err := request.Request().Cookie(m.Config().GetString("csrf.cookie.name"))
+		if err != nil {
+			return nil
+		}
+		value = cookie.Value
+	}
+
+	token, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(token) != tokenLength {
+		return nil
+	}
+	return token
+}
+
+func (m *Middleware) storeToken(response *goyave.Response, request *goyave.Request, token []byte) {
+	value := base64.RawURLEncoding.EncodeToString(token)
+	switch m.Mode {
+	case Synchronizer:
+		mustGetSession(request).Set(SessionKey, value)
+	default:
+		cfg := m.Config()
+		sameSite := http.SameSiteLaxMode
+		switch cfg.GetString("csrf.cookie.sameSite") {
+		case "Strict":
+			sameSite = http.SameSiteStrictMode
+		case "None":
+			sameSite = http.SameSiteNoneMode
+		}
+		response.Cookie(&http.Cookie{
+			Name:     cfg.GetString("csrf.cookie.name"),
+			Value:    value,
+			Path:     cfg.GetString("csrf.cookie.path"),
+			Domain:   cfg.GetString("csrf.cookie.domain"),
+			Secure:   cfg.GetBool("csrf.cookie.secure"),
+			SameSite: sameSite,
+		})
+	}
+}
+
+func mustGetSession(request *goyave.Request) *session.Session {
+	s := session.FromRequest(request)
+	if s == nil {
+		panic(errorutil.New("csrf: session middleware is required to use the synchronizer token mode"))
+	}
+	return s
+}
+
+// Token returns the masked CSRF token of the given request, or an empty string
+// if the CSRF middleware was not executed for this request. The token should be
+// sent by the client in the request header or body of unsafe requests.
+func Token(request *goyave.Request) string {
+	token, _ := request.Extra[ExtraToken{}].(string)
+	return token
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func generateToken() []byte {
+	token := make([]byte, tokenLength)
+	if _, err := rand.Read(token); err != nil {
+		panic(errorutil.New(err))
+	}
+	return token
+}
+
+// mask XORs the token with a random one-time pad so the token sent
+// in responses is different every time.
+func mask(token []byte) string {
+	pad := generateToken()
+	masked := make([]byte, tokenLength*2)
+	copy(masked, pad)
+	for i, b := range token {
+		masked[tokenLength+i] = b ^ pad[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+// unmask returns the original token from a masked token. Unmasked tokens
+// (e.g. read from the double-submit cookie by client-side scripts) are also accepted.
+// Returns nil if the token is invalid.
+func unmask(value string) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	switch len(b) {
+	case tokenLength:
+		return b
+	case tokenLength * 2:
+		token := make([]byte, tokenLength)
+		for i := range token {
+			token[i] = b[tokenLength+i] ^ b[i]
+		}
+		return token
+	}
+	return nil
+}
+
+// StatusHandler for HTTP 403 errors. If the request was blocked by the CSRF
+// middleware, writes the translated reason to the response. Otherwise, writes
+// the status text, like `goyave.ErrorStatusHandler`.
+//
+// The middleware also stores the translated reason in the request's `goyave.ExtraForbiddenReason`.
+// If other middleware deny requests with "403 Forbidden" (e.g. the authorization layer
+// of the `auth` package), register `goyave.ForbiddenStatusHandler` instead: it handles
+// the requests denied by all of them.
+type StatusHandler struct {
+	goyave.Component
+}
+
+// Handle forbidden responses.
+func (*StatusHandler) Handle(response *goyave.Response, request *goyave.Request) {
+	message := map[string]string{
+		"error": http.StatusText(response.GetStatus()),
+	}
+	if err, ok := request.Extra[ExtraError{}].(error); ok {
+		message["error"] = errorMessage(request, err)
+	}
+	response.JSON(response.GetStatus(), message)
+}
+
+// errorMessage returns the translated reason why the given verification error
+// blocked the request.
+func errorMessage(request *goyave.Request, err error) string {
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		return request.Lang.Get("csrf.missing-token")
+	case errors.Is(err, ErrInvalidOrigin):
+		return request.Lang.Get("csrf.invalid-origin")
+	default:
+		return request.Lang.Get("csrf.invalid-token")
+	}
+}
@@ -0,0 +1,228 @@
+package csrf
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/middleware/parse"
+	"goyave.dev/goyave/v5/middleware/session"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+type csrfTestClient struct {
+	server  *testutil.TestServer
+	router  *goyave.Router
+	cookies map[string]*http.Cookie
+}
+
+func (c *csrfTestClient) do(t *testing.T, method, path string, headers map[string]string, body io.Reader) (*http.Response, string) {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, body)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	c.router.ServeHTTP(recorder, req)
+	resp := recorder.Result()
+	for _, cookie := range resp.Cookies() {
+		c.cookies[cookie.Name] = cookie
+	}
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	return resp, string(b)
+}
+
+func prepareCSRFTest(t *testing.T, middleware ...goyave.Middleware) *csrfTestClient {
+	cfg := config.LoadDefault()
+	cfg.Set("app.debug", false)
+	server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+
+	router := goyave.NewRouter(server.Server)
+	router.GlobalMiddleware(middleware...)
+	router.StatusHandler(&StatusHandler{}, http.StatusForbidden)
+	router.Get("/form", func(response *goyave.Response, request *goyave.Request) {
+		response.String(http.StatusOK, Token(request))
+	})
+	router.Post("/submit", func(response *goyave.Response, _ *goyave.Request) {
+		response.Status(http.StatusNoContent)
+	})
+	router.Post("/webhook", func(response *goyave.Response, _ *goyave.Request) {
+		response.Status(http.StatusNoContent)
+	}).SetMeta(MetaExempt, true)
+	router.Delete("/forbidden", func(response *goyave.Response, _ *goyave.Request) {
+		response.Status(http.StatusForbidden)
+	}).SetMeta(MetaExempt, true)
+
+	return &csrfTestClient{server: server, router: router, cookies: map[string]*http.Cookie{}}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("double_submit", func(t *testing.T) {
+		client := prepareCSRFTest(t, &parse.Middleware{}, &Middleware{TrustedOrigins: []string{"https://trusted.example.org/"}})
+		lang := client.server.Lang.GetDefault()
+
+		resp, token := client.do(t, http.MethodGet, "/form", nil, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		cookie := client.cookies["goyave_csrf"]
+		require.NotNil(t, cookie)
+		assert.False(t, cookie.HttpOnly)
+		assert.True(t, cookie.Secure)
+		assert.Equal(t, "/", cookie.Path)
+		assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+		assert.Equal(t, cookie.Value, base64.RawURLEncoding.EncodeToString(unmask(token)))
+
+		// The cookie is not renewed and the token is masked differently
+		_, token2 := client.do(t, http.MethodGet, "/form", nil, nil)
+		assert.Same(t, cookie, client.cookies["goyave_csrf"])
+		assert.NotEqual(t, token, token2)
+
+		form := url.Values{DefaultFieldName: {token}}.Encode()
+		cases := []struct {
+			headers map[string]string
+			desc    string
+			path    string
+			body    string
+			want    string
+			status  int
+		}{
+			{desc: "masked_header", path: "/submit", headers: map[string]string{DefaultHeaderName: token}, status: http.StatusNoContent},
+			{desc: "raw_header", path: "/submit", headers: map[string]string{DefaultHeaderName: cookie.Value}, status: http.StatusNoContent},
+			{desc: "form_field", path: "/submit", headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, body: form, status: http.StatusNoContent},
+			{desc: "same_origin", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Origin": "http://example.com"}, status: http.StatusNoContent},
+			{desc: "trusted_origin", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Origin": "https://trusted.example.org"}, status: http.StatusNoContent},
+			{desc: "same_origin_referer", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Referer": "http://example.com/form"}, status: http.StatusNoContent},
+			{desc: "exempt", path: "/webhook", status: http.StatusNoContent},
+			{desc: "missing_token", path: "/submit", status: http.StatusForbidden, want: lang.Get("csrf.missing-token")},
+			{desc: "invalid_token", path: "/submit", headers: map[string]string{DefaultHeaderName: "invalid"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-token")},
+			{desc: "wrong_token", path: "/submit", headers: map[string]string{DefaultHeaderName: mask(generateToken())}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-token")},
+			{desc: "untrusted_origin", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Origin": "https://evil.example.org"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-origin")},
+			{desc: "cross_scheme_origin", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Origin": "https://example.com"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-origin")},
+			{desc: "cross_scheme_referer", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Referer": "https://example.com/form"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-origin")},
+			{desc: "untrusted_referer", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Referer": "https://evil.example.org/form"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-origin")},
+			{desc: "invalid_referer", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Referer": "/form"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-origin")},
+			{desc: "invalid_origin", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Origin": "::"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-origin")},
+			{desc: "null_origin", path: "/submit", headers: map[string]string{DefaultHeaderName: token, "Origin": "null"}, status: http.StatusForbidden, want: lang.Get("csrf.invalid-origin")},
+		}
+
+		for _, c := range cases {
+			t.Run(c.desc, func(t *testing.T) {
+				resp, body := client.do(t, http.MethodPost, c.path, c.headers, strings.NewReader(c.body))
+				assert.Equal(t, c.status, resp.StatusCode)
+				if c.want != "" {
+					assert.JSONEq(t, `{"error":"`+c.want+`"}`, body)
+				}
+			})
+		}
+
+		t.Run("no_cookie", func(t *testing.T) {
+			delete(client.cookies, "goyave_csrf")
+			resp, body := client.do(t, http.MethodPost, "/submit", map[string]string{DefaultHeaderName: token}, nil)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			assert.JSONEq(t, `{"error":"`+lang.Get("csrf.invalid-token")+`"}`, body)
+			assert.NotEqual(t, cookie.Value, client.cookies["goyave_csrf"].Value)
+		})
+
+		t.Run("forbidden_without_csrf_error", func(t *testing.T) {
+			resp, body := client.do(t, http.MethodDelete, "/forbidden", nil, nil)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			assert.JSONEq(t, `{"error":"Forbidden"}`, body)
+		})
+
+		t.Run("forbidden_status_handler", func(t *testing.T) {
+			client.router.StatusHandler(&goyave.ForbiddenStatusHandler{}, http.StatusForbidden)
+			resp, body := client.do(t, http.MethodPost, "/submit", nil, nil)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			assert.JSONEq(t, `{"error":"`+lang.Get("csrf.missing-token")+`"}`, body)
+		})
+	})
+
+	t.Run("custom_names", func(t *testing.T) {
+		client := prepareCSRFTest(t, &parse.Middleware{}, &Middleware{HeaderName: "X-Custom", FieldName: "token"})
+		_, token := client.do(t, http.MethodGet, "/form", nil, nil)
+
+		resp, _ := client.do(t, http.MethodPost, "/submit", map[string]string{"X-Custom": token}, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = client.do(t, http.MethodPost, "/submit", map[string]string{"Content-Type": "application/json"}, strings.NewReader(`{"token":"`+token+`"}`))
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = client.do(t, http.MethodPost, "/submit", map[string]string{DefaultHeaderName: token}, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("cookie_config", func(t *testing.T) {
+		client := prepareCSRFTest(t, &Middleware{})
+		cfg := client.server.Config()
+		cfg.Set("csrf.cookie.name", "custom_csrf")
+		cfg.Set("csrf.cookie.path", "/app")
+		cfg.Set("csrf.cookie.domain", "example.org")
+		cfg.Set("csrf.cookie.secure", false)
+
+		for sameSite, want := range map[string]http.SameSite{"Strict": http.SameSiteStrictMode, "Lax": http.SameSiteLaxMode, "None": http.SameSiteNoneMode} {
+			cfg.Set("csrf.cookie.sameSite", sameSite)
+			client.cookies = map[string]*http.Cookie{}
+			_, _ = client.do(t, http.MethodGet, "/form", nil, nil)
+			cookie := client.cookies["custom_csrf"]
+			require.NotNil(t, cookie)
+			assert.Equal(t, "/app", cookie.Path)
+			assert.Equal(t, "example.org", cookie.Domain)
+			assert.False(t, cookie.Secure)
+			assert.Equal(t, want, cookie.SameSite)
+		}
+	})
+
+	t.Run("synchronizer", func(t *testing.T) {
+		client := prepareCSRFTest(t, &session.Middleware{Store: session.NewMemoryStore()}, &Middleware{Mode: Synchronizer})
+
+		_, token := client.do(t, http.MethodGet, "/form", nil, nil)
+		assert.NotContains(t, client.cookies, "goyave_csrf")
+		require.Contains(t, client.cookies, "goyave_session")
+
+		resp, _ := client.do(t, http.MethodPost, "/submit", map[string]string{DefaultHeaderName: token}, nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// The token is kept for the whole session
+		_, token2 := client.do(t, http.MethodGet, "/form", nil, nil)
+		assert.Equal(t, unmask(token), unmask(token2))
+
+		resp, _ = client.do(t, http.MethodPost, "/submit", map[string]string{DefaultHeaderName: mask(generateToken())}, nil)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("synchronizer_without_session", func(t *testing.T) {
+		client := prepareCSRFTest(t, &Middleware{Mode: Synchronizer})
+		resp, _ := client.do(t, http.MethodGet, "/form", nil, nil)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestMask(t *testing.T) {
+	token := generateToken()
+	assert.Len(t, token, tokenLength)
+
+	masked := mask(token)
+	assert.NotEqual(t, masked, mask(token))
+	assert.Equal(t, token, unmask(masked))
+	assert.Equal(t, token, unmask(base64.RawURLEncoding.EncodeToString(token)))
+	assert.Nil(t, unmask("!!!"))
+	assert.Nil(t, unmask(base64.RawURLEncoding.EncodeToString([]byte("short"))))
+}
+
+func TestToken(t *testing.T) {
+	request := testutil.NewTestRequest(http.MethodGet, "/", nil)
+	assert.Empty(t, Token(request))
+	request.Extra[ExtraToken{}] = "token"
+	assert.Equal(t, "token", Token(request))
+}
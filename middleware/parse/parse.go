@@ -3,6 +3,7 @@ package parse
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,9 +11,21 @@ import (
 	"strings"
 
 	"goyave.dev/goyave/v5"
+	errorutil "goyave.dev/goyave/v5/util/errors"
 	"goyave.dev/goyave/v5/util/fsutil"
 )
 
+// Route meta keys used by the parse middleware.
+const (
+	// MetaMaxUploadSize overrides the maximum size of the request (in MiB) for a route or router.
+	// The value must be a `float64` or an `int`.
+	MetaMaxUploadSize = "goyave.parse.maxUploadSize"
+
+	// MetaStream enables the streaming mode for the multipart requests of a route or router.
+	// The value must be a `PartHandler`. Give `nil` to disable the streaming mode.
+	MetaStream = "goyave.parse.stream"
+)
+
 // Middleware reading the raw request query and body.
 //
 // First, the query is parsed using Go's standard `url.ParseQuery()`. After being flattened
@@ -35,6 +48,19 @@ import (
 // In `multipart/form-data`, all file parts are automatically converted to `[]fsutil.File`.
 // Inside `request.Data`, a field of type "file" will therefore always be of type `[]fsutil.File`.
 // It is a slice so it support multi-file uploads in a single field.
+//
+// The max upload size can be overridden for a route or router using the `MetaMaxUploadSize` meta.
+//
+// If the route has a `PartHandler` in its `MetaStream` meta, `multipart/form-data` requests are
+// not buffered: the body is read part by part. The values are put in the request's `Data` like
+// in the regular mode, but the file parts are passed to the `PartHandler` as they are received.
+// Inside `request.Data`, a file field will always be of type `[]any`, containing the values
+// returned by the `PartHandler`. Use `FSPartHandler` to write the files directly to a file system.
+// The non-file parts are read in memory and cannot exceed 10 MiB in total.
+// If the body exceeds the max upload size or if the non-file parts are too large,
+// "413 Request Entity Too Large" is returned. If the `PartHandler` returns an error,
+// "500 Internal Server Error" is returned. If the `PartHandler` implements `PartCleaner`,
+// the parts already handled are cleaned up when the request fails.
 type Middleware struct {
 	goyave.Component
 
@@ -67,8 +93,15 @@ func (m *Middleware) Handle(next goyave.Handler) goyave.Handler {
 
 		r.Data = nil
 		contentType := r.Header().Get("Content-Type")
+		maxSize := int64(m.getMaxUploadSize(r) * 1024 * 1024)
+		if handler, ok := r.Route.LookupMeta(MetaStream); ok && strings.HasPrefix(contentType, "multipart/form-data") {
+			if partHandler, ok := handler.(PartHandler); ok {
+				m.stream(next, response, r, partHandler, maxSize)
+				return
+			}
+		}
+
 		if contentType != "" {
-			maxSize := int64(m.getMaxUploadSize() * 1024 * 1024)
 			maxValueBytes := maxSize
 			var bodyBuf bytes.Buffer
 			n, err := io.CopyN(&bodyBuf, r.Body(), maxValueBytes+1)
@@ -108,7 +141,42 @@ func (m *Middleware) Handle(next goyave.Handler) goyave.Handler {
 	}
 }
 
-func (m *Middleware) getMaxUploadSize() float64 {
+func (m *Middleware) stream(next goyave.Handler, response *goyave.Response, r *goyave.Request, handler PartHandler, maxSize int64) {
+	data, handled, err := streamMultipart(r, handler, maxSize)
+	if err != nil && len(handled) > 0 {
+		if cleaner, ok := handler.(PartCleaner); ok {
+			if cleanupErr := cleaner.CleanupParts(r, handled); cleanupErr != nil {
+				m.Logger().Error(errorutil.New(cleanupErr))
+			}
+		}
+	}
+	var maxBytesErr *http.MaxBytesError
+	var handlerErr *partHandlerError
+	switch {
+	case errors.As(err, &maxBytesErr), errors.Is(err, errValuesTooLarge):
+		response.Status(http.StatusRequestEntityTooLarge)
+		return
+	case errors.As(err, &handlerErr):
+		response.Error(handlerErr.err)
+		return
+	case err != nil:
+		response.Status(http.StatusBadRequest)
+		r.Extra[goyave.ExtraParseError{}] = fmt.Errorf("%w: %w", goyave.ErrInvalidContentForType, err)
+		return
+	}
+	r.Data = data
+	next(response, r)
+}
+
+func (m *Middleware) getMaxUploadSize(request *goyave.Request) float64 {
+	if size, ok := request.Route.LookupMeta(MetaMaxUploadSize); ok {
+		switch size := size.(type) {
+		case float64:
+			return size
+		case int:
+			return float64(size)
+		}
+	}
 	if m.MaxUploadSize == 0 {
 		return m.Config().GetFloat("server.maxUploadSize")
 	}
@@ -22,17 +22,28 @@ func TestParseMiddleware(t *testing.T) {
 	route := server.Router().Post("/parse", nil)
 
 	t.Run("Max Upload Size", func(t *testing.T) {
+		request := testutil.NewTestRequest(http.MethodPost, "/parse", nil)
+		request.Route = route
 		m := &Middleware{}
 		m.Init(server.Server)
-		assert.InEpsilon(t, 10.0, m.getMaxUploadSize(), 0) // Default
+		assert.InEpsilon(t, 10.0, m.getMaxUploadSize(request), 0) // Default
 		m.MaxUploadSize = 2.3
-		assert.InEpsilon(t, 2.3, m.getMaxUploadSize(), 0)
+		assert.InEpsilon(t, 2.3, m.getMaxUploadSize(request), 0)
 
 		m = &Middleware{
 			MaxUploadSize: 2.3,
 		}
 		m.Init(server.Server)
-		assert.InEpsilon(t, 2.3, m.getMaxUploadSize(), 0)
+		assert.InEpsilon(t, 2.3, m.getMaxUploadSize(request), 0)
+
+		router := server.Router().Subrouter("/meta")
+		router.Meta[MetaMaxUploadSize] = 50.5
+		request.Route = router.Post("/float", nil)
+		assert.InEpsilon(t, 50.5, m.getMaxUploadSize(request), 0)
+		request.Route = router.Post("/int", nil).SetMeta(MetaMaxUploadSize, 100)
+		assert.InEpsilon(t, 100.0, m.getMaxUploadSize(request), 0)
+		request.Route = router.Post("/invalid", nil).SetMeta(MetaMaxUploadSize, "invalid")
+		assert.InEpsilon(t, 2.3, m.getMaxUploadSize(request), 0)
 	})
 
 	t.Run("Parse Query", func(t *testing.T) {
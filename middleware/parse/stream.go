@@ -0,0 +1,209 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/errors"
+	"goyave.dev/goyave/v5/util/fsutil"
+)
+
+// PartHandler processes the file parts of multipart requests in streaming mode.
+// Set it as the `MetaStream` meta of a route or router to enable streaming.
+//
+// `HandlePart` is called for each file part, in the order they are received. The part
+// is a reader on the request body: it is not buffered in memory or on disk. The part
+// must be consumed before returning, it cannot be read anymore afterwards.
+// The returned value is added to the request's `Data`, under the form name of the part.
+// If an error is returned, the request is aborted and the error is reported
+// using `Response.Error()`.
+type PartHandler interface {
+	HandlePart(request *goyave.Request, part *multipart.Part) (any, error)
+}
+
+// PartCleaner can be implemented by a `PartHandler` to undo the side effects of the parts
+// it already handled when the request fails afterwards (for example if the body exceeds the
+// max upload size or if a subsequent part cannot be handled).
+//
+// `CleanupParts` receives the values returned by `HandlePart` for each successfully handled part.
+// If an error is returned, it is logged.
+type PartCleaner interface {
+	CleanupParts(request *goyave.Request, values []any) error
+}
+
+// StoredFile a file part written to a file system by `FSPartHandler`.
+type StoredFile struct {
+	// Path the path of the file in the file system.
+	Path string
+	// Name the original name of the file, as sent by the client.
+	Name string
+	// MIMEType the detected MIME type of the file.
+	MIMEType string
+	// Size the size of the file in bytes.
+	Size int64
+}
+
+// FSPartHandler a `PartHandler` writing the file parts directly to a file system.
+// Directories are created if needed. A timestamp is appended to the file names
+// to avoid duplicate file names (see `fsutil.SaveReader()`).
+//
+// The value added to the request's `Data` for each file part is a `StoredFile`.
+// If the file system implements `fsutil.RemoveFS`, the files already written are removed
+// if the request fails before reaching the handler. Otherwise, they are left in the file system.
+type FSPartHandler struct {
+	FS fsutil.WritableFS
+
+	// Dir the directory in which the files are written.
+	Dir string
+}
+
+// HandlePart writes the given part to the file system.
+func (h *FSPartHandler) HandlePart(_ *goyave.Request, part *multipart.Part) (any, error) {
+	reader := bufio.NewReaderSize(part, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, errors.New(err)
+	}
+	mimeType := "application/octet-stream"
+	if len(head) != 0 {
+		mimeType, err = fsutil.DetectContentType(bytes.NewReader(head), part.FileName())
+		if err != nil {
+			return nil, errors.New(err)
+		}
+	}
+
+	counter := &countingReader{reader: reader}
+	name, err := fsutil.SaveReader(h.FS, h.Dir, part.FileName(), counter)
+	if err != nil {
+		if removeFS, ok := h.FS.(fsutil.RemoveFS); ok && name != "" {
+			// Don't leave a partially written file
+			_ = removeFS.Remove(path.Join(h.Dir, name))
+		}
+		return nil, err
+	}
+	return StoredFile{
+		Path:     path.Join(h.Dir, name),
+		Name:     part.FileName(),
+		MIMEType: mimeType,
+		Size:     counter.n,
+	}, nil
+}
+
+// CleanupParts removes the given stored files if the file system implements `fsutil.RemoveFS`.
+func (h *FSPartHandler) CleanupParts(_ *goyave.Request, values []any) error {
+	removeFS, ok := h.FS.(fsutil.RemoveFS)
+	if !ok {
+		return nil
+	}
+	errs := []error{}
+	for _, v := range values {
+		if file, ok := v.(StoredFile); ok {
+			if err := removeFS.Remove(file.Path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(errs)
+	}
+	return nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// partHandlerError an error returned by a `PartHandler`.
+type partHandlerError struct {
+	err error
+}
+
+func (e *partHandlerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *partHandlerError) Unwrap() error {
+	return e.err
+}
+
+// maxStreamValuesSize the maximum total size of the non-file parts of a multipart request
+// in streaming mode. Non-file parts are read in memory. Same limit as the standard library.
+const maxStreamValuesSize = 10 << 20
+
+// errValuesTooLarge returned by `streamMultipart` if the non-file parts exceed `maxStreamValuesSize`.
+var errValuesTooLarge = fmt.Errorf("multipart values too large")
+
+// streamMultipart reads the multipart body of the request part by part. The values are
+// flattened and the file parts are passed to the given handler. Reading more than
+// `maxSize` bytes from the body results in a `*http.MaxBytesError`. If the non-file
+// parts exceed `maxStreamValuesSize`, returns `errValuesTooLarge`.
+//
+// The values returned by the handler for the file parts handled are returned, even if
+// an error occurred, so they can be cleaned up.
+func streamMultipart(request *goyave.Request, handler PartHandler, maxSize int64) (map[string]any, []any, error) {
+	req := request.Request()
+	req.Body = http.MaxBytesReader(nil, req.Body, maxSize)
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := url.Values{}
+	files := map[string][]any{}
+	handled := []any{}
+	remainingValuesSize := int64(maxStreamValuesSize)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, handled, err
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, remainingValuesSize+1))
+			if err != nil {
+				return nil, handled, err
+			}
+			remainingValuesSize -= int64(len(value))
+			if remainingValuesSize < 0 {
+				return nil, handled, errValuesTooLarge
+			}
+			values.Add(name, string(value))
+			continue
+		}
+
+		file, err := handler.HandlePart(request, part)
+		if err != nil {
+			return nil, handled, &partHandlerError{err: err}
+		}
+		handled = append(handled, file)
+		files[name] = append(files[name], file)
+	}
+
+	flatMap := make(map[string]any, len(values)+len(files))
+	flatten(flatMap, values)
+	for name, f := range files {
+		flatMap[name] = f
+	}
+	return flatMap, handled, nil
+}
@@ -0,0 +1,212 @@
+package parse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/fsutil"
+	"goyave.dev/goyave/v5/util/fsutil/osfs"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+type testPartHandler struct {
+	err error
+}
+
+func (h *testPartHandler) HandlePart(_ *goyave.Request, part *multipart.Part) (any, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	content, err := io.ReadAll(part)
+	return part.FileName() + ":" + string(content), err
+}
+
+func prepareStreamRequest(t *testing.T, server *testutil.TestServer, route *goyave.Route, files map[string]string) *goyave.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("email", "johndoe@example.org"))
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	request := testutil.NewTestRequest(http.MethodPost, "/stream", body)
+	request.Header().Set("Content-Type", writer.FormDataContentType())
+	request.Lang = server.Lang.GetDefault()
+	request.Route = route
+	return request
+}
+
+func TestStream(t *testing.T) {
+	server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+
+	t.Run("PartHandler", func(t *testing.T) {
+		route := server.Router().Post("/stream", nil).SetMeta(MetaStream, &testPartHandler{})
+		request := prepareStreamRequest(t, server, route, map[string]string{"file.txt": "content"})
+
+		result := server.TestMiddleware(&Middleware{}, request, func(resp *goyave.Response, req *goyave.Request) {
+			expected := map[string]any{
+				"email": "johndoe@example.org",
+				"files": []any{"file.txt:content"},
+			}
+			assert.Equal(t, expected, req.Data)
+			resp.Status(http.StatusOK)
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+
+	t.Run("FSPartHandler", func(t *testing.T) {
+		dir := t.TempDir()
+		route := server.Router().Post("/stream", nil).SetMeta(MetaStream, &FSPartHandler{FS: &osfs.FS{}, Dir: path.Join(dir, "uploads")})
+		request := prepareStreamRequest(t, server, route, map[string]string{"file.txt": "content"})
+
+		result := server.TestMiddleware(&Middleware{}, request, func(resp *goyave.Response, req *goyave.Request) {
+			data, ok := req.Data.(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, "johndoe@example.org", data["email"])
+
+			files, ok := data["files"].([]any)
+			require.True(t, ok)
+			require.Len(t, files, 1)
+			file, ok := files[0].(StoredFile)
+			require.True(t, ok)
+			assert.Equal(t, "file.txt", file.Name)
+			assert.Equal(t, "text/plain", file.MIMEType)
+			assert.Equal(t, int64(7), file.Size)
+			assert.True(t, strings.HasPrefix(file.Path, path.Join(dir, "uploads", "file-")))
+
+			content, err := os.ReadFile(file.Path)
+			require.NoError(t, err)
+			assert.Equal(t, "content", string(content))
+			resp.Status(http.StatusOK)
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+
+	t.Run("Entity Too Large", func(t *testing.T) {
+		route := server.Router().Post("/stream", nil).
+			SetMeta(MetaStream, &testPartHandler{}).
+			SetMeta(MetaMaxUploadSize, 0.001)
+		request := prepareStreamRequest(t, server, route, map[string]string{"file.txt": strings.Repeat("a", 2048)})
+
+		result := server.TestMiddleware(&Middleware{}, request, func(_ *goyave.Response, _ *goyave.Request) {
+			assert.Fail(t, "Middleware should not pass")
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusRequestEntityTooLarge, result.StatusCode)
+	})
+
+	t.Run("Values Too Large", func(t *testing.T) {
+		route := server.Router().Post("/stream", nil).
+			SetMeta(MetaStream, &testPartHandler{}).
+			SetMeta(MetaMaxUploadSize, 20)
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		require.NoError(t, writer.WriteField("small", "value"))
+		require.NoError(t, writer.WriteField("large", strings.Repeat("a", maxStreamValuesSize)))
+		require.NoError(t, writer.Close())
+		request := testutil.NewTestRequest(http.MethodPost, "/stream", body)
+		request.Header().Set("Content-Type", writer.FormDataContentType())
+		request.Lang = server.Lang.GetDefault()
+		request.Route = route
+
+		result := server.TestMiddleware(&Middleware{}, request, func(_ *goyave.Response, _ *goyave.Request) {
+			assert.Fail(t, "Middleware should not pass")
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusRequestEntityTooLarge, result.StatusCode)
+	})
+
+	t.Run("FSPartHandler Cleanup", func(t *testing.T) {
+		dir := t.TempDir()
+		route := server.Router().Post("/stream", nil).
+			SetMeta(MetaStream, &FSPartHandler{FS: &osfs.FS{}, Dir: dir}).
+			SetMeta(MetaMaxUploadSize, 0.001)
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, content := range []string{"content", strings.Repeat("a", 2048)} {
+			part, err := writer.CreateFormFile("files", "file.txt")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+		request := testutil.NewTestRequest(http.MethodPost, "/stream", body)
+		request.Header().Set("Content-Type", writer.FormDataContentType())
+		request.Lang = server.Lang.GetDefault()
+		request.Route = route
+
+		result := server.TestMiddleware(&Middleware{}, request, func(_ *goyave.Response, _ *goyave.Request) {
+			assert.Fail(t, "Middleware should not pass")
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusRequestEntityTooLarge, result.StatusCode)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("PartHandler Error", func(t *testing.T) {
+		route := server.Router().Post("/stream", nil).SetMeta(MetaStream, &testPartHandler{err: fmt.Errorf("test error")})
+		request := prepareStreamRequest(t, server, route, map[string]string{"file.txt": "content"})
+
+		result := server.TestMiddleware(&Middleware{}, request, func(_ *goyave.Response, _ *goyave.Request) {
+			assert.Fail(t, "Middleware should not pass")
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	})
+
+	t.Run("Invalid Multipart", func(t *testing.T) {
+		route := server.Router().Post("/stream", nil).SetMeta(MetaStream, &testPartHandler{})
+		request := testutil.NewTestRequest(http.MethodPost, "/stream", strings.NewReader("invalid"))
+		request.Lang = server.Lang.GetDefault()
+		request.Header().Set("Content-Type", "multipart/form-data; boundary=boundary")
+		request.Route = route
+
+		result := server.TestMiddleware(&Middleware{}, request, func(_ *goyave.Response, _ *goyave.Request) {
+			assert.Fail(t, "Middleware should not pass")
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusBadRequest, result.StatusCode)
+		extraError, ok := request.Extra[goyave.ExtraParseError{}].(error)
+		require.True(t, ok)
+		assert.ErrorIs(t, extraError, goyave.ErrInvalidContentForType)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		router := server.Router().Subrouter("/disabled")
+		router.Meta[MetaStream] = &testPartHandler{}
+		route := router.Post("/stream", nil).SetMeta(MetaStream, nil)
+		request := prepareStreamRequest(t, server, route, map[string]string{"file.txt": "content"})
+
+		result := server.TestMiddleware(&Middleware{}, request, func(resp *goyave.Response, req *goyave.Request) {
+			data, ok := req.Data.(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, "johndoe@example.org", data["email"])
+			assert.IsType(t, []fsutil.File{}, data["files"])
+			resp.Status(http.StatusOK)
+		})
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+	})
+}
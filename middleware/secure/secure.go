@@ -0,0 +1,271 @@
+package secure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// MetaOptions the route meta key used to override the security headers options
+// for a router or a route. The value must be of type `*Options`. If the value
+// is `nil`, no security header is added for this router, its subrouters and routes.
+// The headers can be re-enabled for subrouters and routes on a case-by-case basis using
+// non-nil options.
+//
+// Prefer using `Apply` and `ApplyRoute`, which also add the middleware automatically.
+//
+//	secure.Apply(router, options)
+const MetaOptions = "goyave.secure"
+
+// NoncePlaceholder the placeholder replaced by the per-request nonce in the
+// `ContentSecurityPolicy`.
+//
+//	"script-src 'self' 'nonce-{nonce}'"
+const NoncePlaceholder = "{nonce}"
+
+// ExtraNonce the key used to store the Content-Security-Policy nonce of the current
+// request in the request's `Extra`. Use `Nonce` to retrieve it.
+type ExtraNonce struct{}
+
+func init() {
+	registerEntry("secure.hsts.maxAge", 31536000, reflect.Int)
+	registerEntry("secure.hsts.includeSubdomains", true, reflect.Bool)
+	registerEntry("secure.hsts.preload", false, reflect.Bool)
+	registerEntry("secure.contentSecurityPolicy", "default-src 'self'; base-uri 'self'; frame-ancestors 'none'; object-src 'none'", reflect.String)
+	registerEntry("secure.contentSecurityPolicyReportOnly", false, reflect.Bool)
+	registerEntry("secure.contentTypeNosniff", true, reflect.Bool)
+	config.Register("secure.frameOptions", config.Entry{
+		Value:            "DENY",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{"DENY", "SAMEORIGIN", ""},
+	})
+	registerEntry("secure.referrerPolicy", "strict-origin-when-cross-origin", reflect.String)
+	registerEntry("secure.permissionsPolicy", "", reflect.String)
+	registerEntry("secure.crossOriginOpenerPolicy", "same-origin", reflect.String)
+	registerEntry("secure.crossOriginEmbedderPolicy", "", reflect.String)
+	registerEntry("secure.crossOriginResourcePolicy", "same-origin", reflect.String)
+}
+
+func registerEntry(name string, value any, t reflect.Kind) {
+	config.Register(name, config.Entry{
+		Value:            value,
+		Type:             t,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+}
+
+// Options the security headers added to the responses. Empty values
+// disable the corresponding header.
+type Options struct {
+	// ContentSecurityPolicy the value of the `Content-Security-Policy` header. The occurrences
+	// of `NoncePlaceholder` are replaced with a random nonce generated for each request.
+	ContentSecurityPolicy string
+
+	// FrameOptions the value of the `X-Frame-Options` header ("DENY" or "SAMEORIGIN").
+	FrameOptions string
+
+	// ReferrerPolicy the value of the `Referrer-Policy` header.
+	ReferrerPolicy string
+
+	// PermissionsPolicy the value of the `Permissions-Policy` header.
+	PermissionsPolicy string
+
+	// CrossOriginOpenerPolicy the value of the `Cross-Origin-Opener-Policy` header.
+	CrossOriginOpenerPolicy string
+
+	// CrossOriginEmbedderPolicy the value of the `Cross-Origin-Embedder-Policy` header.
+	CrossOriginEmbedderPolicy string
+
+	// CrossOriginResourcePolicy the value of the `Cross-Origin-Resource-Policy` header.
+	CrossOriginResourcePolicy string
+
+	// HSTSMaxAge the duration during which the browser should only access the
+	// application using HTTPS (`Strict-Transport-Security` header).
+	// The header is not added if the duration is zero, or if the request was not
+	// sent over HTTPS (see `Request.Scheme()`).
+	HSTSMaxAge time.Duration
+
+	// HSTSIncludeSubdomains adds the `includeSubDomains` directive to the HSTS header.
+	HSTSIncludeSubdomains bool
+
+	// HSTSPreload adds the `preload` directive to the HSTS header.
+	HSTSPreload bool
+
+	// ContentSecurityPolicyReportOnly if true, the policy is sent in the
+	// `Content-Security-Policy-Report-Only` header instead so it is not enforced.
+	ContentSecurityPolicyReportOnly bool
+
+	// ContentTypeNosniff adds the `X-Content-Type-Options: nosniff` header.
+	ContentTypeNosniff bool
+}
+
+// FromConfig create new options using the values of the `secure.*` config entries.
+// The returned value can be used as a starting point for customized options.
+func FromConfig(cfg *config.Config) *Options {
+	return &Options{
+		HSTSMaxAge:                      time.Duration(cfg.GetInt("secure.hsts.maxAge")) * time.Second,
+		HSTSIncludeSubdomains:           cfg.GetBool("secure.hsts.includeSubdomains"),
+		HSTSPreload:                     cfg.GetBool("secure.hsts.preload"),
+		ContentSecurityPolicy:           cfg.GetString("secure.contentSecurityPolicy"),
+		ContentSecurityPolicyReportOnly: cfg.GetBool("secure.contentSecurityPolicyReportOnly"),
+		ContentTypeNosniff:              cfg.GetBool("secure.contentTypeNosniff"),
+		FrameOptions:                    cfg.GetString("secure.frameOptions"),
+		ReferrerPolicy:                  cfg.GetString("secure.referrerPolicy"),
+		PermissionsPolicy:               cfg.GetString("secure.permissionsPolicy"),
+		CrossOriginOpenerPolicy:         cfg.GetString("secure.crossOriginOpenerPolicy"),
+		CrossOriginEmbedderPolicy:       cfg.GetString("secure.crossOriginEmbedderPolicy"),
+		CrossOriginResourcePolicy:       cfg.GetString("secure.crossOriginResourcePolicy"),
+	}
+}
+
+// Clone returns a copy of the options.
+func (o *Options) Clone() *Options {
+	clone := *o
+	return &clone
+}
+
+// Middleware adding standard security headers to the responses.
+//
+// By default, the headers are defined by the `secure.*` config entries. The options
+// can be overridden per router or route using the `MetaOptions` meta.
+//
+// The headers are set before the next handlers are executed, so they can
+// still be altered or removed by the handlers.
+//
+// If the Content-Security-Policy contains `NoncePlaceholder`, a nonce is generated for each
+// request and made available with `Nonce` so it can be used in the `nonce` attribute of
+// inline scripts and styles.
+//
+//	router.GlobalMiddleware(&secure.Middleware{})
+//	secure.Apply(router.Subrouter("/embed"), embedOptions)
+type Middleware struct {
+	goyave.Component
+	defaultOptions *Options
+}
+
+// Init the middleware and load the default options from the config.
+func (m *Middleware) Init(server *goyave.Server) {
+	m.Component.Init(server)
+	m.defaultOptions = FromConfig(server.Config())
+}
+
+// Handle implementation of `goyave.Middleware`.
+func (m *Middleware) Handle(next goyave.Handler) goyave.Handler {
+	return func(response *goyave.Response, request *goyave.Request) {
+		options := m.defaultOptions
+		if o, ok := request.Route.LookupMeta(MetaOptions); ok {
+			options, _ = o.(*Options)
+		}
+		if options != nil {
+			options.apply(response, request)
+		}
+		next(response, request)
+	}
+}
+
+// Apply set the security headers options for the given route group, like `Router.CORS()`.
+// If the options are not `nil`, the middleware is automatically added globally. It then
+// adds the headers defined by the config to the routes that don't have options.
+// To disable the security headers for this router, subrouters and routes, give `nil` options.
+// The headers can be re-enabled for subrouters and routes on a case-by-case basis
+// using non-nil options.
+func Apply(router *goyave.Router, options *Options) *goyave.Router {
+	router.SetMeta(MetaOptions, options)
+	if options != nil {
+		addMiddleware(router)
+	}
+	return router
+}
+
+// ApplyRoute set the security headers options for the given route only, like `Route.CORS()`.
+// If the options are not `nil`, the middleware is automatically added globally.
+// To disable the security headers for this route, give `nil` options.
+//
+// See `Apply()` for more details.
+func ApplyRoute(route *goyave.Route, options *Options) *goyave.Route {
+	route.SetMeta(MetaOptions, options)
+	if options != nil {
+		addMiddleware(route.GetParent())
+	}
+	return route
+}
+
+func addMiddleware(router *goyave.Router) {
+	for _, m := range router.GetGlobalMiddleware() {
+		if _, ok := m.(*Middleware); ok {
+			return
+		}
+	}
+	router.GlobalMiddleware(&Middleware{})
+}
+
+func (o *Options) apply(response *goyave.Response, request *goyave.Request) {
+	headers := response.Header()
+
+	// HSTS must only be sent over secure transport (RFC 6797 §7.2)
+	if o.HSTSMaxAge > 0 && request.Scheme() == "https" {
+		value := "max-age=" + strconv.FormatInt(int64(o.HSTSMaxAge/time.Second), 10)
+		if o.HSTSIncludeSubdomains {
+			value += "; includeSubDomains"
+		}
+		if o.HSTSPreload {
+			value += "; preload"
+		}
+		headers.Set("Strict-Transport-Security", value)
+	}
+
+	if o.ContentSecurityPolicy != "" {
+		policy := o.ContentSecurityPolicy
+		if strings.Contains(policy, NoncePlaceholder) {
+			nonce := generateNonce()
+			request.Extra[ExtraNonce{}] = nonce
+			policy = strings.ReplaceAll(policy, NoncePlaceholder, nonce)
+		}
+		header := "Content-Security-Policy"
+		if o.ContentSecurityPolicyReportOnly {
+			header = "Content-Security-Policy-Report-Only"
+		}
+		headers.Set(header, policy)
+	}
+
+	if o.ContentTypeNosniff {
+		headers.Set("X-Content-Type-Options", "nosniff")
+	}
+	setIfNotEmpty(headers, "X-Frame-Options", o.FrameOptions)
+	setIfNotEmpty(headers, "Referrer-Policy", o.ReferrerPolicy)
+	setIfNotEmpty(headers, "Permissions-Policy", o.PermissionsPolicy)
+	setIfNotEmpty(headers, "Cross-Origin-Opener-Policy", o.CrossOriginOpenerPolicy)
+	setIfNotEmpty(headers, "Cross-Origin-Embedder-Policy", o.CrossOriginEmbedderPolicy)
+	setIfNotEmpty(headers, "Cross-Origin-Resource-Policy", o.CrossOriginResourcePolicy)
+}
+
+func setIfNotEmpty(headers http.Header, key, value string) {
+	if value != "" {
+		headers.Set(key, value)
+	}
+}
+
+// Nonce returns the Content-Security-Policy nonce of the given request, or an empty
+// string if the policy applied to this request doesn't contain `NoncePlaceholder`.
+func Nonce(request *goyave.Request) string {
+	nonce, _ := request.Extra[ExtraNonce{}].(string)
+	return nonce
+}
+
+func generateNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(errors.New(err))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
@@ -0,0 +1,243 @@
+package secure
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+func TestFromConfig(t *testing.T) {
+	cfg := config.LoadDefault()
+	expected := &Options{
+		HSTSMaxAge:                365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:     true,
+		ContentSecurityPolicy:     "default-src 'self'; base-uri 'self'; frame-ancestors 'none'; object-src 'none'",
+		ContentTypeNosniff:        true,
+		FrameOptions:              "DENY",
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginResourcePolicy: "same-origin",
+	}
+	options := FromConfig(cfg)
+	assert.Equal(t, expected, options)
+
+	clone := options.Clone()
+	assert.Equal(t, options, clone)
+	assert.NotSame(t, options, clone)
+}
+
+func TestMiddleware(t *testing.T) {
+	prepare := func(t *testing.T, cfg *config.Config) (*goyave.Router, *goyave.Router) {
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+		router := goyave.NewRouter(server.Server)
+		router.GlobalMiddleware(&Middleware{})
+		handler := func(response *goyave.Response, request *goyave.Request) {
+			response.String(http.StatusOK, Nonce(request))
+		}
+		router.Get("/", handler)
+		router.Get("/disabled", handler).SetMeta(MetaOptions, nil)
+		subrouter := router.Subrouter("/sub")
+		subrouter.Get("/", handler)
+		return router, subrouter
+	}
+
+	request := func(router *goyave.Router, path string) (*http.Response, string) {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.TLS = &tls.ConnectionState{}
+		router.ServeHTTP(recorder, req)
+		return recorder.Result(), recorder.Body.String()
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		router, _ := prepare(t, config.LoadDefault())
+		resp, body := request(router, "/")
+		assert.NoError(t, resp.Body.Close())
+		assert.Empty(t, body)
+		assert.Equal(t, "max-age=31536000; includeSubDomains", resp.Header.Get("Strict-Transport-Security"))
+		assert.Equal(t, "default-src 'self'; base-uri 'self'; frame-ancestors 'none'; object-src 'none'", resp.Header.Get("Content-Security-Policy"))
+		assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+		assert.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"))
+		assert.Equal(t, "strict-origin-when-cross-origin", resp.Header.Get("Referrer-Policy"))
+		assert.Equal(t, "same-origin", resp.Header.Get("Cross-Origin-Opener-Policy"))
+		assert.Equal(t, "same-origin", resp.Header.Get("Cross-Origin-Resource-Policy"))
+		assert.NotContains(t, resp.Header, "Permissions-Policy")
+		assert.NotContains(t, resp.Header, "Cross-Origin-Embedder-Policy")
+	})
+
+	t.Run("hsts_https_only", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("server.trustedProxies", []string{"198.51.100.1"})
+		router, _ := prepare(t, cfg)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		resp := recorder.Result()
+		assert.NoError(t, resp.Body.Close())
+		assert.NotContains(t, resp.Header, "Strict-Transport-Security")
+		assert.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"))
+
+		// Behind a trusted TLS-terminating proxy
+		recorder = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "198.51.100.1:1234"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		router.ServeHTTP(recorder, req)
+		resp = recorder.Result()
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, "max-age=31536000; includeSubDomains", resp.Header.Get("Strict-Transport-Security"))
+
+		// Untrusted proxy
+		recorder = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		router.ServeHTTP(recorder, req)
+		resp = recorder.Result()
+		assert.NoError(t, resp.Body.Close())
+		assert.NotContains(t, resp.Header, "Strict-Transport-Security")
+	})
+
+	t.Run("config", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("secure.hsts.maxAge", 60)
+		cfg.Set("secure.hsts.includeSubdomains", false)
+		cfg.Set("secure.hsts.preload", true)
+		cfg.Set("secure.contentSecurityPolicy", "script-src 'nonce-{nonce}'; style-src 'nonce-{nonce}'")
+		cfg.Set("secure.contentSecurityPolicyReportOnly", true)
+		cfg.Set("secure.contentTypeNosniff", false)
+		cfg.Set("secure.frameOptions", "")
+		cfg.Set("secure.referrerPolicy", "no-referrer")
+		cfg.Set("secure.permissionsPolicy", "geolocation=()")
+		cfg.Set("secure.crossOriginOpenerPolicy", "")
+		cfg.Set("secure.crossOriginEmbedderPolicy", "require-corp")
+		cfg.Set("secure.crossOriginResourcePolicy", "")
+		router, _ := prepare(t, cfg)
+
+		resp, nonce := request(router, "/")
+		assert.NoError(t, resp.Body.Close())
+		require.NotEmpty(t, nonce)
+		assert.Equal(t, "max-age=60; preload", resp.Header.Get("Strict-Transport-Security"))
+		assert.NotContains(t, resp.Header, "Content-Security-Policy")
+		assert.Equal(t, "script-src 'nonce-"+nonce+"'; style-src 'nonce-"+nonce+"'", resp.Header.Get("Content-Security-Policy-Report-Only"))
+		assert.NotContains(t, resp.Header, "X-Content-Type-Options")
+		assert.NotContains(t, resp.Header, "X-Frame-Options")
+		assert.Equal(t, "no-referrer", resp.Header.Get("Referrer-Policy"))
+		assert.Equal(t, "geolocation=()", resp.Header.Get("Permissions-Policy"))
+		assert.NotContains(t, resp.Header, "Cross-Origin-Opener-Policy")
+		assert.Equal(t, "require-corp", resp.Header.Get("Cross-Origin-Embedder-Policy"))
+		assert.NotContains(t, resp.Header, "Cross-Origin-Resource-Policy")
+
+		// A new nonce is generated for each request
+		resp, nonce2 := request(router, "/")
+		assert.NoError(t, resp.Body.Close())
+		assert.NotEqual(t, nonce, nonce2)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		router, subrouter := prepare(t, config.LoadDefault())
+		resp, _ := request(router, "/disabled")
+		assert.NoError(t, resp.Body.Close())
+		assert.NotContains(t, resp.Header, "Strict-Transport-Security")
+		assert.NotContains(t, resp.Header, "Content-Security-Policy")
+
+		options := FromConfig(config.LoadDefault())
+		options.FrameOptions = "SAMEORIGIN"
+		options.HSTSMaxAge = 0
+		subrouter.SetMeta(MetaOptions, options)
+		resp, _ = request(router, "/sub")
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, "SAMEORIGIN", resp.Header.Get("X-Frame-Options"))
+		assert.NotContains(t, resp.Header, "Strict-Transport-Security")
+
+		// Disabled for the subrouter, re-enabled for a single route
+		subrouter.SetMeta(MetaOptions, nil)
+		subrouter.Get("/enabled", func(response *goyave.Response, _ *goyave.Request) {
+			response.Status(http.StatusNoContent)
+		}).SetMeta(MetaOptions, options)
+		resp, _ = request(router, "/sub")
+		assert.NoError(t, resp.Body.Close())
+		assert.NotContains(t, resp.Header, "X-Frame-Options")
+		resp, _ = request(router, "/sub/enabled")
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, "SAMEORIGIN", resp.Header.Get("X-Frame-Options"))
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+		router := goyave.NewRouter(server.Server)
+		handler := func(response *goyave.Response, _ *goyave.Request) {
+			response.Status(http.StatusNoContent)
+		}
+		router.Get("/", handler)
+
+		// Nil options don't add the middleware
+		countMiddleware := func() int {
+			return lo.CountBy(router.GetGlobalMiddleware(), func(m goyave.Middleware) bool {
+				_, ok := m.(*Middleware)
+				return ok
+			})
+		}
+		disabled := Apply(router.Subrouter("/disabled"), nil)
+		assert.Equal(t, 0, countMiddleware())
+		disabled.Get("/", handler)
+
+		options := FromConfig(config.LoadDefault())
+		options.FrameOptions = "SAMEORIGIN"
+		embed := Apply(router.Subrouter("/embed"), options)
+		embed.Get("/", handler)
+		embed.Subrouter("/nested").Get("/", handler)
+		ApplyRoute(embed.Get("/raw", handler), nil)
+		ApplyRoute(disabled.Get("/enabled", handler), options)
+		assert.Equal(t, 1, countMiddleware())
+
+		cases := []struct {
+			path         string
+			frameOptions string
+		}{
+			{path: "/", frameOptions: "DENY"},
+			{path: "/disabled", frameOptions: ""},
+			{path: "/disabled/enabled", frameOptions: "SAMEORIGIN"},
+			{path: "/embed", frameOptions: "SAMEORIGIN"},
+			{path: "/embed/nested", frameOptions: "SAMEORIGIN"},
+			{path: "/embed/raw", frameOptions: ""},
+		}
+		for _, c := range cases {
+			t.Run(c.path, func(t *testing.T) {
+				resp, _ := request(router, c.path)
+				assert.NoError(t, resp.Body.Close())
+				assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+				assert.Equal(t, c.frameOptions, resp.Header.Get("X-Frame-Options"))
+			})
+		}
+	})
+
+	t.Run("handler_can_override", func(t *testing.T) {
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+		resp := server.TestMiddleware(&Middleware{}, func() *goyave.Request {
+			r := server.NewTestRequest(http.MethodGet, "/", nil)
+			r.Route = &goyave.Route{Meta: map[string]any{}}
+			return r
+		}(), func(response *goyave.Response, _ *goyave.Request) {
+			response.Header().Set("X-Frame-Options", "SAMEORIGIN")
+			response.Status(http.StatusNoContent)
+		})
+		assert.NoError(t, resp.Body.Close())
+		assert.Equal(t, "SAMEORIGIN", resp.Header.Get("X-Frame-Options"))
+	})
+}
+
+func TestNonce(t *testing.T) {
+	request := testutil.NewTestRequest(http.MethodGet, "/", nil)
+	assert.Empty(t, Nonce(request))
+	request.Extra[ExtraNonce{}] = "nonce"
+	assert.Equal(t, "nonce", Nonce(request))
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// Model the database model used by the `GORMStore`. The table should be
+// created using a migration or `db.AutoMigrate(&session.Model{})`.
+type Model struct {
+	ExpiresAt time.Time `gorm:"index"`
+	ID        string    `gorm:"primaryKey;size:64"`
+	Data      []byte
+}
+
+// TableName returns "sessions".
+func (Model) TableName() string {
+	return "sessions"
+}
+
+// GORMStore a `Store` keeping the sessions in a database. The records
+// are stored in the table of `Model`.
+//
+// Expired sessions are never returned but are not deleted automatically.
+// Use `Purge` periodically to remove them.
+type GORMStore struct {
+	DB *gorm.DB
+}
+
+// NewGORMStore create a new `GORMStore` using the given database connection.
+func NewGORMStore(db *gorm.DB) *GORMStore {
+	return &GORMStore{DB: db}
+}
+
+// Load returns the session identified by the given ID, or `nil, nil` if
+// it doesn't exist or is expired.
+func (s *GORMStore) Load(ctx context.Context, value string) (*Record, error) {
+	model := &Model{}
+	db := s.DB.WithContext(ctx).Where("id = ? AND expires_at > ?", value, time.Now()).Limit(1).Find(model)
+	if db.Error != nil {
+		return nil, errors.New(db.Error)
+	}
+	if db.RowsAffected == 0 {
+		return nil, nil
+	}
+	record := &Record{}
+	if err := json.Unmarshal(model.Data, record); err != nil {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// Save inserts or updates the given record and returns its ID.
+func (s *GORMStore) Save(ctx context.Context, record *Record) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", errors.New(err)
+	}
+	model := &Model{
+		ID:        record.ID,
+		Data:      data,
+		ExpiresAt: record.ExpiresAt,
+	}
+	if err := s.DB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(model).Error; err != nil {
+		return "", errors.New(err)
+	}
+	return record.ID, nil
+}
+
+// Delete removes the session identified by the given ID.
+func (s *GORMStore) Delete(ctx context.Context, id string) error {
+	return errors.New(s.DB.WithContext(ctx).Where("id = ?", id).Delete(&Model{}).Error)
+}
+
+// Purge deletes all the expired sessions.
+func (s *GORMStore) Purge(ctx context.Context) error {
+	return errors.New(s.DB.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&Model{}).Error)
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/testutil"
+
+	_ "goyave.dev/goyave/v5/database/dialect/sqlite"
+)
+
+func prepareGORMStore(t *testing.T) *GORMStore {
+	cfg := config.LoadDefault()
+	cfg.Set("database.connection", "sqlite3")
+	cfg.Set("database.name", "testsessionstore.db")
+	cfg.Set("database.options", "mode=memory")
+	server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+	require.NoError(t, server.DB().AutoMigrate(&Model{}))
+	return NewGORMStore(server.DB())
+}
+
+func TestGORMStore(t *testing.T) {
+	t.Run("save_load_delete", func(t *testing.T) {
+		store := prepareGORMStore(t)
+		record := testRecord()
+		value, err := store.Save(context.Background(), record)
+		require.NoError(t, err)
+		assert.Equal(t, record.ID, value)
+
+		loaded, err := store.Load(context.Background(), value)
+		require.NoError(t, err)
+		assert.Equal(t, record, loaded)
+
+		// Update
+		record.Values["key"] = "updated"
+		_, err = store.Save(context.Background(), record)
+		require.NoError(t, err)
+		loaded, err = store.Load(context.Background(), value)
+		require.NoError(t, err)
+		assert.Equal(t, "updated", loaded.Values["key"])
+
+		loaded, err = store.Load(context.Background(), "unknown")
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+
+		require.NoError(t, store.Delete(context.Background(), record.ID))
+		loaded, err = store.Load(context.Background(), value)
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+	})
+
+	t.Run("expired_and_purge", func(t *testing.T) {
+		store := prepareGORMStore(t)
+		expired := testRecord()
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		_, err := store.Save(context.Background(), expired)
+		require.NoError(t, err)
+
+		record := testRecord()
+		record.ID = "other"
+		_, err = store.Save(context.Background(), record)
+		require.NoError(t, err)
+
+		loaded, err := store.Load(context.Background(), expired.ID)
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+
+		require.NoError(t, store.Purge(context.Background()))
+		var count int64
+		require.NoError(t, store.DB.Model(&Model{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("invalid_data", func(t *testing.T) {
+		store := prepareGORMStore(t)
+		require.NoError(t, store.DB.Create(&Model{ID: "id", Data: []byte("invalid"), ExpiresAt: time.Now().Add(time.Hour)}).Error)
+		loaded, err := store.Load(context.Background(), "id")
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		store := prepareGORMStore(t)
+		require.NoError(t, store.DB.Migrator().DropTable(&Model{}))
+
+		_, err := store.Load(context.Background(), "id")
+		require.Error(t, err)
+		_, err = store.Save(context.Background(), testRecord())
+		require.Error(t, err)
+		require.Error(t, store.Delete(context.Background(), "id"))
+		require.Error(t, store.Purge(context.Background()))
+
+		record := testRecord()
+		record.Values["func"] = func() {}
+		_, err = store.Save(context.Background(), record)
+		require.Error(t, err)
+	})
+}
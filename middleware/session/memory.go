@@ -0,0 +1,105 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// memoryPurgeInterval the minimum duration between two purges of the expired
+// sessions of a `MemoryStore`.
+const memoryPurgeInterval = time.Minute
+
+type memoryEntry struct {
+	expiresAt time.Time
+	data      []byte
+}
+
+// MemoryStore a `Store` keeping the sessions in memory. Expired sessions are purged
+// lazily when sessions are saved.
+//
+// The sessions are lost when the application restarts and are not shared between
+// instances. This store is therefore mostly suitable for development and tests.
+type MemoryStore struct {
+	lastPurge time.Time
+	sessions  map[string]memoryEntry
+	mu        sync.Mutex
+}
+
+// NewMemoryStore create a new empty `MemoryStore`.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions:  map[string]memoryEntry{},
+		lastPurge: time.Now(),
+	}
+}
+
+// Load returns the session identified by the given ID, or `nil, nil` if
+// it doesn't exist or is expired.
+func (s *MemoryStore) Load(_ context.Context, value string) (*Record, error) {
+	s.mu.Lock()
+	entry, ok := s.sessions[value]
+	if ok && !entry.expiresAt.After(time.Now()) {
+		delete(s.sessions, value)
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	// Records are kept encoded so they cannot be altered outside of
+	// the store and have the same types as with the other stores.
+	record := &Record{}
+	if err := json.Unmarshal(entry.data, record); err != nil {
+		return nil, errors.New(err)
+	}
+	return record, nil
+}
+
+// Save stores the given record and returns its ID.
+func (s *MemoryStore) Save(_ context.Context, record *Record) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", errors.New(err)
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastPurge) >= memoryPurgeInterval {
+		s.purge(now)
+	}
+	s.sessions[record.ID] = memoryEntry{
+		data:      data,
+		expiresAt: record.ExpiresAt,
+	}
+	return record.ID, nil
+}
+
+// Delete removes the session identified by the given ID.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of sessions currently stored, including expired
+// sessions that haven't been purged yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+func (s *MemoryStore) purge(now time.Time) {
+	for id, entry := range s.sessions {
+		if !entry.expiresAt.After(now) {
+			delete(s.sessions, id)
+		}
+	}
+	s.lastPurge = now
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Run("save_load_delete", func(t *testing.T) {
+		store := NewMemoryStore()
+		record := testRecord()
+		value, err := store.Save(context.Background(), record)
+		require.NoError(t, err)
+		assert.Equal(t, record.ID, value)
+
+		loaded, err := store.Load(context.Background(), value)
+		require.NoError(t, err)
+		assert.Equal(t, record, loaded)
+		assert.NotSame(t, record, loaded)
+
+		loaded, err = store.Load(context.Background(), "unknown")
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+
+		require.NoError(t, store.Delete(context.Background(), record.ID))
+		loaded, err = store.Load(context.Background(), value)
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+		assert.Equal(t, 0, store.Len())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		store := NewMemoryStore()
+		record := testRecord()
+		record.ExpiresAt = time.Now().Add(-time.Second)
+		_, err := store.Save(context.Background(), record)
+		require.NoError(t, err)
+
+		loaded, err := store.Load(context.Background(), record.ID)
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+		assert.Equal(t, 0, store.Len())
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		store := NewMemoryStore()
+		expired := testRecord()
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		_, err := store.Save(context.Background(), expired)
+		require.NoError(t, err)
+
+		store.lastPurge = time.Now().Add(-memoryPurgeInterval)
+		record := testRecord()
+		record.ID = "other"
+		_, err = store.Save(context.Background(), record)
+		require.NoError(t, err)
+		assert.Equal(t, 1, store.Len())
+		assert.Contains(t, store.sessions, "other")
+	})
+
+	t.Run("marshal_error", func(t *testing.T) {
+		store := NewMemoryStore()
+		record := testRecord()
+		record.Values["func"] = func() {}
+		_, err := store.Save(context.Background(), record)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,220 @@
+package session
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+func init() {
+	config.Register("session.cookie.name", config.Entry{
+		Value:            "goyave_session",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("session.cookie.path", config.Entry{
+		Value:            "/",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("session.cookie.domain", config.Entry{
+		Value:            "",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("session.cookie.secure", config.Entry{
+		Value:            true,
+		Type:             reflect.Bool,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("session.cookie.sameSite", config.Entry{
+		Value:            "Lax",
+		Type:             reflect.String,
+		IsSlice:          false,
+		AuthorizedValues: []any{"Strict", "Lax", "None"},
+	})
+	config.Register("session.idleTimeout", config.Entry{
+		Value:            1800,
+		Type:             reflect.Int,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+	config.Register("session.absoluteTimeout", config.Entry{
+		Value:            86400,
+		Type:             reflect.Int,
+		IsSlice:          false,
+		AuthorizedValues: []any{},
+	})
+}
+
+// Middleware loads the session identified by the request's session cookie and
+// makes it available to the next handlers via `FromRequest`. A new session is
+// started if the request doesn't have a session cookie or if the session is
+// invalid or expired.
+//
+// The session is saved and the cookie is set right before the response header
+// is written, or after the next handlers returned if nothing was written. New sessions
+// that haven't been modified are not saved.
+//
+// A session expires after `session.idleTimeout` seconds without activity, or
+// `session.absoluteTimeout` seconds after it has been created, whichever comes first.
+// The cookie attributes are defined by the `session.cookie.*` config entries.
+//
+// If the store returns an error, the middleware panics.
+//
+//	store, err := session.NewCookieStore(hashKey, encryptionKey)
+//	if err != nil {
+//		panic(err)
+//	}
+//	router.GlobalMiddleware(&session.Middleware{Store: store})
+type Middleware struct {
+	goyave.Component
+	Store Store
+}
+
+// Handle implementation of `goyave.Middleware`.
+func (m *Middleware) Handle(next goyave.Handler) goyave.Handler {
+	return func(response *goyave.Response, request *goyave.Request) {
+		s := m.load(request)
+		request.Extra[ExtraSession{}] = s
+
+		committed := false
+		commit := func() {
+			if committed {
+				return
+			}
+			committed = true
+			m.commit(response, request, s)
+		}
+		response.SetWriter(&sessionWriter{
+			CommonWriter: goyave.NewCommonWriter(response.Writer()),
+			commit:       commit,
+		})
+
+		next(response, request)
+		commit()
+	}
+}
+
+func (m *Middleware) load(request *goyave.Request) *Session {
+	now := time.Now()
+	if cookie, err := request.Request().Cookie(m.Config().GetString("session.cookie.name")); err == nil && cookie.Value != "" {
+		record, err := m.Store.Load(request.Context(), cookie.Value)
+		if err != nil {
+			panic(errors.New(err))
+		}
+		if record != nil {
+			if !m.isExpired(record, now) {
+				return loadSession(record)
+			}
+			if err := m.Store.Delete(request.Context(), record.ID); err != nil {
+				panic(errors.New(err))
+			}
+		}
+	}
+
+	s, err := newSession(now)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (m *Middleware) isExpired(record *Record, now time.Time) bool {
+	return !now.Before(record.ExpiresAt) ||
+		!now.Before(record.LastActivity.Add(m.idleTimeout())) ||
+		!now.Before(record.CreatedAt.Add(m.absoluteTimeout()))
+}
+
+func (m *Middleware) commit(response *goyave.Response, request *goyave.Request, s *Session) {
+	ctx := request.Context()
+	if s.oldID != "" {
+		if err := m.Store.Delete(ctx, s.oldID); err != nil {
+			panic(errors.New(err))
+		}
+	}
+
+	if s.destroyed {
+		if !s.isNew {
+			if err := m.Store.Delete(ctx, s.record.ID); err != nil {
+				panic(errors.New(err))
+			}
+		}
+		cookie := m.cookie("")
+		cookie.MaxAge = -1
+		response.Cookie(cookie)
+		return
+	}
+
+	if s.isNew && !s.modified {
+		return
+	}
+
+	now := time.Now()
+	s.record.LastActivity = now
+	s.record.ExpiresAt = now.Add(m.idleTimeout())
+	if absolute := s.record.CreatedAt.Add(m.absoluteTimeout()); absolute.Before(s.record.ExpiresAt) {
+		s.record.ExpiresAt = absolute
+	}
+
+	value, err := m.Store.Save(ctx, s.record)
+	if err != nil {
+		panic(errors.New(err))
+	}
+	cookie := m.cookie(value)
+	cookie.Expires = s.record.ExpiresAt
+	response.Cookie(cookie)
+}
+
+func (m *Middleware) cookie(value string) *http.Cookie {
+	cfg := m.Config()
+	sameSite := http.SameSiteLaxMode
+	switch cfg.GetString("session.cookie.sameSite") {
+	case "Strict":
+		sameSite = http.SameSiteStrictMode
+	case "None":
+		sameSite = http.SameSiteNoneMode
+	}
+	return &http.Cookie{
+		Name:     cfg.GetString("session.cookie.name"),
+		Value:    value,
+		Path:     cfg.GetString("session.cookie.path"),
+		Domain:   cfg.GetString("session.cookie.domain"),
+		Secure:   cfg.GetBool("session.cookie.secure"),
+		HttpOnly: true,
+		SameSite: sameSite,
+	}
+}
+
+func (m *Middleware) idleTimeout() time.Duration {
+	return time.Duration(m.Config().GetInt("session.idleTimeout")) * time.Second
+}
+
+func (m *Middleware) absoluteTimeout() time.Duration {
+	return time.Duration(m.Config().GetInt("session.absoluteTimeout")) * time.Second
+}
+
+// sessionWriter saves the session right before the response header is written
+// so the session cookie can still be added.
+type sessionWriter struct {
+	commit func()
+	goyave.CommonWriter
+}
+
+func (w *sessionWriter) PreWrite(b []byte) {
+	w.commit()
+	w.CommonWriter.PreWrite(b)
+}
+
+func (w *sessionWriter) PreWriteHeader(status int) {
+	w.commit()
+	w.CommonWriter.PreWriteHeader(status)
+}
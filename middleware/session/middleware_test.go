@@ -0,0 +1,296 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+type failingStore struct {
+	loadErr   error
+	saveErr   error
+	deleteErr error
+	record    *Record
+}
+
+func (s *failingStore) Load(_ context.Context, _ string) (*Record, error) {
+	return s.record, s.loadErr
+}
+
+func (s *failingStore) Save(_ context.Context, record *Record) (string, error) {
+	return record.ID, s.saveErr
+}
+
+func (s *failingStore) Delete(_ context.Context, _ string) error {
+	return s.deleteErr
+}
+
+func prepareMiddlewareTest(t *testing.T) (*testutil.TestServer, *Middleware, *MemoryStore) {
+	cfg := config.LoadDefault()
+	cfg.Set("app.debug", false)
+	server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: cfg})
+	store := NewMemoryStore()
+	return server, &Middleware{Store: store}, store
+}
+
+func doSessionRequest(server *testutil.TestServer, middleware *Middleware, cookie *http.Cookie, handler goyave.Handler) (*http.Response, *http.Cookie) {
+	request := server.NewTestRequest(http.MethodGet, "/session", nil)
+	if cookie != nil {
+		request.Request().AddCookie(cookie)
+	}
+	resp := server.TestMiddleware(middleware, request, handler)
+	_ = resp.Body.Close()
+	for _, c := range resp.Cookies() {
+		if c.Name == "goyave_session" {
+			return resp, c
+		}
+	}
+	return resp, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("new_session_not_modified", func(t *testing.T) {
+		server, middleware, store := prepareMiddlewareTest(t)
+		resp, cookie := doSessionRequest(server, middleware, nil, func(response *goyave.Response, request *goyave.Request) {
+			s := FromRequest(request)
+			require.NotNil(t, s)
+			assert.True(t, s.IsNew())
+			response.Status(http.StatusNoContent)
+		})
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Nil(t, cookie)
+		assert.Equal(t, 0, store.Len())
+	})
+
+	t.Run("save_and_load", func(t *testing.T) {
+		server, middleware, store := prepareMiddlewareTest(t)
+		var id string
+		before := time.Now()
+		resp, cookie := doSessionRequest(server, middleware, nil, func(response *goyave.Response, request *goyave.Request) {
+			s := FromRequest(request)
+			id = s.ID()
+			s.Set("key", "value")
+			// The session is saved before the header is written
+			response.JSON(http.StatusOK, map[string]string{"status": "ok"})
+		})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotNil(t, cookie)
+		assert.Equal(t, id, cookie.Value)
+		assert.Equal(t, "/", cookie.Path)
+		assert.True(t, cookie.HttpOnly)
+		assert.True(t, cookie.Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+		assert.WithinDuration(t, before.Add(30*time.Minute), cookie.Expires, 2*time.Second)
+		assert.Equal(t, 1, store.Len())
+
+		_, cookie = doSessionRequest(server, middleware, cookie, func(response *goyave.Response, request *goyave.Request) {
+			s := FromRequest(request)
+			assert.False(t, s.IsNew())
+			assert.Equal(t, id, s.ID())
+			v, ok := s.Get("key")
+			assert.True(t, ok)
+			assert.Equal(t, "value", v)
+			response.Status(http.StatusNoContent)
+		})
+		// The cookie is renewed on each request
+		require.NotNil(t, cookie)
+		assert.Equal(t, id, cookie.Value)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		cases := []struct {
+			handler goyave.Handler
+			desc    string
+		}{
+			{desc: "http_redirect", handler: func(response *goyave.Response, request *goyave.Request) {
+				FromRequest(request).Set("key", "value")
+				http.Redirect(response, request.Request(), "/login", http.StatusFound)
+			}},
+			{desc: "write_header", handler: func(response *goyave.Response, request *goyave.Request) {
+				FromRequest(request).Set("key", "value")
+				response.Header().Set("Location", "/login")
+				response.WriteHeader(http.StatusFound)
+			}},
+		}
+
+		for _, c := range cases {
+			t.Run(c.desc, func(t *testing.T) {
+				server, middleware, store := prepareMiddlewareTest(t)
+				resp, cookie := doSessionRequest(server, middleware, nil, c.handler)
+				assert.Equal(t, http.StatusFound, resp.StatusCode)
+				assert.Equal(t, "/login", resp.Header.Get("Location"))
+				require.NotNil(t, cookie)
+				assert.Equal(t, 1, store.Len())
+			})
+		}
+	})
+
+	t.Run("cookie_config", func(t *testing.T) {
+		server, middleware, _ := prepareMiddlewareTest(t)
+		server.Config().Set("session.cookie.path", "/app")
+		server.Config().Set("session.cookie.domain", "example.org")
+		server.Config().Set("session.cookie.secure", false)
+		server.Config().Set("session.absoluteTimeout", 60)
+
+		for sameSite, want := range map[string]http.SameSite{"Strict": http.SameSiteStrictMode, "Lax": http.SameSiteLaxMode, "None": http.SameSiteNoneMode} {
+			server.Config().Set("session.cookie.sameSite", sameSite)
+			before := time.Now()
+			_, cookie := doSessionRequest(server, middleware, nil, func(_ *goyave.Response, request *goyave.Request) {
+				FromRequest(request).Set("key", "value")
+			})
+			require.NotNil(t, cookie)
+			assert.Equal(t, "/app", cookie.Path)
+			assert.Equal(t, "example.org", cookie.Domain)
+			assert.False(t, cookie.Secure)
+			assert.Equal(t, want, cookie.SameSite)
+			// The absolute timeout comes first
+			assert.WithinDuration(t, before.Add(time.Minute), cookie.Expires, 2*time.Second)
+		}
+	})
+
+	t.Run("flash", func(t *testing.T) {
+		server, middleware, _ := prepareMiddlewareTest(t)
+		_, cookie := doSessionRequest(server, middleware, nil, func(_ *goyave.Response, request *goyave.Request) {
+			FromRequest(request).Flash("notice", "saved")
+		})
+		require.NotNil(t, cookie)
+
+		_, cookie = doSessionRequest(server, middleware, cookie, func(_ *goyave.Response, request *goyave.Request) {
+			v, ok := FromRequest(request).GetFlash("notice")
+			assert.True(t, ok)
+			assert.Equal(t, "saved", v)
+		})
+		require.NotNil(t, cookie)
+
+		_, _ = doSessionRequest(server, middleware, cookie, func(_ *goyave.Response, request *goyave.Request) {
+			_, ok := FromRequest(request).GetFlash("notice")
+			assert.False(t, ok)
+		})
+	})
+
+	t.Run("regenerate", func(t *testing.T) {
+		server, middleware, store := prepareMiddlewareTest(t)
+		_, cookie := doSessionRequest(server, middleware, nil, func(_ *goyave.Response, request *goyave.Request) {
+			FromRequest(request).Set("key", "value")
+		})
+		require.NotNil(t, cookie)
+		oldID := cookie.Value
+
+		_, cookie = doSessionRequest(server, middleware, cookie, func(_ *goyave.Response, request *goyave.Request) {
+			assert.NoError(t, FromRequest(request).Regenerate())
+		})
+		require.NotNil(t, cookie)
+		assert.NotEqual(t, oldID, cookie.Value)
+		assert.Equal(t, 1, store.Len())
+		assert.NotContains(t, store.sessions, oldID)
+
+		_, _ = doSessionRequest(server, middleware, cookie, func(_ *goyave.Response, request *goyave.Request) {
+			v, _ := FromRequest(request).Get("key")
+			assert.Equal(t, "value", v)
+		})
+	})
+
+	t.Run("destroy", func(t *testing.T) {
+		server, middleware, store := prepareMiddlewareTest(t)
+		_, cookie := doSessionRequest(server, middleware, nil, func(_ *goyave.Response, request *goyave.Request) {
+			FromRequest(request).Set("key", "value")
+		})
+		require.NotNil(t, cookie)
+
+		_, cookie = doSessionRequest(server, middleware, cookie, func(_ *goyave.Response, request *goyave.Request) {
+			FromRequest(request).Destroy()
+		})
+		require.NotNil(t, cookie)
+		assert.Empty(t, cookie.Value)
+		assert.Equal(t, -1, cookie.MaxAge)
+		assert.Equal(t, 0, store.Len())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		server, middleware, store := prepareMiddlewareTest(t)
+		now := time.Now()
+		cases := []*Record{
+			{ID: "expiresAt", CreatedAt: now, LastActivity: now, ExpiresAt: now.Add(-time.Second)},
+			{ID: "idle", CreatedAt: now, LastActivity: now.Add(-31 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+			{ID: "absolute", CreatedAt: now.Add(-25 * time.Hour), LastActivity: now, ExpiresAt: now.Add(time.Hour)},
+		}
+		for _, record := range cases {
+			t.Run(record.ID, func(t *testing.T) {
+				_, err := store.Save(context.Background(), record)
+				require.NoError(t, err)
+				_, _ = doSessionRequest(server, middleware, &http.Cookie{Name: "goyave_session", Value: record.ID}, func(_ *goyave.Response, request *goyave.Request) {
+					s := FromRequest(request)
+					assert.True(t, s.IsNew())
+					assert.NotEqual(t, record.ID, s.ID())
+				})
+				assert.NotContains(t, store.sessions, record.ID)
+			})
+		}
+	})
+
+	t.Run("invalid_cookie", func(t *testing.T) {
+		server, middleware, _ := prepareMiddlewareTest(t)
+		_, cookie := doSessionRequest(server, middleware, &http.Cookie{Name: "goyave_session", Value: "unknown"}, func(_ *goyave.Response, request *goyave.Request) {
+			s := FromRequest(request)
+			assert.True(t, s.IsNew())
+			assert.NotEqual(t, "unknown", s.ID())
+		})
+		assert.Nil(t, cookie)
+	})
+
+	t.Run("store_errors", func(t *testing.T) {
+		now := time.Now()
+		expired := &Record{ID: "expired", CreatedAt: now, LastActivity: now, ExpiresAt: now}
+		valid := &Record{ID: "valid", CreatedAt: now, LastActivity: now, ExpiresAt: now.Add(time.Hour)}
+		cases := []struct {
+			store   *failingStore
+			handler goyave.Handler
+			desc    string
+		}{
+			{desc: "load", store: &failingStore{loadErr: fmt.Errorf("load error")}},
+			{desc: "delete_expired", store: &failingStore{record: expired, deleteErr: fmt.Errorf("delete error")}},
+			{
+				desc:  "save",
+				store: &failingStore{saveErr: fmt.Errorf("save error")},
+				handler: func(response *goyave.Response, request *goyave.Request) {
+					FromRequest(request).Set("key", "value")
+					response.String(http.StatusOK, "hello")
+				},
+			},
+			{
+				desc:  "delete_old_id",
+				store: &failingStore{record: valid, deleteErr: fmt.Errorf("delete error")},
+				handler: func(_ *goyave.Response, request *goyave.Request) {
+					assert.NoError(t, FromRequest(request).Regenerate())
+				},
+			},
+			{
+				desc:  "destroy",
+				store: &failingStore{record: valid, deleteErr: fmt.Errorf("delete error")},
+				handler: func(_ *goyave.Response, request *goyave.Request) {
+					FromRequest(request).Destroy()
+				},
+			},
+		}
+
+		for _, c := range cases {
+			t.Run(c.desc, func(t *testing.T) {
+				server, _, _ := prepareMiddlewareTest(t)
+				handler := c.handler
+				if handler == nil {
+					handler = func(_ *goyave.Response, _ *goyave.Request) {}
+				}
+				resp, _ := doSessionRequest(server, &Middleware{Store: c.store}, &http.Cookie{Name: "goyave_session", Value: "value"}, handler)
+				assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			})
+		}
+	})
+}
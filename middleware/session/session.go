@@ -0,0 +1,169 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// ExtraSession the key used to store the current `*Session` in the request's `Extra`.
+type ExtraSession struct{}
+
+// Record the persisted state of a session. Records are encoded as JSON by
+// the built-in stores, meaning the session values must be JSON-serializable and
+// are decoded as generic JSON values (e.g. numbers are decoded as `float64`).
+type Record struct {
+	CreatedAt    time.Time      `json:"createdAt"`
+	LastActivity time.Time      `json:"lastActivity"`
+	ExpiresAt    time.Time      `json:"expiresAt"`
+	Values       map[string]any `json:"values,omitempty"`
+	Flashes      map[string]any `json:"flashes,omitempty"`
+	ID           string         `json:"id"`
+}
+
+// Session a user session. Sessions are not safe for concurrent use.
+//
+// Sessions are created and saved by the `Middleware`. They can be retrieved
+// in handlers using `FromRequest`.
+type Session struct {
+	record   *Record
+	incoming map[string]any
+	oldID    string
+
+	isNew     bool
+	modified  bool
+	destroyed bool
+}
+
+func newSession(now time.Time) (*Session, error) {
+	id, err := generateID()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		record: &Record{
+			ID:           id,
+			CreatedAt:    now,
+			LastActivity: now,
+			Values:       map[string]any{},
+		},
+		isNew: true,
+	}, nil
+}
+
+func loadSession(record *Record) *Session {
+	if record.Values == nil {
+		record.Values = map[string]any{}
+	}
+	s := &Session{
+		record:   record,
+		incoming: record.Flashes,
+	}
+	record.Flashes = nil
+	return s
+}
+
+func generateID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// FromRequest returns the session of the given request, or `nil` if the
+// session `Middleware` was not executed for this request.
+func FromRequest(request *goyave.Request) *Session {
+	s, _ := request.Extra[ExtraSession{}].(*Session)
+	return s
+}
+
+// ID returns the session ID.
+func (s *Session) ID() string {
+	return s.record.ID
+}
+
+// IsNew returns true if the session was created during the current request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// CreatedAt returns the time at which the session was created.
+func (s *Session) CreatedAt() time.Time {
+	return s.record.CreatedAt
+}
+
+// Get returns the value associated with the given key and true, or `nil`
+// and false if there is no value for this key.
+func (s *Session) Get(key string) (any, bool) {
+	v, ok := s.record.Values[key]
+	return v, ok
+}
+
+// Set associates the given value to the given key.
+func (s *Session) Set(key string, value any) {
+	s.record.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value associated with the given key.
+func (s *Session) Delete(key string) {
+	delete(s.record.Values, key)
+	s.modified = true
+}
+
+// Clear removes all the values from the session, flash messages excluded.
+func (s *Session) Clear() {
+	clear(s.record.Values)
+	s.modified = true
+}
+
+// Flash stores a value only available during the next request, using `GetFlash`.
+// Flash messages are typically used to display a notification after a redirect.
+func (s *Session) Flash(key string, value any) {
+	if s.record.Flashes == nil {
+		s.record.Flashes = map[string]any{}
+	}
+	s.record.Flashes[key] = value
+	s.modified = true
+}
+
+// GetFlash returns the flash value stored with the given key during the previous request.
+func (s *Session) GetFlash(key string) (any, bool) {
+	v, ok := s.incoming[key]
+	return v, ok
+}
+
+// Regenerate changes the session ID while keeping its values. The previous ID
+// is invalidated when the session is saved.
+//
+// The session ID should be regenerated when the privilege level of the user changes,
+// for example on login, to prevent session fixation attacks.
+func (s *Session) Regenerate() error {
+	id, err := generateID()
+	if err != nil {
+		return err
+	}
+	if s.oldID == "" && !s.isNew {
+		s.oldID = s.record.ID
+	}
+	s.record.ID = id
+	s.modified = true
+	return nil
+}
+
+// Destroy removes all the values of the session. The session is deleted from
+// the store and the session cookie is removed when the response is written.
+func (s *Session) Destroy() {
+	clear(s.record.Values)
+	s.record.Flashes = nil
+	s.destroyed = true
+}
+
+// IsDestroyed returns true if `Destroy` has been called.
+func (s *Session) IsDestroyed() bool {
+	return s.destroyed
+}
@@ -0,0 +1,104 @@
+package session
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+func TestSession(t *testing.T) {
+	t.Run("newSession", func(t *testing.T) {
+		now := time.Now()
+		s, err := newSession(now)
+		require.NoError(t, err)
+		assert.True(t, s.IsNew())
+		assert.Len(t, s.ID(), 43)
+		assert.Equal(t, now, s.CreatedAt())
+		assert.Equal(t, now, s.record.LastActivity)
+		assert.NotNil(t, s.record.Values)
+		assert.False(t, s.modified)
+
+		other, err := newSession(now)
+		require.NoError(t, err)
+		assert.NotEqual(t, s.ID(), other.ID())
+	})
+
+	t.Run("loadSession", func(t *testing.T) {
+		record := &Record{ID: "id", Flashes: map[string]any{"notice": "saved"}}
+		s := loadSession(record)
+		assert.False(t, s.IsNew())
+		assert.Equal(t, "id", s.ID())
+		assert.NotNil(t, record.Values)
+		assert.Nil(t, record.Flashes)
+		v, ok := s.GetFlash("notice")
+		assert.True(t, ok)
+		assert.Equal(t, "saved", v)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		s := loadSession(&Record{ID: "id"})
+		v, ok := s.Get("key")
+		assert.False(t, ok)
+		assert.Nil(t, v)
+
+		s.Set("key", "value")
+		s.Set("other", 1)
+		assert.True(t, s.modified)
+		v, ok = s.Get("key")
+		assert.True(t, ok)
+		assert.Equal(t, "value", v)
+
+		s.Delete("key")
+		_, ok = s.Get("key")
+		assert.False(t, ok)
+
+		s.Flash("notice", "saved")
+		s.Clear()
+		assert.Empty(t, s.record.Values)
+		assert.Equal(t, map[string]any{"notice": "saved"}, s.record.Flashes)
+
+		// Outgoing flashes are not readable during the current request
+		_, ok = s.GetFlash("notice")
+		assert.False(t, ok)
+	})
+
+	t.Run("Regenerate", func(t *testing.T) {
+		s := loadSession(&Record{ID: "id"})
+		require.NoError(t, s.Regenerate())
+		assert.NotEqual(t, "id", s.ID())
+		assert.Equal(t, "id", s.oldID)
+		assert.True(t, s.modified)
+
+		// The original ID is kept
+		require.NoError(t, s.Regenerate())
+		assert.Equal(t, "id", s.oldID)
+
+		s, err := newSession(time.Now())
+		require.NoError(t, err)
+		require.NoError(t, s.Regenerate())
+		assert.Empty(t, s.oldID)
+	})
+
+	t.Run("Destroy", func(t *testing.T) {
+		s := loadSession(&Record{ID: "id", Values: map[string]any{"key": "value"}})
+		s.Flash("notice", "saved")
+		assert.False(t, s.IsDestroyed())
+		s.Destroy()
+		assert.True(t, s.IsDestroyed())
+		assert.Empty(t, s.record.Values)
+		assert.Nil(t, s.record.Flashes)
+	})
+
+	t.Run("FromRequest", func(t *testing.T) {
+		request := testutil.NewTestRequest(http.MethodGet, "/", nil)
+		assert.Nil(t, FromRequest(request))
+
+		s := loadSession(&Record{ID: "id"})
+		request.Extra[ExtraSession{}] = s
+		assert.Same(t, s, FromRequest(request))
+	})
+}
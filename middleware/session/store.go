@@ -0,0 +1,150 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// maxCookieSize the maximum size of a cookie value accepted by most browsers.
+const maxCookieSize = 4096
+
+var (
+	// ErrCookieTooLarge returned by `CookieStore.Save` if the encoded session
+	// exceeds the maximum cookie size of 4096 bytes.
+	ErrCookieTooLarge = fmt.Errorf("session: encoded session exceeds %d bytes", maxCookieSize)
+
+	// ErrInvalidKey returned by `NewCookieStore` if one of the given keys doesn't have a valid length.
+	ErrInvalidKey = fmt.Errorf("session: invalid key length")
+)
+
+// Store persists session records.
+type Store interface {
+	// Load returns the record identified by the given cookie value.
+	// If the record doesn't exist or the value is invalid, returns `nil, nil`.
+	// Only unexpected errors (e.g.: database errors) should be returned.
+	Load(ctx context.Context, value string) (*Record, error)
+
+	// Save persists the given record and returns the value of the session cookie.
+	Save(ctx context.Context, record *Record) (string, error)
+
+	// Delete removes the record identified by the given session ID.
+	Delete(ctx context.Context, id string) error
+}
+
+// CookieStore a `Store` keeping the whole session record inside the session
+// cookie. The cookie is signed using HMAC-SHA256 and optionally encrypted
+// using AES-GCM. The server doesn't keep any state, meaning that deleted
+// or regenerated sessions cannot be revoked before they expire.
+//
+// Because the size of a cookie is limited, this store is only suitable
+// for small sessions.
+type CookieStore struct {
+	hashKey []byte
+	aead    cipher.AEAD
+}
+
+// NewCookieStore create a new `CookieStore`. The `hashKey` is used to sign the cookies
+// and must be at least 32 bytes long. If `encryptionKey` is not `nil`, the cookies
+// are also encrypted. The encryption key must be 16, 24 or 32 bytes long to select
+// AES-128, AES-192 or AES-256 respectively.
+//
+// Keys should be generated using a cryptographically secure random generator and
+// kept secret.
+func NewCookieStore(hashKey, encryptionKey []byte) (*CookieStore, error) {
+	if len(hashKey) < 32 {
+		return nil, errors.New(ErrInvalidKey)
+	}
+	store := &CookieStore{
+		hashKey: hashKey,
+	}
+	if encryptionKey != nil {
+		block, err := aes.NewCipher(encryptionKey)
+		if err != nil {
+			return nil, errors.New([]error{ErrInvalidKey, err})
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.New(err)
+		}
+		store.aead = aead
+	}
+	return store, nil
+}
+
+// Load decodes the given cookie value. Returns `nil, nil` if the signature
+// is invalid or if the value cannot be decrypted or decoded.
+func (s *CookieStore) Load(_ context.Context, value string) (*Record, error) {
+	if len(value) > maxCookieSize {
+		return nil, nil
+	}
+	data, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, nil
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(data)) {
+		return nil, nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, nil
+	}
+	if s.aead != nil {
+		nonceSize := s.aead.NonceSize()
+		if len(payload) < nonceSize {
+			return nil, nil
+		}
+		payload, err = s.aead.Open(nil, payload[:nonceSize], payload[nonceSize:], nil)
+		if err != nil {
+			return nil, nil
+		}
+	}
+	record := &Record{}
+	if err := json.Unmarshal(payload, record); err != nil {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// Save encodes the given record. Returns `ErrCookieTooLarge` if the result
+// exceeds 4096 bytes.
+func (s *CookieStore) Save(_ context.Context, record *Record) (string, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return "", errors.New(err)
+	}
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", errors.New(err)
+		}
+		payload = s.aead.Seal(nonce, nonce, payload, nil)
+	}
+	data := base64.RawURLEncoding.EncodeToString(payload)
+	value := data + "." + base64.RawURLEncoding.EncodeToString(s.sign(data))
+	if len(value) > maxCookieSize {
+		return "", errors.New(ErrCookieTooLarge)
+	}
+	return value, nil
+}
+
+// Delete does nothing: the session is removed by expiring the client's cookie.
+func (s *CookieStore) Delete(_ context.Context, _ string) error {
+	return nil
+}
+
+func (s *CookieStore) sign(data string) []byte {
+	h := hmac.New(sha256.New, s.hashKey)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord() *Record {
+	now := time.Now().Truncate(time.Second).UTC()
+	return &Record{
+		ID:           "id",
+		CreatedAt:    now,
+		LastActivity: now,
+		ExpiresAt:    now.Add(time.Hour),
+		Values:       map[string]any{"key": "value", "number": float64(1)},
+		Flashes:      map[string]any{"notice": "saved"},
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	hashKey := []byte(strings.Repeat("h", 32))
+	encryptionKey := []byte(strings.Repeat("e", 32))
+
+	t.Run("NewCookieStore", func(t *testing.T) {
+		_, err := NewCookieStore([]byte("short"), nil)
+		require.ErrorIs(t, err, ErrInvalidKey)
+
+		_, err = NewCookieStore(hashKey, []byte("invalid"))
+		require.ErrorIs(t, err, ErrInvalidKey)
+
+		store, err := NewCookieStore(hashKey, nil)
+		require.NoError(t, err)
+		assert.Nil(t, store.aead)
+
+		store, err = NewCookieStore(hashKey, encryptionKey)
+		require.NoError(t, err)
+		assert.NotNil(t, store.aead)
+	})
+
+	for desc, key := range map[string][]byte{"signed": nil, "encrypted": encryptionKey} {
+		t.Run(desc, func(t *testing.T) {
+			store, err := NewCookieStore(hashKey, key)
+			require.NoError(t, err)
+
+			record := testRecord()
+			value, err := store.Save(context.Background(), record)
+			require.NoError(t, err)
+			if key != nil {
+				assert.NotContains(t, value, "value")
+			}
+
+			loaded, err := store.Load(context.Background(), value)
+			require.NoError(t, err)
+			assert.Equal(t, record, loaded)
+
+			// Tampered values
+			data, signature, _ := strings.Cut(value, ".")
+			for _, v := range []string{"", "nodot", data + ".", "a" + value, data + ".!!!", "!!!." + signature, strings.Repeat("a", maxCookieSize+1)} {
+				loaded, err = store.Load(context.Background(), v)
+				require.NoError(t, err)
+				assert.Nil(t, loaded)
+			}
+
+			// Signed with another key
+			other, err := NewCookieStore([]byte(strings.Repeat("o", 32)), key)
+			require.NoError(t, err)
+			loaded, err = other.Load(context.Background(), value)
+			require.NoError(t, err)
+			assert.Nil(t, loaded)
+
+			require.NoError(t, store.Delete(context.Background(), record.ID))
+		})
+	}
+
+	t.Run("too_large", func(t *testing.T) {
+		store, err := NewCookieStore(hashKey, nil)
+		require.NoError(t, err)
+		record := testRecord()
+		record.Values["large"] = strings.Repeat("a", maxCookieSize)
+		_, err = store.Save(context.Background(), record)
+		require.ErrorIs(t, err, ErrCookieTooLarge)
+	})
+
+	t.Run("marshal_error", func(t *testing.T) {
+		store, err := NewCookieStore(hashKey, nil)
+		require.NoError(t, err)
+		record := testRecord()
+		record.Values["func"] = func() {}
+		_, err = store.Save(context.Background(), record)
+		require.Error(t, err)
+	})
+}
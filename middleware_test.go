@@ -259,19 +259,22 @@ func (v *testValidator) Name() string {
 
 func TestValidateMiddleware(t *testing.T) {
 	cases := []struct {
-		next              func(*Response, *Request)
-		queryRules        func(*Request) validation.RuleSet
-		bodyRules         func(*Request) validation.RuleSet
-		headers           map[string]string
-		query             map[string]any
-		data              any
-		expectQueryErrors *validation.Errors
-		expectBodyErrors  *validation.Errors
-		desc              string
-		expectBody        string
-		hasDB             bool
-		expectPass        bool
-		expectStatus      int
+		next               func(*Response, *Request)
+		queryRules         func(*Request) validation.RuleSet
+		bodyRules          func(*Request) validation.RuleSet
+		paramsRules        func(*Request) validation.RuleSet
+		headers            map[string]string
+		query              map[string]any
+		params             map[string]string
+		data               any
+		expectQueryErrors  *validation.Errors
+		expectBodyErrors   *validation.Errors
+		expectParamsErrors *validation.Errors
+		desc               string
+		expectBody         string
+		hasDB              bool
+		expectPass         bool
+		expectStatus       int
 	}{
 		{
 			desc: "query_ok",
@@ -456,6 +459,36 @@ func TestValidateMiddleware(t *testing.T) {
 				assert.Equal(t, map[string]any{"param": 6}, r.Query)
 			},
 		},
+		{
+			desc: "params_ok",
+			paramsRules: func(_ *Request) validation.RuleSet {
+				return validation.RuleSet{{Path: "id", Rules: validation.List{validation.Required(), validation.Int(), validation.Min(5)}}}
+			},
+			params:       map[string]string{"id": "6"},
+			expectBody:   "OK",
+			expectPass:   true,
+			expectStatus: http.StatusOK,
+			next: func(_ *Response, r *Request) {
+				assert.Equal(t, map[string]string{"id": "6"}, r.RouteParams)
+				assert.Equal(t, map[string]any{"id": 6}, r.Extra[ExtraValidatedParams{}])
+			},
+		},
+		{
+			desc: "params_error",
+			paramsRules: func(_ *Request) validation.RuleSet {
+				return validation.RuleSet{{Path: "id", Rules: validation.List{validation.Required(), validation.Int(), validation.Min(5)}}}
+			},
+			queryRules: func(_ *Request) validation.RuleSet {
+				return validation.RuleSet{{Path: "param", Rules: validation.List{validation.Required()}}}
+			},
+			params:       map[string]string{"id": "4"},
+			query:        map[string]any{"param": "v"},
+			expectPass:   false,
+			expectStatus: http.StatusUnprocessableEntity,
+			expectParamsErrors: &validation.Errors{Fields: validation.FieldsErrors{
+				"id": &validation.Errors{Errors: []string{"The id must be at least 5."}},
+			}},
+		},
 		{
 			desc: "query_and_body_error",
 			queryRules: func(_ *Request) validation.RuleSet {
@@ -500,8 +533,9 @@ func TestValidateMiddleware(t *testing.T) {
 			}()
 
 			m := &validateRequestMiddleware{
-				QueryRules: c.queryRules,
-				BodyRules:  c.bodyRules,
+				QueryRules:  c.queryRules,
+				BodyRules:   c.bodyRules,
+				ParamsRules: c.paramsRules,
 			}
 			m.Init(server)
 
@@ -510,6 +544,7 @@ func TestValidateMiddleware(t *testing.T) {
 			request.Lang = server.Lang.GetDefault()
 			request.Query = c.query
 			request.Data = c.data
+			request.RouteParams = c.params
 			if c.headers != nil {
 				for h, v := range c.headers {
 					request.httpRequest.Header.Set(h, v)
@@ -547,6 +582,11 @@ func TestValidateMiddleware(t *testing.T) {
 			} else {
 				assert.Equal(t, c.expectBodyErrors, request.Extra[ExtraValidationError{}])
 			}
+			if c.expectParamsErrors == nil {
+				assert.NotContains(t, request.Extra, ExtraParamsValidationError{})
+			} else {
+				assert.Equal(t, c.expectParamsErrors, request.Extra[ExtraParamsValidationError{}])
+			}
 		})
 	}
 }
@@ -3,6 +3,7 @@ package goyave
 import (
 	"fmt"
 	"regexp"
+	"regexp/syntax"
 	"strings"
 )
 
@@ -11,9 +12,54 @@ import (
 type parameterizable struct {
 	regex      *regexp.Regexp
 	parameters []string
+
+	// segments the compiled URI segments. Only used if `segmented` is true.
+	segments []uriSegment
+	// literalPrefix the leading literal segments of the URI, used to index
+	// URIs that are not segmented in the route tree.
+	literalPrefix []string
+	// segmented true if the URI can be matched segment by segment, meaning that
+	// none of its parameters can match a slash. Otherwise, the regex is used.
+	segmented bool
+}
+
+// routeParamTypes the built-in patterns that can be used as parameter types in
+// routes and hosts. For example "{id:int}" is equivalent to "{id:-?[0-9]+}".
+var routeParamTypes = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[a-zA-Z]+`,
+	"alnum": `[a-zA-Z0-9]+`,
+	"slug":  `[a-z0-9]+(?:-[a-z0-9]+)*`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// resolveParamType returns the pattern of the built-in type if the given
+// pattern is a type name. Otherwise returns the pattern unchanged.
+func resolveParamType(pattern string) string {
+	if typ, ok := routeParamTypes[pattern]; ok {
+		return typ
+	}
+	return pattern
+}
+
+type segmentKind uint8
+
+const (
+	segmentLiteral segmentKind = iota
+	segmentParam               // A single parameter without pattern, matching any non-empty segment
+	segmentRegex               // Parameters with a pattern or mixed with literal characters
+)
+
+// uriSegment a part of a URI delimited by slashes.
+type uriSegment struct {
+	regex   *regexp.Regexp
+	literal string
+	kind    segmentKind
 }
 
 // compileParameters parse the route parameters and compiles their regexes if needed.
+// The pattern of a parameter can be the name of a built-in type (see `routeParamTypes`).
 // If "ends" is set to true, the generated regex ends with "$", thus set "ends" to true
 // if you're compiling route parameters, set to false if you're compiling router parameters.
 func (p *parameterizable) compileParameters(uri string, ends bool, regexCache map[string]*regexp.Regexp) {
@@ -46,6 +92,7 @@ func (p *parameterizable) compileParameters(uri string, ends bool, regexCache ma
 				if pattern == "" {
 					panic(fmt.Errorf("invalid route parameter, missing pattern in %q", sub))
 				}
+				pattern = resolveParamType(pattern)
 			}
 
 			builder.WriteString(raw)
@@ -80,6 +127,258 @@ func (p *parameterizable) compileParameters(uri string, ends bool, regexCache ma
 		panic(fmt.Sprintf("route %s contains capture groups in its regexp. ", uri) +
 			"Only non-capturing groups are accepted: e.g. (?:pattern) instead of (pattern)")
 	}
+
+	p.compileSegments(uri, idxs, regexCache)
+}
+
+// compileHost parse the parameters of a host pattern (e.g. "{tenant}.example.com") and
+// compiles its regex. Contrary to URIs, the literal parts of the pattern are matched
+// literally and case-insensitively and the default parameter pattern matches a single
+// host label (`[^.]+`).
+func (p *parameterizable) compileHost(host string, regexCache map[string]*regexp.Regexp) {
+	idxs, err := p.braceIndices(host)
+	if err != nil {
+		panic(err)
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(host) + 2)
+	builder.WriteString("^")
+	end := 0
+	length := len(idxs)
+	for i := 0; i < length; i += 2 {
+		builder.WriteString(regexp.QuoteMeta(strings.ToLower(host[end:idxs[i]])))
+		end = idxs[i+1]
+		sub := host[idxs[i]+1 : end]
+		parts := strings.SplitN(sub, ":", 2)
+		if parts[0] == "" {
+			panic(fmt.Errorf("invalid host parameter, missing name in %q", sub))
+		}
+		pattern := "[^.]+" // default pattern
+		if len(parts) == 2 {
+			pattern = parts[1]
+			if pattern == "" {
+				panic(fmt.Errorf("invalid host parameter, missing pattern in %q", sub))
+			}
+			pattern = resolveParamType(pattern)
+		}
+		builder.WriteString("(")
+		builder.WriteString(pattern)
+		builder.WriteString(")")
+		end++ // Skip closing braces
+		p.parameters = append(p.parameters, parts[0])
+	}
+	builder.WriteString(regexp.QuoteMeta(strings.ToLower(host[end:])))
+	builder.WriteString("$")
+
+	pattern := builder.String()
+	regex, ok := regexCache[pattern]
+	if !ok {
+		regex = regexp.MustCompile(pattern)
+		regexCache[pattern] = regex
+	}
+	p.regex = regex
+
+	if p.regex.NumSubexp() != length/2 {
+		panic(fmt.Sprintf("host %s contains capture groups in its regexp. ", host) +
+			"Only non-capturing groups are accepted: e.g. (?:pattern) instead of (pattern)")
+	}
+}
+
+// fillParameters replace the parameters of the given pattern with the given values, in order.
+func (p *parameterizable) fillParameters(pattern string, parameters []string) string {
+	var builder strings.Builder
+	builder.Grow(len(pattern))
+
+	idxs, _ := p.braceIndices(pattern)
+	length := len(idxs)
+	end := 0
+	currentParam := 0
+	for i := 0; i < length; i += 2 {
+		raw := pattern[end:idxs[i]]
+		end = idxs[i+1]
+		builder.WriteString(raw)
+		builder.WriteString(parameters[currentParam])
+		currentParam++
+		end++ // Skip closing braces
+	}
+	builder.WriteString(pattern[end:])
+
+	return builder.String()
+}
+
+// compileSegments split the URI into segments so it can be matched without running
+// the whole regex. Segments without parameters are compared as plain strings, segments
+// only made of a parameter without pattern match any non-empty segment and only the
+// other segments use a regex.
+//
+// If one of the segments can match a slash, the URI is not segmented and
+// only its leading literal segments are kept for indexing.
+func (p *parameterizable) compileSegments(uri string, idxs []int, regexCache map[string]*regexp.Regexp) {
+	p.segments = nil
+	p.literalPrefix = nil
+	p.segmented = false
+	if uri == "" {
+		p.segmented = true
+		return
+	}
+	if uri[0] != '/' {
+		return
+	}
+
+	segments := make([]uriSegment, 0, strings.Count(uri, "/"))
+	segmented := true
+	start := 1
+	brace := 0
+	for i := 1; i <= len(uri); i++ {
+		if brace < len(idxs) && i == idxs[brace] {
+			// Skip the parameter, its pattern may contain slashes
+			i = idxs[brace+1]
+			brace += 2
+			continue
+		}
+		if i < len(uri) && uri[i] != '/' {
+			continue
+		}
+		segment, ok := p.compileSegment(uri[start:i], regexCache)
+		if !ok {
+			segmented = false
+			break
+		}
+		if segment.kind == segmentLiteral && segmented && len(p.literalPrefix) == len(segments) && i < len(uri) {
+			p.literalPrefix = append(p.literalPrefix, segment.literal)
+		}
+		segments = append(segments, segment)
+		start = i + 1
+	}
+
+	if segmented {
+		p.segments = segments
+		p.segmented = true
+		p.literalPrefix = nil
+	}
+}
+
+func (p *parameterizable) compileSegment(raw string, regexCache map[string]*regexp.Regexp) (uriSegment, bool) {
+	idxs, err := p.braceIndices(raw)
+	if err != nil {
+		return uriSegment{}, false
+	}
+	if len(idxs) == 0 && regexp.QuoteMeta(raw) == raw {
+		return uriSegment{kind: segmentLiteral, literal: raw}, true
+	}
+	if len(idxs) == 2 && idxs[0] == 0 && idxs[1] == len(raw)-1 && !strings.Contains(raw, ":") {
+		return uriSegment{kind: segmentParam}, true
+	}
+
+	var builder strings.Builder
+	builder.WriteString("^")
+	end := 0
+	for i := 0; i < len(idxs); i += 2 {
+		builder.WriteString(raw[end:idxs[i]])
+		end = idxs[i+1]
+		pattern := "[^/]+"
+		if _, pat, ok := strings.Cut(raw[idxs[i]+1:end], ":"); ok {
+			pattern = resolveParamType(pat)
+		}
+		builder.WriteString("(")
+		builder.WriteString(pattern)
+		builder.WriteString(")")
+		end++
+	}
+	builder.WriteString(raw[end:])
+	builder.WriteString("$")
+
+	pattern := builder.String()
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil || canMatchSlash(parsed) {
+		return uriSegment{}, false
+	}
+	regex, ok := regexCache[pattern]
+	if !ok {
+		regex = regexp.MustCompile(pattern)
+		regexCache[pattern] = regex
+	}
+	return uriSegment{kind: segmentRegex, regex: regex}, true
+}
+
+// canMatchSlash returns true if the given regex may match a slash. The result
+// is conservative: it can return true for regexes that never actually match a slash.
+func canMatchSlash(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpLiteral:
+		return strings.ContainsRune(string(re.Rune), '/')
+	case syntax.OpCharClass:
+		for i := 0; i < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '/' && '/' <= re.Rune[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, sub := range re.Sub {
+		if canMatchSlash(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchURI returns the submatches of the given URI in the same format as
+// `regexp.FindStringSubmatch`: the first element is the full match and the
+// following elements are the parameters' values. The returned slice is `nil` if the
+// URI has no parameters. Returns false if the URI doesn't match.
+func (p *parameterizable) matchURI(uri string) ([]string, bool) {
+	if !p.segmented {
+		match := p.regex.FindStringSubmatch(uri)
+		return match, match != nil
+	}
+
+	var values []string
+	rest := uri
+	for _, segment := range p.segments {
+		if rest == "" || rest[0] != '/' {
+			return nil, false
+		}
+		var s string
+		if i := strings.IndexByte(rest[1:], '/'); i >= 0 {
+			s, rest = rest[1:i+1], rest[i+1:]
+		} else {
+			s, rest = rest[1:], ""
+		}
+
+		switch segment.kind {
+		case segmentLiteral:
+			if s != segment.literal {
+				return nil, false
+			}
+		case segmentParam:
+			if s == "" {
+				return nil, false
+			}
+			values = p.appendValues(values, uri, s)
+		case segmentRegex:
+			match := segment.regex.FindStringSubmatch(s)
+			if match == nil {
+				return nil, false
+			}
+			values = p.appendValues(values, uri, match[1:]...)
+		}
+	}
+	if rest != "" {
+		return nil, false
+	}
+	return values, true
+}
+
+func (p *parameterizable) appendValues(values []string, uri string, v ...string) []string {
+	if values == nil {
+		values = make([]string, 1, len(p.parameters)+1)
+		values[0] = uri
+	}
+	return append(values, v...)
 }
 
 // braceIndices returns the first level curly brace indices from a string.
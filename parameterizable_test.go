@@ -62,6 +62,73 @@ func (suite *ParameterizableTestSuite) TestCompileParametersRouter() {
 	suite.False(p.regex.MatchString("/product/qwerty/extra"))
 }
 
+func (suite *ParameterizableTestSuite) TestCompileHost() {
+	regexCache := make(map[string]*regexp.Regexp, 5)
+	p := &parameterizable{}
+	p.compileHost("{tenant}.Example.com", regexCache)
+	suite.Equal([]string{"tenant"}, p.parameters)
+	suite.Equal(`^([^.]+)\.example\.com$`, p.regex.String())
+	suite.True(p.regex.MatchString("acme.example.com"))
+	suite.False(p.regex.MatchString("a.b.example.com"))
+	suite.False(p.regex.MatchString("acme.exampleXcom"))
+	suite.False(p.regex.MatchString("example.com"))
+
+	p = &parameterizable{}
+	p.compileHost("{sub:.+}.example.com", regexCache)
+	suite.True(p.regex.MatchString("a.b.example.com"))
+
+	suite.Panics(func() { // Empty name, expect error
+		(&parameterizable{}).compileHost("{:[a-z]+}.example.com", regexCache)
+	})
+	suite.Panics(func() { // Empty pattern, expect error
+		(&parameterizable{}).compileHost("{tenant:}.example.com", regexCache)
+	})
+	suite.Panics(func() { // Capturing groups
+		(&parameterizable{}).compileHost("{tenant:(a|b)}.example.com", regexCache)
+	})
+	suite.Panics(func() { // Unbalanced
+		(&parameterizable{}).compileHost("{tenant.example.com", regexCache)
+	})
+}
+
+func (suite *ParameterizableTestSuite) TestParamTypes() {
+	regexCache := make(map[string]*regexp.Regexp, 5)
+	cases := []struct {
+		uri      string
+		match    []string
+		notMatch []string
+	}{
+		{uri: "/{id:int}", match: []string{"/1", "/-12"}, notMatch: []string{"/a", "/1.5", "/"}},
+		{uri: "/{id:uint}", match: []string{"/1", "/0123"}, notMatch: []string{"/-1", "/a"}},
+		{uri: "/{name:alpha}", match: []string{"/abc", "/ABC"}, notMatch: []string{"/a1", "/a-b"}},
+		{uri: "/{name:alnum}", match: []string{"/abc1", "/A2"}, notMatch: []string{"/a-1", "/a_1"}},
+		{uri: "/{slug:slug}", match: []string{"/lawn-mower", "/item2"}, notMatch: []string{"/-lawn", "/lawn-", "/Lawn", "/lawn--mower"}},
+		{uri: "/{id:uuid}", match: []string{"/3bbcee75-cecc-5b56-8031-b6641c1ed1f1", "/3BBCEE75-CECC-5B56-8031-B6641C1ED1F1"}, notMatch: []string{"/3bbcee75", "/3bbcee75-cecc-5b56-8031-b6641c1ed1fz"}},
+		{uri: "/items/{id:int}-{slug:slug}", match: []string{"/items/1-lawn-mower"}, notMatch: []string{"/items/a-lawn-mower"}},
+	}
+
+	for _, c := range cases {
+		p := &parameterizable{}
+		p.compileParameters(c.uri, true, regexCache)
+		suite.True(p.segmented, c.uri)
+		for _, path := range c.match {
+			_, ok := p.matchURI(path)
+			suite.True(ok, "%q %q", c.uri, path)
+			suite.True(p.regex.MatchString(path), "%q %q", c.uri, path)
+		}
+		for _, path := range c.notMatch {
+			_, ok := p.matchURI(path)
+			suite.False(ok, "%q %q", c.uri, path)
+			suite.False(p.regex.MatchString(path), "%q %q", c.uri, path)
+		}
+	}
+
+	p := &parameterizable{}
+	p.compileHost("{tenant:slug}.example.com", regexCache)
+	suite.True(p.regex.MatchString("my-company.example.com"))
+	suite.False(p.regex.MatchString("my_company.example.com"))
+}
+
 func (suite *ParameterizableTestSuite) TestBraceIndices() {
 	p := &parameterizable{}
 	str := "/product/{id:[0-9]+}"
@@ -129,6 +196,79 @@ func (suite *ParameterizableTestSuite) TestRegexCache() {
 	suite.Same(p1.regex, p2.regex)
 }
 
+func (suite *ParameterizableTestSuite) TestCompileSegments() {
+	regexCache := make(map[string]*regexp.Regexp, 5)
+	cases := []struct {
+		uri           string
+		kinds         []segmentKind
+		literalPrefix []string
+		segmented     bool
+	}{
+		{uri: "", segmented: true, kinds: []segmentKind{}},
+		{uri: "/", segmented: true, kinds: []segmentKind{segmentLiteral}},
+		{uri: "/product/{id}", segmented: true, kinds: []segmentKind{segmentLiteral, segmentParam}},
+		{uri: "/product/{id:[0-9]+}/{name}", segmented: true, kinds: []segmentKind{segmentLiteral, segmentRegex, segmentParam}},
+		{uri: "/product/{id}-{name}", segmented: true, kinds: []segmentKind{segmentLiteral, segmentRegex}},
+		{uri: "/product/{name:[^/]+}", segmented: true, kinds: []segmentKind{segmentLiteral, segmentRegex}},
+		{uri: "/files/{path:.*}", segmented: false, literalPrefix: []string{"files"}},
+		{uri: "/static/files{path:.*}", segmented: false, literalPrefix: []string{"static"}},
+		{uri: "/product/{id}.json", segmented: false, literalPrefix: []string{"product"}}, // "." can match a slash
+		{uri: "/a/{b:x/y}/c", segmented: false, literalPrefix: []string{"a"}},
+		{uri: "/a/b", segmented: true, kinds: []segmentKind{segmentLiteral, segmentLiteral}},
+		{uri: "product", segmented: false},
+	}
+
+	for _, c := range cases {
+		p := &parameterizable{}
+		p.compileParameters(c.uri, true, regexCache)
+		suite.Equal(c.segmented, p.segmented, c.uri)
+		suite.Equal(c.literalPrefix, p.literalPrefix, c.uri)
+		if c.segmented {
+			kinds := make([]segmentKind, 0, len(p.segments))
+			for _, segment := range p.segments {
+				kinds = append(kinds, segment.kind)
+			}
+			suite.Equal(c.kinds, kinds, c.uri)
+		}
+	}
+}
+
+func (suite *ParameterizableTestSuite) TestMatchURI() {
+	regexCache := make(map[string]*regexp.Regexp, 5)
+	uris := []string{
+		"", "/", "/product", "/product/", "/product/{id}", "/product/{id:[0-9]+}",
+		"/product/{id:[0-9]+}/{name}", "/product/{id}-{name}", "/product/{id:[0-9]*}/edit",
+		"/{category}/{sort:(?:asc|desc)}", "/files/{path:.*}", "/product/{id}.json",
+	}
+	paths := []string{
+		"", "/", "//", "/product", "/product/", "/product//", "/product/33", "/product/abc", "/product/33/",
+		"/product/33/test", "/product/33-test", "/product/33-", "/product//edit", "/product/33/edit",
+		"/lawn-mower/asc", "/lawn-mower/new", "/files/", "/files/a/b.txt", "/product/33.json",
+		"/product/33/json", "product",
+	}
+
+	for _, uri := range uris {
+		for _, ends := range []bool{true, false} {
+			p := &parameterizable{}
+			p.compileParameters(uri, ends, regexCache)
+			for _, path := range paths {
+				expected := p.regex.FindStringSubmatch(path)
+				var actual []string
+				var ok bool
+				if ends {
+					actual, ok = p.matchURI(path)
+				} else {
+					actual, ok = (&Router{parameterizable: *p}).matchPrefix(path)
+				}
+				suite.Equal(expected != nil, ok, "%q %q", uri, path)
+				if ok && len(expected) > 1 {
+					suite.Equal(expected[1:], actual[1:], "%q %q", uri, path)
+				}
+			}
+		}
+	}
+}
+
 func (suite *ParameterizableTestSuite) TestGetParameters() {
 	p := &parameterizable{
 		parameters: []string{"a", "b"},
@@ -0,0 +1,203 @@
+package goyave
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// forwardedInfo the client information resolved from the request's
+// remote address and forwarding headers.
+type forwardedInfo struct {
+	clientIP string
+	scheme   string
+	host     string
+}
+
+// forwardedHop an element of the `Forwarded` or `X-Forwarded-*` headers.
+type forwardedHop struct {
+	forwardedFor string
+	proto        string
+	host         string
+}
+
+// parseTrustedProxies parses the given list of IP addresses and CIDR ranges.
+// Single addresses are converted to a prefix containing only this address.
+func parseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		if strings.Contains(v, "/") {
+			prefix, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, errors.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, errors.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func isTrustedProxy(trustedProxies []netip.Prefix, address string) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveForwarded determines the client IP, scheme and host of the given request.
+// The forwarding headers are only taken into account if the request was sent
+// by a trusted proxy.
+//
+// The hops are walked from the closest to the furthest. The first hop that doesn't
+// come from a trusted proxy is considered the client. If all the hops are trusted, the
+// furthest one is used.
+func resolveForwarded(trustedProxies []netip.Prefix, req *http.Request) *forwardedInfo {
+	info := &forwardedInfo{
+		clientIP: remoteIP(req.RemoteAddr),
+		scheme:   "http",
+		host:     req.Host,
+	}
+	if req.TLS != nil {
+		info.scheme = "https"
+	}
+
+	if !isTrustedProxy(trustedProxies, info.clientIP) {
+		return info
+	}
+
+	hops := parseForwarded(req.Header)
+	if len(hops) == 0 {
+		return info
+	}
+
+	i := len(hops) - 1
+	for ; i > 0; i-- {
+		if !isTrustedProxy(trustedProxies, hops[i].forwardedFor) {
+			break
+		}
+	}
+	if forwardedFor := hops[i].forwardedFor; forwardedFor != "" {
+		info.clientIP = forwardedFor
+	}
+	// The proto and host lists may be shorter than the "for" list if some of the
+	// proxies don't set them. In that case, the outermost value present is used.
+	for _, hop := range hops[i:] {
+		if hop.proto != "" {
+			info.scheme = strings.ToLower(hop.proto)
+			break
+		}
+	}
+	for _, hop := range hops[i:] {
+		if hop.host != "" {
+			info.host = hop.host
+			break
+		}
+	}
+	return info
+}
+
+// parseForwarded returns the hops found in the `Forwarded` header or, if absent,
+// in the `X-Forwarded-For`, `X-Forwarded-Proto` and `X-Forwarded-Host` headers.
+func parseForwarded(header http.Header) []forwardedHop {
+	if values := header.Values("Forwarded"); len(values) > 0 {
+		return parseForwardedHeader(values)
+	}
+
+	forwardedFor := splitHeaderList(header.Values("X-Forwarded-For"))
+	protos := splitHeaderList(header.Values("X-Forwarded-Proto"))
+	hosts := splitHeaderList(header.Values("X-Forwarded-Host"))
+	n := max(len(forwardedFor), len(protos), len(hosts))
+	if n == 0 {
+		return nil
+	}
+
+	// Proxies don't necessarily add all the headers. The values are
+	// aligned on the closest hop.
+	hops := make([]forwardedHop, n)
+	for i, v := range forwardedFor {
+		hops[n-len(forwardedFor)+i].forwardedFor = stripPort(v)
+	}
+	for i, v := range protos {
+		hops[n-len(protos)+i].proto = v
+	}
+	for i, v := range hosts {
+		hops[n-len(hosts)+i].host = v
+	}
+	return hops
+}
+
+// parseForwardedHeader parses the RFC 7239 `Forwarded` header.
+//
+//	Forwarded: for=192.0.2.60;proto=https;host=example.org, for="[2001:db8::1]:4711"
+func parseForwardedHeader(values []string) []forwardedHop {
+	hops := []forwardedHop{}
+	for _, element := range splitHeaderList(values) {
+		hop := forwardedHop{}
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+			value = strings.Trim(value, `"`)
+			switch strings.ToLower(key) {
+			case "for":
+				hop.forwardedFor = stripPort(value)
+			case "proto":
+				hop.proto = value
+			case "host":
+				hop.host = value
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+func splitHeaderList(values []string) []string {
+	list := []string{}
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// stripPort removes the port and the IPv6 brackets from the given node identifier.
+// Obfuscated identifiers and "unknown" are returned unchanged.
+func stripPort(node string) string {
+	if addr, err := netip.ParseAddrPort(node); err == nil {
+		return addr.Addr().Unmap().String()
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(node, "[]")); err == nil {
+		return addr.Unmap().String()
+	}
+	return node
+}
+
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
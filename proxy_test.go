@@ -0,0 +1,198 @@
+package goyave
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.12/16", "127.0.0.1", "::1", "::ffff:172.16.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("127.0.0.1/32"),
+		netip.MustParsePrefix("::1/128"),
+		netip.MustParsePrefix("172.16.0.1/32"),
+	}, prefixes)
+
+	_, err = parseTrustedProxies([]string{"10.0.0.0/33"})
+	require.ErrorContains(t, err, `invalid trusted proxy "10.0.0.0/33"`)
+	_, err = parseTrustedProxies([]string{"not an IP"})
+	require.ErrorContains(t, err, `invalid trusted proxy "not an IP"`)
+
+	prefixes, err = parseTrustedProxies(nil)
+	require.NoError(t, err)
+	assert.Empty(t, prefixes)
+}
+
+func TestResolveForwarded(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		headers    map[string][]string
+		want       *forwardedInfo
+		desc       string
+		remoteAddr string
+		tls        bool
+	}{
+		{
+			desc:       "untrusted_remote",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.1"}, "X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"example.org"}},
+			want:       &forwardedInfo{clientIP: "192.0.2.1", scheme: "http", host: "example.com"},
+		},
+		{
+			desc:       "untrusted_remote_tls",
+			remoteAddr: "192.0.2.1:1234",
+			tls:        true,
+			want:       &forwardedInfo{clientIP: "192.0.2.1", scheme: "https", host: "example.com"},
+		},
+		{
+			desc:       "trusted_no_headers",
+			remoteAddr: "10.0.0.1:1234",
+			want:       &forwardedInfo{clientIP: "10.0.0.1", scheme: "http", host: "example.com"},
+		},
+		{
+			desc:       "x_forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.1"}, "X-Forwarded-Proto": {"HTTPS"}, "X-Forwarded-Host": {"example.org"}},
+			want:       &forwardedInfo{clientIP: "203.0.113.1", scheme: "https", host: "example.org"},
+		},
+		{
+			desc:       "x_forwarded_chain",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1, 203.0.113.1", "10.0.0.2"}},
+			want:       &forwardedInfo{clientIP: "203.0.113.1", scheme: "http", host: "example.com"},
+		},
+		{
+			desc:       "x_forwarded_all_trusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:       &forwardedInfo{clientIP: "10.0.0.3", scheme: "http", host: "example.com"},
+		},
+		{
+			desc:       "x_forwarded_partial_headers",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.1, 10.0.0.2"}, "X-Forwarded-Proto": {"https"}},
+			// The proto list is shorter than the for list: the outermost proto is used
+			want: &forwardedInfo{clientIP: "203.0.113.1", scheme: "https", host: "example.com"},
+		},
+		{
+			desc:       "x_forwarded_partial_headers_outermost",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For":   {"198.51.100.1, 203.0.113.1, 10.0.0.2"},
+				"X-Forwarded-Proto": {"https, http"},
+				"X-Forwarded-Host":  {"example.org, internal.example.com"},
+			},
+			want: &forwardedInfo{clientIP: "203.0.113.1", scheme: "https", host: "example.org"},
+		},
+		{
+			desc:       "x_forwarded_ipv6_with_port",
+			remoteAddr: "[2001:db8::1]:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"[2001:db9::1]:4711"}},
+			want:       &forwardedInfo{clientIP: "2001:db9::1", scheme: "http", host: "example.com"},
+		},
+		{
+			desc:       "forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {`for=203.0.113.1;proto=https;host=example.org`}},
+			want:       &forwardedInfo{clientIP: "203.0.113.1", scheme: "https", host: "example.org"},
+		},
+		{
+			desc:       "forwarded_takes_precedence",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"Forwarded":       {`for=203.0.113.1`},
+				"X-Forwarded-For": {"198.51.100.1"},
+			},
+			want: &forwardedInfo{clientIP: "203.0.113.1", scheme: "http", host: "example.com"},
+		},
+		{
+			desc:       "forwarded_chain",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{"Forwarded": {
+				`for=198.51.100.1;proto=http, For="[2001:db9::1]:4711";Proto=https;Host="example.org"`,
+				`for=10.0.0.2;proto=http;host=internal`,
+			}},
+			want: &forwardedInfo{clientIP: "2001:db9::1", scheme: "https", host: "example.org"},
+		},
+		{
+			desc:       "forwarded_obfuscated",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {`for=unknown;proto=https, for=10.0.0.2`}},
+			want:       &forwardedInfo{clientIP: "unknown", scheme: "https", host: "example.com"},
+		},
+		{
+			desc:       "forwarded_invalid_pairs",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {`invalid;by=10.0.0.1`}},
+			want:       &forwardedInfo{clientIP: "10.0.0.1", scheme: "http", host: "example.com"},
+		},
+		{
+			desc:       "invalid_remote_address",
+			remoteAddr: "[::1",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.1"}},
+			want:       &forwardedInfo{clientIP: "[::1", scheme: "http", host: "example.com"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = c.remoteAddr
+			if c.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range c.headers {
+				req.Header[k] = v
+			}
+			assert.Equal(t, c.want, resolveForwarded(trustedProxies, req))
+		})
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	t.Run("invalid_config", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("server.trustedProxies", []string{"invalid"})
+		s, err := New(Options{Config: cfg})
+		assert.Nil(t, s)
+		require.Error(t, err)
+		_, ok := err.(*errors.Error)
+		assert.True(t, ok)
+	})
+
+	t.Run("request_accessors", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("server.trustedProxies", []string{"192.0.2.0/24"})
+		server, err := New(Options{Config: cfg})
+		require.NoError(t, err)
+
+		server.Router().Get("/", func(response *Response, request *Request) {
+			assert.Equal(t, "203.0.113.1", request.ClientIP())
+			assert.Equal(t, "https", request.Scheme())
+			assert.Equal(t, "example.org", request.Host())
+			assert.Equal(t, "https://example.org", request.BaseURL())
+			response.Status(http.StatusNoContent)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil) // RemoteAddr is 192.0.2.1:1234
+		req.Header.Set("X-Forwarded-For", "203.0.113.1")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "example.org")
+		recorder := httptest.NewRecorder()
+		server.Router().ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"strings"
 	"sync"
@@ -22,6 +23,10 @@ type (
 	// store the query validation rules.
 	ExtraQueryValidationRules struct{}
 
+	// ExtraParamsValidationRules the key used in `Context.Extra` to
+	// store the route parameters validation rules.
+	ExtraParamsValidationRules struct{}
+
 	// ExtraValidationError the key used in `Context.Extra` to
 	// store the body validation errors.
 	ExtraValidationError struct{}
@@ -30,9 +35,23 @@ type (
 	// store the query validation errors.
 	ExtraQueryValidationError struct{}
 
+	// ExtraParamsValidationError the key used in `Context.Extra` to
+	// store the route parameters validation errors.
+	ExtraParamsValidationError struct{}
+
+	// ExtraValidatedParams the key used in `Context.Extra` to
+	// store the route parameters converted by the validation, as a `map[string]any`.
+	// Only set if the parameters passed validation. Read by `Param()`.
+	ExtraValidatedParams struct{}
+
 	// ExtraParseError the key used in `Context.Extra` to
 	// store specific parsing errors.
 	ExtraParseError struct{}
+
+	// ExtraForbiddenReason the key used in `Context.Extra` to
+	// store the translated reason why a request was denied with "403 Forbidden".
+	// Written to the response by `ForbiddenStatusHandler`.
+	ExtraForbiddenReason struct{}
 )
 
 var (
@@ -70,6 +89,9 @@ type Request struct {
 	Route       *Route
 	RouteParams map[string]string
 	cookies     []*http.Cookie
+
+	trustedProxies []netip.Prefix
+	forwarded      *forwardedInfo
 }
 
 var requestPool = sync.Pool{
@@ -91,6 +113,8 @@ func (r *Request) reset(httpRequest *http.Request) {
 	r.Now = time.Now()
 	r.Extra = map[any]any{}
 	r.cookies = nil
+	r.trustedProxies = nil
+	r.forwarded = nil
 	r.Data = nil
 	r.Lang = nil
 	r.Query = nil
@@ -151,10 +175,55 @@ func (r *Request) ContentLength() int64 {
 
 // RemoteAddress allows to record the network address that
 // sent the request, usually for logging.
+//
+// If the application is behind a reverse proxy, this is the address of the proxy.
+// Use `ClientIP` to get the address of the client.
 func (r *Request) RemoteAddress() string {
 	return r.httpRequest.RemoteAddr
 }
 
+// ClientIP returns the IP address of the client. If the request was sent by
+// one of the proxies defined in the "server.trustedProxies" config, the address
+// is read from the `Forwarded` or `X-Forwarded-For` headers. Otherwise, returns
+// the IP part of `RemoteAddress`.
+//
+// The forwarding hops are walked from the closest to the furthest: the first address that
+// is not a trusted proxy is returned. The returned value may be an obfuscated
+// identifier if a proxy doesn't disclose the client address (e.g. "unknown").
+func (r *Request) ClientIP() string {
+	return r.getForwarded().clientIP
+}
+
+// Scheme returns the scheme ("http" or "https") used by the client. If the request was
+// sent by a trusted proxy, the scheme is read from the `Forwarded` or `X-Forwarded-Proto`
+// headers. Otherwise, returns "https" if the connection uses TLS.
+func (r *Request) Scheme() string {
+	return r.getForwarded().scheme
+}
+
+// Host returns the host (and port if specified) requested by the client. If the request
+// was sent by a trusted proxy, the host is read from the `Forwarded` or `X-Forwarded-Host`
+// headers. Otherwise, returns the `Host` header of the request.
+func (r *Request) Host() string {
+	return r.getForwarded().host
+}
+
+// BaseURL returns the base URL used by the client to reach the application,
+// using `Scheme` and `Host` ("https://example.org" for example).
+//
+// Contrary to `Server.ProxyBaseURL()`, this is resolved for each request and
+// can be used to build URLs when the application is reachable from multiple hosts.
+func (r *Request) BaseURL() string {
+	return r.Scheme() + "://" + r.Host()
+}
+
+func (r *Request) getForwarded() *forwardedInfo {
+	if r.forwarded == nil {
+		r.forwarded = resolveForwarded(r.trustedProxies, r.httpRequest)
+	}
+	return r.forwarded
+}
+
 // Cookies returns the HTTP cookies sent with the request.
 func (r *Request) Cookies() []*http.Cookie {
 	if r.cookies == nil {
@@ -40,6 +40,10 @@ func TestRequest(t *testing.T) {
 		assert.Equal(t, "/test", r.URL().String())
 		assert.Equal(t, int64(5), r.ContentLength())
 		assert.Equal(t, "192.0.2.1:1234", r.RemoteAddress())
+		assert.Equal(t, "192.0.2.1", r.ClientIP())
+		assert.Equal(t, "http", r.Scheme())
+		assert.Equal(t, "example.com", r.Host())
+		assert.Equal(t, "http://example.com", r.BaseURL())
 
 		cookies := r.Cookies()
 		assert.Equal(t, cookies, r.cookies)
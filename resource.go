@@ -0,0 +1,210 @@
+package goyave
+
+import (
+	"net/http"
+	"strings"
+
+	"goyave.dev/goyave/v5/util/errors"
+	"goyave.dev/goyave/v5/validation"
+)
+
+// The actions a resource controller can implement. `Router.Resource()` registers
+// a route for each action implemented by the controller.
+type (
+	// ResourceIndexer lists the resources. Registered as "GET /" on the collection.
+	ResourceIndexer interface {
+		Index(response *Response, request *Request)
+	}
+
+	// ResourceShower shows a single resource. Registered as "GET /" on the member.
+	ResourceShower interface {
+		Show(response *Response, request *Request)
+	}
+
+	// ResourceStorer creates a resource. Registered as "POST /" on the collection.
+	ResourceStorer interface {
+		Store(response *Response, request *Request)
+	}
+
+	// ResourceUpdater updates a resource. Registered as "PATCH /" and "PUT /" on the member.
+	ResourceUpdater interface {
+		Update(response *Response, request *Request)
+	}
+
+	// ResourceDestroyer deletes a resource. Registered as "DELETE /" on the member.
+	ResourceDestroyer interface {
+		Destroy(response *Response, request *Request)
+	}
+)
+
+// Optional interfaces providing the validation rules of the resource actions.
+type (
+	// ResourceIndexValidator provides the query validation rules of the "index" action.
+	ResourceIndexValidator interface {
+		IndexRules(request *Request) validation.RuleSet
+	}
+
+	// ResourceStoreValidator provides the body validation rules of the "store" action.
+	ResourceStoreValidator interface {
+		StoreRules(request *Request) validation.RuleSet
+	}
+
+	// ResourceUpdateValidator provides the body validation rules of the "update" action.
+	ResourceUpdateValidator interface {
+		UpdateRules(request *Request) validation.RuleSet
+	}
+)
+
+// ResourceParameterizer optional interface overriding the definition of the route
+// parameter identifying a resource. The returned value uses the route parameter
+// syntax without braces, and can therefore have a pattern or a type (e.g. "articleId:uint").
+type ResourceParameterizer interface {
+	ResourceParameter() string
+}
+
+// Resource a group of conventional CRUD routes registered by `Router.Resource()`.
+type Resource struct {
+	// Collection the router of the collection (e.g. "/articles").
+	Collection *Router
+
+	// Member the router of a single resource (e.g. "/articles/{articleId}"). Nil if the
+	// controller doesn't implement any member action and no nested resource is registered,
+	// so it doesn't match the additional routes of the collection.
+	Member *Router
+
+	// Name the name of the resource, used as a prefix for the route names
+	// (e.g. "articles", or "articles.comments" for a nested resource).
+	Name string
+
+	parameter string
+}
+
+// Resource registers the conventional CRUD routes for the actions implemented by the given
+// controller. The controller is initialized and must implement at least one of
+// `ResourceIndexer`, `ResourceShower`, `ResourceStorer`, `ResourceUpdater` and `ResourceDestroyer`.
+//
+// For the prefix "/articles", the following routes are registered:
+//
+//	GET          /articles               articles.index
+//	POST         /articles               articles.store
+//	GET          /articles/{articleId}   articles.show
+//	PATCH, PUT   /articles/{articleId}   articles.update
+//	DELETE       /articles/{articleId}   articles.destroy
+//
+// The name of the resource is the last segment of the prefix. The route parameter identifying
+// a resource is named after the singular of the resource name followed by "Id". The singular
+// is naively computed by removing the trailing "s" (or replacing "ies" with "y"). Implement
+// `ResourceParameterizer` to choose another parameter name, or to give the parameter a type.
+//
+// Validation rules are added to the actions if the controller implements `ResourceIndexValidator`,
+// `ResourceStoreValidator` or `ResourceUpdateValidator`.
+//
+// If the controller also implements `Registrer`, `RegisterRoutes()` is called with the
+// collection router so additional routes can be registered. Keep in mind that subrouters
+// are matched before routes: if the controller implements a member action, give the resource
+// parameter a type so the member router doesn't match the additional routes of the collection.
+//
+// Use `Resource.Resource()` to register nested resources.
+func (r *Router) Resource(prefix string, controller Composable) *Resource {
+	return r.resource(prefix, controller, "")
+}
+
+// Resource registers a nested resource on the member router of this resource.
+// The nested routes are prefixed by the member path (e.g. "/articles/{articleId}/comments")
+// and their names are prefixed by the name of this resource (e.g. "articles.comments.index").
+// See `Router.Resource()`.
+func (r *Resource) Resource(prefix string, controller Composable) *Resource {
+	return r.member().resource(prefix, controller, r.Name+".")
+}
+
+// member returns the member router, creating it if it doesn't exist yet.
+func (r *Resource) member() *Router {
+	if r.Member == nil {
+		r.Member = r.Collection.Subrouter("/{" + r.parameter + "}")
+	}
+	return r.Member
+}
+
+func (r *Router) resource(prefix string, controller Composable, namePrefix string) *Resource {
+	controller.Init(r.server)
+
+	name := prefix[strings.LastIndexByte(prefix, '/')+1:]
+	parameter := resourceParameter(name)
+	if p, ok := controller.(ResourceParameterizer); ok {
+		parameter = p.ResourceParameter()
+	}
+
+	resource := &Resource{
+		Name:       namePrefix + name,
+		Collection: r.Subrouter(prefix),
+		parameter:  parameter,
+	}
+
+	registered := false
+	if c, ok := controller.(ResourceIndexer); ok {
+		route := resource.Collection.Get("/", c.Index).Name(resource.Name + ".index")
+		if v, ok := controller.(ResourceIndexValidator); ok {
+			route.ValidateQuery(v.IndexRules)
+		}
+		registered = true
+	}
+	if c, ok := controller.(ResourceStorer); ok {
+		route := resource.Collection.Post("/", c.Store).Name(resource.Name + ".store")
+		if v, ok := controller.(ResourceStoreValidator); ok {
+			route.ValidateBody(v.StoreRules)
+		}
+		registered = true
+	}
+	if c, ok := controller.(ResourceShower); ok {
+		resource.member().Get("/", c.Show).Name(resource.Name + ".show")
+		registered = true
+	}
+	if c, ok := controller.(ResourceUpdater); ok {
+		route := resource.member().Route([]string{http.MethodPatch, http.MethodPut}, "/", c.Update).Name(resource.Name + ".update")
+		if v, ok := controller.(ResourceUpdateValidator); ok {
+			route.ValidateBody(v.UpdateRules)
+		}
+		registered = true
+	}
+	if c, ok := controller.(ResourceDestroyer); ok {
+		resource.member().Delete("/", c.Destroy).Name(resource.Name + ".destroy")
+		registered = true
+	}
+	if !registered {
+		panic(errors.Errorf("resource %q: the controller %T doesn't implement any resource action", resource.Name, controller))
+	}
+
+	if registrer, ok := controller.(Registrer); ok {
+		registrer.RegisterRoutes(resource.Collection)
+	}
+	return resource
+}
+
+// resourceParameter returns the default name of the route parameter identifying
+// a resource: the singular of the name in camel case, followed by "Id".
+// For example "blog-posts" gives "blogPostId".
+func resourceParameter(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		name = strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		name = strings.TrimSuffix(name, "s")
+	}
+
+	var builder strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '-' || r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			builder.WriteString(strings.ToUpper(string(r)))
+			upper = false
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	builder.WriteString("Id")
+	return builder.String()
+}
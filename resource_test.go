@@ -0,0 +1,178 @@
+package goyave
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5/validation"
+)
+
+type testResourceController struct {
+	Component
+	registered bool
+}
+
+func (c *testResourceController) Index(_ *Response, _ *Request)   {}
+func (c *testResourceController) Show(_ *Response, _ *Request)    {}
+func (c *testResourceController) Store(_ *Response, _ *Request)   {}
+func (c *testResourceController) Update(_ *Response, _ *Request)  {}
+func (c *testResourceController) Destroy(_ *Response, _ *Request) {}
+
+func (c *testResourceController) IndexRules(_ *Request) validation.RuleSet {
+	return validation.RuleSet{}
+}
+
+func (c *testResourceController) StoreRules(_ *Request) validation.RuleSet {
+	return validation.RuleSet{}
+}
+
+func (c *testResourceController) UpdateRules(_ *Request) validation.RuleSet {
+	return validation.RuleSet{}
+}
+
+func (c *testResourceController) ResourceParameter() string {
+	return "articleId:uint"
+}
+
+func (c *testResourceController) RegisterRoutes(router *Router) {
+	c.registered = true
+	router.Get("/drafts", nil).Name("articles.drafts")
+}
+
+type testReadOnlyResourceController struct {
+	Component
+}
+
+func (c *testReadOnlyResourceController) Index(_ *Response, _ *Request) {}
+func (c *testReadOnlyResourceController) Show(_ *Response, _ *Request)  {}
+
+type testIndexResourceController struct {
+	Component
+}
+
+func (c *testIndexResourceController) Index(_ *Response, _ *Request) {}
+
+func (c *testIndexResourceController) RegisterRoutes(router *Router) {
+	router.Get("/drafts", nil).Name("articles.drafts")
+}
+
+func TestResource(t *testing.T) {
+	t.Run("Resource", func(t *testing.T) {
+		router := prepareRouterTest()
+		ctrl := &testResourceController{}
+		resource := router.Resource("/articles", ctrl)
+
+		assert.Equal(t, "articles", resource.Name)
+		assert.Equal(t, router.server, ctrl.server)
+		assert.True(t, ctrl.registered)
+		assert.Equal(t, "/articles", resource.Collection.prefix)
+		assert.Equal(t, "/{articleId:uint}", resource.Member.prefix)
+
+		cases := []struct {
+			name       string
+			uri        string
+			methods    []string
+			validation bool
+		}{
+			{name: "articles.index", uri: "/articles", methods: []string{http.MethodGet, http.MethodHead}, validation: true},
+			{name: "articles.store", uri: "/articles", methods: []string{http.MethodPost}, validation: true},
+			{name: "articles.show", uri: "/articles/{articleId:uint}", methods: []string{http.MethodGet, http.MethodHead}},
+			{name: "articles.update", uri: "/articles/{articleId:uint}", methods: []string{http.MethodPatch, http.MethodPut}, validation: true},
+			{name: "articles.destroy", uri: "/articles/{articleId:uint}", methods: []string{http.MethodDelete}},
+			{name: "articles.drafts", uri: "/articles/drafts", methods: []string{http.MethodGet, http.MethodHead}},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				route := router.GetRoute(c.name)
+				require.NotNil(t, route)
+				assert.Equal(t, c.uri, route.GetFullURI())
+				assert.Equal(t, c.methods, route.GetMethods())
+				assert.Equal(t, c.validation, findMiddleware[*validateRequestMiddleware](route.middleware) != nil)
+			})
+		}
+
+		match, ok := router.Match(http.MethodPut, "/articles/12")
+		assert.True(t, ok)
+		assert.Equal(t, "articles.update", match.Route.GetName())
+		assert.Equal(t, map[string]string{"articleId": "12"}, match.Params)
+
+		match, ok = router.Match(http.MethodGet, "/articles/drafts")
+		assert.True(t, ok)
+		assert.Equal(t, "articles.drafts", match.Route.GetName())
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		router := prepareRouterTest()
+		resource := router.Resource("/api/categories", &testReadOnlyResourceController{})
+
+		assert.Equal(t, "categories", resource.Name)
+		assert.Equal(t, "/{categoryId}", resource.Member.prefix)
+		assert.NotNil(t, router.GetRoute("categories.index"))
+		assert.NotNil(t, router.GetRoute("categories.show"))
+		assert.Nil(t, router.GetRoute("categories.store"))
+		assert.Nil(t, router.GetRoute("categories.update"))
+		assert.Nil(t, router.GetRoute("categories.destroy"))
+		assert.Nil(t, findMiddleware[*validateRequestMiddleware](router.GetRoute("categories.index").middleware))
+
+		match, ok := router.Match(http.MethodDelete, "/api/categories/abc")
+		assert.False(t, ok)
+		assert.Equal(t, ErrMatchMethodNotAllowed, match.Err)
+	})
+
+	t.Run("collection_only", func(t *testing.T) {
+		router := prepareRouterTest()
+		resource := router.Resource("/articles", &testIndexResourceController{})
+		assert.Nil(t, resource.Member)
+
+		match, ok := router.Match(http.MethodGet, "/articles/drafts")
+		assert.True(t, ok)
+		assert.Equal(t, "articles.drafts", match.Route.GetName())
+
+		comments := resource.Resource("/comments", &testReadOnlyResourceController{})
+		require.NotNil(t, resource.Member)
+		assert.Equal(t, "/{articleId}", resource.Member.prefix)
+		assert.Equal(t, "/articles/{articleId}/comments/{commentId}", router.GetRoute(comments.Name+".show").GetFullURI())
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		router := prepareRouterTest()
+		articles := router.Resource("/articles", &testResourceController{})
+		comments := articles.Resource("/comments", &testReadOnlyResourceController{})
+
+		assert.Equal(t, "articles.comments", comments.Name)
+		route := router.GetRoute("articles.comments.show")
+		require.NotNil(t, route)
+		assert.Equal(t, "/articles/{articleId:uint}/comments/{commentId}", route.GetFullURI())
+		assert.Equal(t, "/articles/{articleId:uint}/comments", router.GetRoute("articles.comments.index").GetFullURI())
+
+		match, ok := router.Match(http.MethodGet, "/articles/3/comments/abc")
+		assert.True(t, ok)
+		assert.Equal(t, route, match.Route)
+		assert.Equal(t, map[string]string{"articleId": "3", "commentId": "abc"}, match.Params)
+	})
+
+	t.Run("no_action", func(t *testing.T) {
+		router := prepareRouterTest()
+		assert.Panics(t, func() {
+			router.Resource("/articles", &testController{})
+		})
+	})
+}
+
+func TestResourceParameter(t *testing.T) {
+	cases := map[string]string{
+		"articles":   "articleId",
+		"categories": "categoryId",
+		"address":    "addressId",
+		"blog-posts": "blogPostId",
+		"user_roles": "userRoleId",
+		"":           "Id",
+	}
+	for name, expected := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, resourceParameter(name))
+		})
+	}
+}
@@ -32,6 +32,13 @@ type PreWriter interface {
 	PreWrite(b []byte)
 }
 
+// HeaderPreWriter is a writer that needs to alter the response headers right before
+// they are written, even if the response has no body (e.g. redirects).
+// If implemented, PreWriteHeader will be called right before the header is written.
+type HeaderPreWriter interface {
+	PreWriteHeader(status int)
+}
+
 // The Flusher interface is implemented by writers that allow
 // handlers to flush buffered data to the client.
 //
@@ -65,6 +72,14 @@ func (w CommonWriter) PreWrite(b []byte) {
 	}
 }
 
+// PreWriteHeader calls PreWriteHeader on the
+// child writer if it implements HeaderPreWriter.
+func (w CommonWriter) PreWriteHeader(status int) {
+	if hw, ok := w.wr.(HeaderPreWriter); ok {
+		hw.PreWriteHeader(status)
+	}
+}
+
 func (w CommonWriter) Write(b []byte) (int, error) {
 	n, err := w.wr.Write(b)
 	return n, errorutil.New(err)
@@ -173,8 +188,13 @@ func (r *Response) Write(data []byte) (int, error) {
 // status code.
 // Prefer using "Status()" method instead.
 // Calling this method a second time will have no effect.
+//
+// PreWriteHeader is called on the child writer first if it implements HeaderPreWriter.
 func (r *Response) WriteHeader(status int) {
 	if !r.wroteHeader {
+		if hw, ok := r.writer.(HeaderPreWriter); ok {
+			hw.PreWriteHeader(status)
+		}
 		r.status = status
 		r.wroteHeader = true
 		r.responseWriter.WriteHeader(status)
@@ -246,6 +266,15 @@ func (r *Response) Hijacked() bool {
 	return r.hijacked
 }
 
+// Unwrap returns the original `http.ResponseWriter`. This allows `http.ResponseController`
+// to access the features of the underlying writer, such as `SetWriteDeadline`.
+//
+// Writing directly to the returned writer or flushing it bypasses the chained writers.
+// Use `Response.Write()` and `Response.Flush()` instead.
+func (r *Response) Unwrap() http.ResponseWriter {
+	return r.responseWriter
+}
+
 // --------------------------------------
 // Chained writers
 
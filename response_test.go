@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
@@ -45,16 +46,21 @@ func (r *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 
 type testChainedWriter struct {
 	*httptest.ResponseRecorder
-	flushErr   error
-	prewritten []byte
-	closed     bool
-	flushed    bool
+	flushErr         error
+	prewritten       []byte
+	prewrittenStatus int
+	closed           bool
+	flushed          bool
 }
 
 func (r *testChainedWriter) PreWrite(b []byte) {
 	r.prewritten = b
 }
 
+func (r *testChainedWriter) PreWriteHeader(status int) {
+	r.prewrittenStatus = status
+}
+
 func (r *testChainedWriter) Flush() error {
 	r.flushed = true
 	return r.flushErr
@@ -449,6 +455,20 @@ func TestResponse(t *testing.T) {
 		assert.Equal(t, "hello world", string(body))
 	})
 
+	t.Run("PreWriteHeader", func(t *testing.T) {
+		resp, recorder := newTestReponse()
+		newWriter := &testChainedWriter{
+			ResponseRecorder: recorder,
+		}
+		resp.SetWriter(newWriter)
+		resp.WriteHeader(http.StatusFound)
+		resp.WriteHeader(http.StatusOK)
+		_, _ = resp.Write([]byte("hello world"))
+
+		assert.Equal(t, http.StatusFound, newWriter.prewrittenStatus)
+		assert.Equal(t, http.StatusFound, recorder.Code)
+	})
+
 	t.Run("PreWrite_called_once", func(t *testing.T) {
 		resp, recorder := newTestReponse()
 		newWriter := &testChainedWriter{
@@ -516,6 +536,16 @@ func TestResponse(t *testing.T) {
 		})
 	})
 
+	t.Run("Unwrap", func(t *testing.T) {
+		resp, recorder := newTestReponse()
+		resp.SetWriter(&bytes.Buffer{})
+		assert.Same(t, recorder, resp.Unwrap())
+
+		// http.ResponseController reaches the original writer
+		err := http.NewResponseController(resp).SetWriteDeadline(time.Time{})
+		require.ErrorIs(t, err, http.ErrNotSupported)
+	})
+
 	t.Run("SetWriter", func(t *testing.T) {
 		resp, _ := newTestReponse()
 		newWriter := &bytes.Buffer{}
@@ -807,6 +837,8 @@ func TestCommonWriter(t *testing.T) {
 
 	wr.PreWrite([]byte("hello"))
 	assert.Equal(t, []byte("hello"), chainedWriter.prewritten)
+	wr.PreWriteHeader(http.StatusFound)
+	assert.Equal(t, http.StatusFound, chainedWriter.prewrittenStatus)
 
 	_, err := wr.Write([]byte("hello"))
 	assert.NoError(t, err)
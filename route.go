@@ -2,9 +2,12 @@ package goyave
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/samber/lo"
 	"goyave.dev/goyave/v5/cors"
@@ -25,6 +28,7 @@ type Route struct {
 	handler Handler
 	middlewareHolder
 	parameterizable
+	matchers []RequestMatcher
 }
 
 var _ routeMatcher = (*Route)(nil) // implements routeMatcher
@@ -51,7 +55,7 @@ func newRoute(handler Handler, name string) *Route {
 }
 
 func (r *Route) match(method string, match *routeMatch) bool {
-	if params := r.regex.FindStringSubmatch(match.currentPath); params != nil {
+	if params, ok := r.matchURI(match.currentPath); ok && matchRequest(r.matchers, match) {
 		if r.checkMethod(method) {
 			if len(params) > 1 {
 				match.mergeParams(r.makeParameters(params))
@@ -59,16 +63,17 @@ func (r *Route) match(method string, match *routeMatch) bool {
 			match.route = r
 			return true
 		}
-		match.err = errMatchMethodNotAllowed
+		match.err = ErrMatchMethodNotAllowed
+		match.addAllowedMethods(r.methods)
 		return false
 	}
 
 	if match.err == nil {
 		// Don't override error if already set.
-		// Not nil error means it's either already errMatchNotFound
-		// or it's errMatchMethodNotAllowed, implying that a route has
+		// Not nil error means it's either already ErrMatchNotFound
+		// or it's ErrMatchMethodNotAllowed, implying that a route has
 		// already been matched but with wrong method.
-		match.err = errMatchNotFound
+		match.err = ErrMatchNotFound
 	}
 	return false
 }
@@ -151,6 +156,35 @@ func (r *Route) ValidateQuery(validationRules RuleSetFunc) *Route {
 	return r
 }
 
+// ValidateParams adds (or replace) validation rules for the route parameters.
+// The parameters are validated as strings, before the query and the body.
+// If the validation passes, the converted values are stored in `Request.Extra`
+// using the `ExtraValidatedParams` key. `Param()` returns them if their type matches.
+// `Request.RouteParams` is left untouched.
+func (r *Route) ValidateParams(validationRules RuleSetFunc) *Route {
+	validationMiddleware := findMiddleware[*validateRequestMiddleware](r.middleware)
+	if validationMiddleware == nil {
+		r.Middleware(&validateRequestMiddleware{ParamsRules: validationRules})
+	} else {
+		validationMiddleware.ParamsRules = validationRules
+	}
+	return r
+}
+
+// Timeout set the maximum duration of the requests handled by this route only,
+// overriding the timeout inherited from the parent routers.
+// If the timeout is strictly positive, the timeout middleware is automatically added globally.
+// To disable the timeout for this route, give a zero duration.
+//
+// See `Router.Timeout()` for more details.
+func (r *Route) Timeout(timeout time.Duration) *Route {
+	r.Meta[MetaTimeout] = timeout
+	if timeout > 0 && !hasMiddleware[*timeoutMiddleware](r.parent.globalMiddleware.middleware) {
+		r.parent.GlobalMiddleware(&timeoutMiddleware{})
+	}
+	return r
+}
+
 // CORS set the CORS options for this route only.
 // The "OPTIONS" method is added if this route doesn't already support it.
 //
@@ -187,18 +221,56 @@ func (r *Route) Middleware(middleware ...Middleware) *Route {
 	return r
 }
 
+// Matcher add one or more conditions the request must satisfy for this route to match.
+// If one of them is not satisfied, the next route matching the path is tried.
+// See `RequestMatcher`.
+func (r *Route) Matcher(matchers ...RequestMatcher) *Route {
+	r.matchers = append(r.matchers, matchers...)
+	return r
+}
+
 // BuildURL build a full URL pointing to this route.
 // Panics if the amount of parameters doesn't match the amount of
 // actual parameters for this route.
+//
+// If the route belongs to a router matching on host (see `Router.Host()`), the
+// host parameters are expected first and the URL uses the host built from them
+// instead of the host of the base URL.
 func (r *Route) BuildURL(parameters ...string) string {
-	return r.parent.server.BaseURL() + r.BuildURI(parameters...)
+	return r.buildURL(r.parent.server.BaseURL(), parameters)
 }
 
 // BuildProxyURL build a full URL pointing to this route using the proxy base URL.
 // Panics if the amount of parameters doesn't match the amount of
 // actual parameters for this route.
+//
+// Host parameters are handled the same way as `BuildURL()`.
 func (r *Route) BuildProxyURL(parameters ...string) string {
-	return r.parent.server.ProxyBaseURL() + r.BuildURI(parameters...)
+	return r.buildURL(r.parent.server.ProxyBaseURL(), parameters)
+}
+
+func (r *Route) buildURL(baseURL string, parameters []string) string {
+	hostRouter := r.parent.hostRouter()
+	if hostRouter == nil {
+		return baseURL + r.BuildURI(parameters...)
+	}
+
+	_, uriParameters := r.GetFullURIAndParameters()
+	hostParamCount := len(hostRouter.host.parameters)
+	if len(parameters) != hostParamCount+len(uriParameters) {
+		panic(errors.Errorf("BuildURL: route has %d parameters, %d given", hostParamCount+len(uriParameters), len(parameters)))
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(errors.New(err))
+	}
+	host := r.fillParameters(hostRouter.hostPattern, parameters[:hostParamCount])
+	if port := u.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	u.Host = host
+	return u.String() + r.BuildURI(parameters[hostParamCount:]...)
 }
 
 // BuildURI build a full URI pointing to this route. The returned
@@ -212,24 +284,7 @@ func (r *Route) BuildURI(parameters ...string) string {
 		panic(errors.Errorf("BuildURI: route has %d parameters, %d given", len(fullParameters), len(parameters)))
 	}
 
-	var builder strings.Builder
-	builder.Grow(len(fullURI))
-
-	idxs, _ := r.braceIndices(fullURI)
-	length := len(idxs)
-	end := 0
-	currentParam := 0
-	for i := 0; i < length; i += 2 {
-		raw := fullURI[end:idxs[i]]
-		end = idxs[i+1]
-		builder.WriteString(raw)
-		builder.WriteString(parameters[currentParam])
-		currentParam++
-		end++ // Skip closing braces
-	}
-	builder.WriteString(fullURI[end:])
-
-	return builder.String()
+	return r.fillParameters(fullURI, parameters)
 }
 
 // GetName get the name of this route.
@@ -270,6 +325,12 @@ func (r *Route) GetFullURI() string {
 	return strings.Join(segments, "")
 }
 
+// GetHost returns the host pattern this route matches against (e.g. "{tenant}.example.com"),
+// inherited from its parent routers. Returns an empty string if the route matches any host.
+func (r *Route) GetHost() string {
+	return r.parent.GetHost()
+}
+
 // GetMethods returns the methods the route matches against.
 func (r *Route) GetMethods() []string {
 	cpy := make([]string, len(r.methods))
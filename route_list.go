@@ -0,0 +1,176 @@
+package goyave
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// RouteInfo the description of a registered route, returned by `Router.ListRoutes()`.
+type RouteInfo struct {
+	// Route the described route.
+	Route *Route `json:"-"`
+
+	// Meta the meta values applying to the route: the route's meta merged
+	// with the values inherited from its parent routers.
+	Meta map[string]any `json:"meta,omitempty"`
+
+	// Name the name of the route. Empty if the route is not named.
+	Name string `json:"name,omitempty"`
+
+	// URI the full URI of the route. See `Route.GetFullURI()`.
+	URI string `json:"uri"`
+
+	// Host the host pattern the route matches against. See `Route.GetHost()`.
+	Host string `json:"host,omitempty"`
+
+	// Methods the methods the route matches against.
+	Methods []string `json:"methods"`
+
+	// Middleware the type names of the middleware applied to the route,
+	// in execution order: global middleware first, then the middleware of
+	// each parent router and finally the route's middleware.
+	Middleware []string `json:"middleware"`
+
+	// ValidatesBody true if the route has body validation rules.
+	ValidatesBody bool `json:"validatesBody"`
+
+	// ValidatesQuery true if the route has query validation rules.
+	ValidatesQuery bool `json:"validatesQuery"`
+
+	// ValidatesParams true if the route has route parameters validation rules.
+	ValidatesParams bool `json:"validatesParams"`
+}
+
+// HasMiddleware returns true if a middleware of type `T` is applied to the route.
+// This can be used to audit the routes, for example to ensure that they all require authentication.
+func HasMiddleware[T Middleware](info *RouteInfo) bool {
+	if hasMiddleware[T](info.Route.parent.globalMiddleware.middleware) || hasMiddleware[T](info.Route.middleware) {
+		return true
+	}
+	for router := info.Route.parent; router != nil; router = router.parent {
+		if hasMiddleware[T](router.middleware) {
+			return true
+		}
+	}
+	return false
+}
+
+// RouteList a flattened list of routes, returned by `Router.ListRoutes()`.
+type RouteList []*RouteInfo
+
+// ListRoutes returns the description of all the routes registered in this router and
+// its subrouters, recursively. The list is sorted by host, URI and name.
+func (r *Router) ListRoutes() RouteList {
+	list := make(RouteList, 0, len(r.routes))
+	list = r.appendRouteInfo(list)
+	slices.SortStableFunc(list, func(a, b *RouteInfo) int {
+		return cmp.Or(
+			cmp.Compare(a.Host, b.Host),
+			cmp.Compare(a.URI, b.URI),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return list
+}
+
+func (r *Router) appendRouteInfo(list RouteList) RouteList {
+	for _, route := range r.routes {
+		list = append(list, newRouteInfo(route))
+	}
+	for _, subrouter := range r.subrouters {
+		list = subrouter.appendRouteInfo(list)
+	}
+	return list
+}
+
+func newRouteInfo(route *Route) *RouteInfo {
+	info := &RouteInfo{
+		Route:      route,
+		Name:       route.name,
+		URI:        route.GetFullURI(),
+		Host:       route.GetHost(),
+		Methods:    route.GetMethods(),
+		Meta:       map[string]any{},
+		Middleware: []string{},
+	}
+
+	routers := []*Router{}
+	for router := route.parent; router != nil; router = router.parent {
+		routers = append(routers, router)
+	}
+	slices.Reverse(routers)
+
+	middleware := route.parent.globalMiddleware.GetMiddleware()
+	for _, router := range routers {
+		maps.Copy(info.Meta, router.Meta)
+		middleware = append(middleware, router.middleware...)
+	}
+	maps.Copy(info.Meta, route.Meta)
+	middleware = append(middleware, route.middleware...)
+
+	for _, m := range middleware {
+		info.Middleware = append(info.Middleware, fmt.Sprintf("%T", m))
+	}
+
+	if validation := findMiddleware[*validateRequestMiddleware](route.middleware); validation != nil {
+		info.ValidatesBody = validation.BodyRules != nil
+		info.ValidatesQuery = validation.QueryRules != nil
+		info.ValidatesParams = validation.ParamsRules != nil
+	}
+	return info
+}
+
+// WriteJSON writes the list as an indented JSON array. Returns an error if
+// one of the meta values cannot be marshaled.
+func (l RouteList) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return errors.New(encoder.Encode(l))
+}
+
+// WriteTable writes the list as a human-readable table. Only the keys of the meta are displayed.
+func (l RouteList) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "METHODS\tURI\tNAME\tMIDDLEWARE\tVALIDATION\tMETA"); err != nil {
+		return errors.New(err)
+	}
+	for _, info := range l {
+		validation := make([]string, 0, 3)
+		if info.ValidatesBody {
+			validation = append(validation, "body")
+		}
+		if info.ValidatesQuery {
+			validation = append(validation, "query")
+		}
+		if info.ValidatesParams {
+			validation = append(validation, "params")
+		}
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			strings.Join(info.Methods, "|"),
+			info.Host+info.URI,
+			tableCell(info.Name),
+			tableCell(strings.Join(info.Middleware, ", ")),
+			tableCell(strings.Join(validation, ", ")),
+			tableCell(strings.Join(slices.Sorted(maps.Keys(info.Meta)), ", ")),
+		)
+		if err != nil {
+			return errors.New(err)
+		}
+	}
+	return errors.New(tw.Flush())
+}
+
+func tableCell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,144 @@
+package goyave
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5/validation"
+)
+
+func prepareRouteListTest() (*Router, []*Route) {
+	router := prepareRouterTest()
+	router.SetMeta("root-meta", "root")
+	rules := func(_ *Request) validation.RuleSet { return validation.RuleSet{} }
+
+	api := router.Subrouter("/api").SetMeta("api-meta", 1)
+	api.Middleware(&testMiddleware{key: "api"})
+	users := api.Subrouter("/users")
+	routes := []*Route{
+		users.Get("/{id}", nil).Name("users.show").SetMeta("api-meta", 2).ValidateParams(rules),
+		users.Post("/", nil).Name("users.store").ValidateBody(rules),
+		router.Get("/", nil).Name("root"),
+		api.Get("/search", nil).ValidateQuery(rules).Middleware(&testMiddleware{key: "route"}),
+		router.Host("{tenant}.example.com").Get("/", nil).Name("tenant.root"),
+	}
+	return router, routes
+}
+
+func TestListRoutes(t *testing.T) {
+	t.Run("ListRoutes", func(t *testing.T) {
+		router, routes := prepareRouteListTest()
+		list := router.ListRoutes()
+
+		expected := RouteList{
+			{
+				Route:      routes[2],
+				Name:       "root",
+				URI:        "/",
+				Methods:    []string{http.MethodGet, http.MethodHead},
+				Middleware: []string{"*goyave.recoveryMiddleware", "*goyave.languageMiddleware"},
+				Meta:       map[string]any{"root-meta": "root"},
+			},
+			{
+				Route:      routes[3],
+				URI:        "/api/search",
+				Methods:    []string{http.MethodGet, http.MethodHead},
+				Middleware: []string{"*goyave.recoveryMiddleware", "*goyave.languageMiddleware", "*goyave.testMiddleware", "*goyave.validateRequestMiddleware", "*goyave.testMiddleware"},
+				Meta:       map[string]any{"root-meta": "root", "api-meta": 1},
+
+				ValidatesQuery: true,
+			},
+			{
+				Route:      routes[1],
+				Name:       "users.store",
+				URI:        "/api/users",
+				Methods:    []string{http.MethodPost},
+				Middleware: []string{"*goyave.recoveryMiddleware", "*goyave.languageMiddleware", "*goyave.testMiddleware", "*goyave.validateRequestMiddleware"},
+				Meta:       map[string]any{"root-meta": "root", "api-meta": 1},
+
+				ValidatesBody: true,
+			},
+			{
+				Route:      routes[0],
+				Name:       "users.show",
+				URI:        "/api/users/{id}",
+				Methods:    []string{http.MethodGet, http.MethodHead},
+				Middleware: []string{"*goyave.recoveryMiddleware", "*goyave.languageMiddleware", "*goyave.testMiddleware", "*goyave.validateRequestMiddleware"},
+				Meta:       map[string]any{"root-meta": "root", "api-meta": 2},
+
+				ValidatesParams: true,
+			},
+			{
+				Route:      routes[4],
+				Name:       "tenant.root",
+				URI:        "/",
+				Host:       "{tenant}.example.com",
+				Methods:    []string{http.MethodGet, http.MethodHead},
+				Middleware: []string{"*goyave.recoveryMiddleware", "*goyave.languageMiddleware"},
+				Meta:       map[string]any{"root-meta": "root"},
+			},
+		}
+		assert.Equal(t, expected, list)
+	})
+
+	t.Run("HasMiddleware", func(t *testing.T) {
+		router, _ := prepareRouteListTest()
+		list := router.ListRoutes()
+
+		assert.True(t, HasMiddleware[*recoveryMiddleware](list[0]))
+		assert.False(t, HasMiddleware[*testMiddleware](list[0]))
+		assert.True(t, HasMiddleware[*testMiddleware](list[1]))
+		assert.True(t, HasMiddleware[*testMiddleware](list[3]))
+		assert.False(t, HasMiddleware[*corsMiddleware](list[3]))
+	})
+
+	t.Run("WriteTable", func(t *testing.T) {
+		router, _ := prepareRouteListTest()
+		buf := &bytes.Buffer{}
+		require.NoError(t, router.ListRoutes().WriteTable(buf))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 6)
+		assert.Equal(t, []string{"METHODS", "URI", "NAME", "MIDDLEWARE", "VALIDATION", "META"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"GET|HEAD", "/", "root", "*goyave.recoveryMiddleware,", "*goyave.languageMiddleware", "-", "root-meta"}, strings.Fields(lines[1]))
+		assert.Equal(t, []string{"POST", "/api/users", "users.store", "*goyave.recoveryMiddleware,", "*goyave.languageMiddleware,", "*goyave.testMiddleware,", "*goyave.validateRequestMiddleware", "body", "api-meta,", "root-meta"}, strings.Fields(lines[3]))
+		assert.True(t, strings.HasPrefix(lines[5], "GET|HEAD  {tenant}.example.com/  tenant.root"))
+	})
+
+	t.Run("WriteJSON", func(t *testing.T) {
+		router := prepareRouterTest()
+		router.Get("/users/{id}", nil).Name("users.show").SetMeta("key", "value")
+		buf := &bytes.Buffer{}
+		require.NoError(t, router.ListRoutes().WriteJSON(buf))
+
+		expected := `[
+  {
+    "meta": {
+      "key": "value"
+    },
+    "name": "users.show",
+    "uri": "/users/{id}",
+    "methods": [
+      "GET",
+      "HEAD"
+    ],
+    "middleware": [
+      "*goyave.recoveryMiddleware",
+      "*goyave.languageMiddleware"
+    ],
+    "validatesBody": false,
+    "validatesQuery": false,
+    "validatesParams": false
+  }
+]
+`
+		assert.Equal(t, expected, buf.String())
+
+		router.Get("/invalid", nil).SetMeta("key", func() {})
+		require.Error(t, router.ListRoutes().WriteJSON(&bytes.Buffer{}))
+	})
+}
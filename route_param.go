@@ -0,0 +1,82 @@
+package goyave
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/google/uuid"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// ParamType the types route parameters can be converted to using `Param`.
+type ParamType interface {
+	~string | ~bool |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 |
+		uuid.UUID
+}
+
+// Param returns the route parameter identified by the given name, converted to `T`.
+// Returns an error if the request doesn't have this parameter or if it cannot
+// be converted (e.g. if the value overflows `T`).
+//
+// If the route parameters were validated (see `Route.ValidateParams()`) and the
+// converted value is of type `T`, this value is returned as is.
+//
+// Use this with typed route parameters so the conversion can only fail in
+// exceptional cases:
+//
+//	router.Get("/articles/{id:uint}", handler)
+//
+//	id, err := goyave.Param[uint](request, "id")
+func Param[T ParamType](request *Request, name string) (T, error) {
+	var result T
+	if params, ok := request.Extra[ExtraValidatedParams{}].(map[string]any); ok {
+		if v, ok := params[name].(T); ok {
+			return v, nil
+		}
+	}
+
+	raw, ok := request.RouteParams[name]
+	if !ok {
+		return result, errors.Errorf("route parameter %q not found", name)
+	}
+
+	if u, ok := any(&result).(*uuid.UUID); ok {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return result, errors.Errorf("invalid route parameter %q: %w", name, err)
+		}
+		*u = id
+		return result, nil
+	}
+
+	var err error
+	value := reflect.ValueOf(&result).Elem()
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(raw)
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(raw, 10, value.Type().Bits())
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(raw, 10, value.Type().Bits())
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(raw, value.Type().Bits())
+		value.SetFloat(f)
+	}
+	if err != nil {
+		var zero T
+		return zero, errors.Errorf("invalid route parameter %q: %w", name, err)
+	}
+	return result, nil
+}
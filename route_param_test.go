@@ -0,0 +1,111 @@
+package goyave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testParamType int64
+
+func TestParam(t *testing.T) {
+	request := NewRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+	request.RouteParams = map[string]string{
+		"id":       "123",
+		"negative": "-5",
+		"large":    "300",
+		"float":    "1.5",
+		"bool":     "true",
+		"slug":     "lawn-mower",
+		"uuid":     "3bbcee75-cecc-5b56-8031-b6641c1ed1f1",
+	}
+
+	t.Run("string", func(t *testing.T) {
+		v, err := Param[string](request, "slug")
+		require.NoError(t, err)
+		assert.Equal(t, "lawn-mower", v)
+	})
+
+	t.Run("int", func(t *testing.T) {
+		v, err := Param[int](request, "id")
+		require.NoError(t, err)
+		assert.Equal(t, 123, v)
+
+		n, err := Param[int64](request, "negative")
+		require.NoError(t, err)
+		assert.Equal(t, int64(-5), n)
+
+		typed, err := Param[testParamType](request, "id")
+		require.NoError(t, err)
+		assert.Equal(t, testParamType(123), typed)
+	})
+
+	t.Run("uint", func(t *testing.T) {
+		v, err := Param[uint](request, "id")
+		require.NoError(t, err)
+		assert.Equal(t, uint(123), v)
+
+		_, err = Param[uint](request, "negative")
+		require.Error(t, err)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		v, err := Param[uint8](request, "large")
+		require.ErrorContains(t, err, `invalid route parameter "large"`)
+		assert.Equal(t, uint8(0), v)
+	})
+
+	t.Run("float", func(t *testing.T) {
+		v, err := Param[float64](request, "float")
+		require.NoError(t, err)
+		assert.InEpsilon(t, 1.5, v, 0)
+	})
+
+	t.Run("bool", func(t *testing.T) {
+		v, err := Param[bool](request, "bool")
+		require.NoError(t, err)
+		assert.True(t, v)
+	})
+
+	t.Run("uuid", func(t *testing.T) {
+		v, err := Param[uuid.UUID](request, "uuid")
+		require.NoError(t, err)
+		assert.Equal(t, uuid.MustParse("3bbcee75-cecc-5b56-8031-b6641c1ed1f1"), v)
+
+		_, err = Param[uuid.UUID](request, "slug")
+		require.Error(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Param[int](request, "slug")
+		require.ErrorContains(t, err, `invalid route parameter "slug"`)
+	})
+
+	t.Run("validated", func(t *testing.T) {
+		request := NewRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+		request.RouteParams = map[string]string{"id": "123", "slug": "lawn-mower"}
+		request.Extra[ExtraValidatedParams{}] = map[string]any{"id": 456, "slug": "lawn-mower"}
+
+		v, err := Param[int](request, "id")
+		require.NoError(t, err)
+		assert.Equal(t, 456, v)
+
+		// Type mismatch: falls back to the raw parameter
+		u, err := Param[uint](request, "id")
+		require.NoError(t, err)
+		assert.Equal(t, uint(123), u)
+
+		slug, err := Param[string](request, "slug")
+		require.NoError(t, err)
+		assert.Equal(t, "lawn-mower", slug)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		_, err := Param[int](request, "unknown")
+		require.ErrorContains(t, err, `route parameter "unknown" not found`)
+	})
+}
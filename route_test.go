@@ -132,6 +132,33 @@ func TestRoute(t *testing.T) {
 		assert.Nil(t, validationMiddleware.QueryRules)
 	})
 
+	t.Run("ValidateParams", func(t *testing.T) {
+		router := prepareRouteTest()
+		route := &Route{
+			parent: router,
+			middlewareHolder: middlewareHolder{
+				middleware: []Middleware{},
+			},
+		}
+
+		route.ValidateQuery(routeTestValidationRules)
+		route.ValidateParams(routeTestValidationRules)
+
+		validationMiddleware := findMiddleware[*validateRequestMiddleware](route.middleware)
+		if !assert.NotNil(t, validationMiddleware) {
+			return
+		}
+		assert.Len(t, route.middleware, 1)
+		assert.NotNil(t, validationMiddleware.ParamsRules)
+		assert.NotNil(t, validationMiddleware.QueryRules)
+		assert.Nil(t, validationMiddleware.BodyRules)
+
+		// Replace params validation
+		route.ValidateParams(nil)
+		assert.Nil(t, validationMiddleware.ParamsRules)
+		assert.NotNil(t, validationMiddleware.QueryRules)
+	})
+
 	t.Run("CORS", func(t *testing.T) {
 		router := prepareRouteTest()
 		route := &Route{
@@ -235,6 +262,27 @@ func TestRoute(t *testing.T) {
 		assert.Equal(t, "http://127.0.0.1:8080/product/123/keyboard/accessories", uri)
 	})
 
+	t.Run("BuildURL_host", func(t *testing.T) {
+		router := prepareRouteTest()
+		subrouter := router.Host("{tenant}.{domain:example\\.(?:com|org)}").Subrouter("/product/{id:[0-9+]}")
+		route := subrouter.Route([]string{http.MethodGet}, "/{name}", nil)
+
+		assert.Equal(t, "{tenant}.{domain:example\\.(?:com|org)}", route.GetHost())
+		assert.Equal(t, "http://acme.example.org:8080/product/123/keyboard", route.BuildURL("acme", "example.org", "123", "keyboard"))
+		assert.Equal(t, "http://acme.example.org:8080/product/123/keyboard", route.BuildProxyURL("acme", "example.org", "123", "keyboard"))
+		assert.Equal(t, "/product/123/keyboard", route.BuildURI("123", "keyboard"))
+		assert.Panics(t, func() {
+			route.BuildURL("123", "keyboard")
+		})
+
+		router.server.config.Set("server.proxy.host", "proxy.example.com")
+		router.server.config.Set("server.proxy.protocol", "https")
+		router.server.config.Set("server.proxy.port", 443)
+		router.server.config.Set("server.proxy.base", "/base")
+		router.server.refreshURLs()
+		assert.Equal(t, "https://acme.example.org/base/product/123/keyboard", route.BuildProxyURL("acme", "example.org", "123", "keyboard"))
+	})
+
 	t.Run("GetFullURI", func(t *testing.T) {
 		router := prepareRouteTest()
 		subrouter := router.Subrouter("/product").Subrouter("/{id:[0-9+]}")
@@ -271,11 +319,11 @@ func TestRoute(t *testing.T) {
 		}{
 			{route: route1, method: http.MethodGet, uri: "/product/33", expectedResult: true, expectedParameters: map[string]string{"id": "33"}, expectedError: nil},
 			{route: route1, method: http.MethodPost, uri: "/product/33", expectedResult: true, expectedParameters: map[string]string{"id": "33"}, expectedError: nil},
-			{route: route1, method: http.MethodPut, uri: "/product/33", expectedResult: false, expectedParameters: nil, expectedError: errMatchMethodNotAllowed},
-			{route: route1, method: http.MethodGet, uri: "/product/test", expectedResult: false, expectedParameters: nil, expectedError: errMatchNotFound},
+			{route: route1, method: http.MethodPut, uri: "/product/33", expectedResult: false, expectedParameters: nil, expectedError: ErrMatchMethodNotAllowed},
+			{route: route1, method: http.MethodGet, uri: "/product/test", expectedResult: false, expectedParameters: nil, expectedError: ErrMatchNotFound},
 			{route: route2, method: http.MethodGet, uri: "/product/666/test", expectedResult: true, expectedParameters: map[string]string{"id": "666", "name": "test"}, expectedError: nil},
 			{route: route3, method: http.MethodGet, uri: "/categories/lawn-mower/asc", expectedResult: true, expectedParameters: map[string]string{"category": "lawn-mower", "sort": "asc"}, expectedError: nil},
-			{route: route3, method: http.MethodGet, uri: "/categories/lawn-mower/notasc", expectedResult: false, expectedParameters: nil, expectedError: errMatchNotFound},
+			{route: route3, method: http.MethodGet, uri: "/categories/lawn-mower/notasc", expectedResult: false, expectedParameters: nil, expectedError: ErrMatchNotFound},
 			{route: route4, method: http.MethodGet, uri: "/product", expectedResult: true, expectedParameters: nil, expectedError: nil},
 		}
 
@@ -291,11 +339,11 @@ func TestRoute(t *testing.T) {
 		t.Run("err_not_overridden", func(t *testing.T) {
 			match := routeMatch{currentPath: "/product/33"}
 			route1.match(http.MethodPut, &match)
-			assert.Equal(t, errMatchMethodNotAllowed, match.err)
+			assert.Equal(t, ErrMatchMethodNotAllowed, match.err)
 
 			match.currentPath = "/product/test"
 			route1.match(http.MethodGet, &match)
-			assert.Equal(t, errMatchMethodNotAllowed, match.err)
+			assert.Equal(t, ErrMatchMethodNotAllowed, match.err)
 		})
 	})
 }
@@ -3,9 +3,12 @@ package goyave
 import (
 	"errors"
 	"io/fs"
+	"net"
 	"net/http"
+	"net/netip"
 	"regexp"
 	"strings"
+	"time"
 
 	"maps"
 	"slices"
@@ -17,18 +20,22 @@ import (
 
 // Common route meta keys.
 const (
-	MetaCORS = "goyave.cors"
+	MetaCORS    = "goyave.cors"
+	MetaTimeout = "goyave.timeout"
 )
 
 // Special route names.
 const (
 	RouteMethodNotAllowed = "goyave.method-not-allowed"
 	RouteNotFound         = "goyave.not-found"
+	RouteOptions          = "goyave.options"
 )
 
 var (
-	errMatchMethodNotAllowed = errors.New("method not allowed for this route")
-	errMatchNotFound         = errors.New("no match for this URI")
+	// ErrMatchMethodNotAllowed the reason of a failed match when routes match the path but not the method.
+	ErrMatchMethodNotAllowed = errors.New("method not allowed for this route")
+	// ErrMatchNotFound the reason of a failed match when no route matches the path.
+	ErrMatchNotFound = errors.New("no match for this URI")
 
 	methodNotAllowedRoute = newRoute(func(response *Response, _ *Request) {
 		response.Status(http.StatusMethodNotAllowed)
@@ -36,6 +43,9 @@ var (
 	notFoundRoute = newRoute(func(response *Response, _ *Request) {
 		response.Status(http.StatusNotFound)
 	}, RouteNotFound)
+	optionsRoute = newRoute(func(response *Response, _ *Request) {
+		response.Status(http.StatusNoContent)
+	}, RouteOptions)
 )
 
 // Handler responds to an HTTP request.
@@ -54,14 +64,69 @@ type routeMatch struct {
 	parameters  map[string]string
 	err         error
 	currentPath string
+
+	// allowedMethods the methods of the routes matching the path but not the method.
+	allowedMethods []string
+
+	// httpRequest and trustedProxies are used to resolve the requested host
+	// the first time a router matching on host is checked.
+	httpRequest    *http.Request
+	trustedProxies []netip.Prefix
+	forwarded      *forwardedInfo
+	host           string
 }
 
+// mergeParams never modifies the current parameters map so it can be restored
+// if the router that merged the parameters is eventually rejected.
 func (rm *routeMatch) mergeParams(params map[string]string) {
 	if rm.parameters == nil {
 		rm.parameters = params
 		return
 	}
-	maps.Copy(rm.parameters, params)
+	merged := make(map[string]string, len(rm.parameters)+len(params))
+	maps.Copy(merged, rm.parameters)
+	maps.Copy(merged, params)
+	rm.parameters = merged
+}
+
+// getHost returns the requested host, in lower case and without port.
+func (rm *routeMatch) getHost() string {
+	if rm.forwarded == nil {
+		if rm.httpRequest == nil {
+			return rm.host
+		}
+		rm.forwarded = resolveForwarded(rm.trustedProxies, rm.httpRequest)
+		rm.host = normalizeHost(rm.forwarded.host)
+	}
+	return rm.host
+}
+
+// normalizeHost removes the port, the IPv6 brackets and the trailing dot of
+// fully qualified domain names from the given host, and converts it to lower case.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	return strings.ToLower(host)
+}
+
+func (rm *routeMatch) addAllowedMethods(methods []string) {
+	for _, m := range methods {
+		if !slices.Contains(rm.allowedMethods, m) {
+			rm.allowedMethods = append(rm.allowedMethods, m)
+		}
+	}
+}
+
+// allow returns the methods listed in the "Allow" header of a "Method Not Allowed" or
+// automatic "OPTIONS" response. "OPTIONS" is always allowed as it is answered automatically.
+func (rm *routeMatch) allow() []string {
+	methods := rm.allowedMethods
+	if !slices.Contains(methods, http.MethodOptions) {
+		methods = append(slices.Clip(methods), http.MethodOptions)
+	}
+	return methods
 }
 
 func (rm *routeMatch) trimCurrentPath(fullMatch string) {
@@ -82,10 +147,19 @@ type Router struct {
 	middlewareHolder
 	globalMiddleware *middlewareHolder
 
+	// host the compiled host pattern if this router matches on host. See `Router.Host()`.
+	host        *parameterizable
+	hostPattern string
+
+	matchers []RequestMatcher
+
 	prefix     string
 	routes     []*Route
 	subrouters []*Router
 
+	routeTree     treeNode[*Route]
+	subrouterTree treeNode[*Router]
+
 	slashCount int
 }
 
@@ -215,6 +289,11 @@ func (r *Router) GlobalMiddleware(middleware ...Middleware) *Router {
 	return r
 }
 
+// GetGlobalMiddleware returns a copy of the global middleware of the main Router.
+func (r *Router) GetGlobalMiddleware() []Middleware {
+	return r.globalMiddleware.GetMiddleware()
+}
+
 // Middleware apply one or more middleware to the route group.
 func (r *Router) Middleware(middleware ...Middleware) *Router {
 	if r.middleware == nil {
@@ -227,6 +306,14 @@ func (r *Router) Middleware(middleware ...Middleware) *Router {
 	return r
 }
 
+// Matcher add one or more conditions the request must satisfy for this router to match.
+// If one of them is not satisfied, the next subrouter or route matching the path is tried.
+// See `RequestMatcher`.
+func (r *Router) Matcher(matchers ...RequestMatcher) *Router {
+	r.matchers = append(r.matchers, matchers...)
+	return r
+}
+
 // CORS set the CORS options for this route group.
 // If the options are not `nil`, the CORS middleware is automatically added globally.
 // To disable CORS for this router, subrouters and routes, give `nil` options.
@@ -243,6 +330,35 @@ func (r *Router) CORS(options *cors.Options) *Router {
 	return r
 }
 
+// Timeout set the maximum duration of the requests handled by this route group.
+// If the timeout is strictly positive, the timeout middleware is automatically added globally.
+//
+// The timeout is applied as a deadline to the request's context: it propagates to database
+// queries and outgoing requests using this context. The handler (and the middleware registered
+// after the timeout middleware) is executed in a separate goroutine. If the deadline is exceeded
+// before the handler writes the response header, "503 Service Unavailable" is sent right away
+// and the corresponding status handler is executed, with the request's original context.
+// If the handler returns an error caused by a deadline (for example a database query canceled)
+// without writing the response, the response is replaced by "504 Gateway Timeout".
+// If the handler already wrote the response header, subsequent writes fail with
+// `context.DeadlineExceeded`.
+//
+// Handlers are not interrupted: they are expected to respect the request's context.
+// A handler overrunning its timeout keeps running in the background. Everything it
+// writes is discarded and the changes it makes to the request are not visible to the
+// previous middleware.
+//
+// The timeout of the closest route or router applies. Subrouters and routes can therefore
+// have a longer timeout than their parent. To disable the timeout for this router, subrouters
+// and routes, give a zero duration.
+func (r *Router) Timeout(timeout time.Duration) *Router {
+	r.Meta[MetaTimeout] = timeout
+	if timeout > 0 && !hasMiddleware[*timeoutMiddleware](r.globalMiddleware.middleware) {
+		r.GlobalMiddleware(&timeoutMiddleware{})
+	}
+	return r
+}
+
 // StatusHandler set a handler for responses with an empty body.
 // The handler will be automatically executed if the request's life-cycle reaches its end
 // and nothing has been written in the response body.
@@ -275,16 +391,100 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	match := routeMatch{currentPath: req.URL.Path}
-	r.match(req.Method, &match)
+	match := routeMatch{}
+	r.matchHTTPRequest(req, &match)
 	r.requestHandler(&match, w, req)
 }
 
-// TODO export RouteMatch and add Match with string param function
+// RouteMatch the result of matching a request against a router.
+type RouteMatch struct {
+	// Route the matched route. If the match failed, this is a special route
+	// named `RouteNotFound` or `RouteMethodNotAllowed`. If the request uses the "OPTIONS"
+	// method and no route handles it, this is the special route named `RouteOptions`.
+	Route *Route
+
+	// Params the route parameters, including the parameters of the parent routers and hosts.
+	Params map[string]string
+
+	// Err the reason of a failed match: `ErrMatchNotFound` or `ErrMatchMethodNotAllowed`.
+	// `nil` if the match succeeded.
+	Err error
+
+	// AllowedMethods the methods allowed for the path, as listed in the "Allow" header.
+	// Only set if the match failed with `ErrMatchMethodNotAllowed` or for automatic "OPTIONS".
+	AllowedMethods []string
+}
+
+// Match find the route matching the given method and path (which can include a query),
+// the same way `ServeHTTP()` would. This is useful for testing the routing table, or to
+// dispatch a request internally. Returns `false` if no route matched. In this case
+// `RouteMatch.Err` contains the reason.
+//
+// The request used for matching has no header and no host: routes using matchers
+// may not match and routers matching on host never match. Use `MatchRequest()` instead
+// if you need them.
+func (r *Router) Match(method, path string) (*RouteMatch, bool) {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return &RouteMatch{Route: notFoundRoute, Params: map[string]string{}, Err: ErrMatchNotFound}, false
+	}
+	return r.MatchRequest(req)
+}
+
+// MatchRequest find the route matching the given request, the same way `ServeHTTP()` would.
+// Returns `false` if no route matched. In this case `RouteMatch.Err` contains the reason.
+func (r *Router) MatchRequest(req *http.Request) (*RouteMatch, bool) {
+	match := routeMatch{}
+	r.matchHTTPRequest(req, &match)
+
+	result := &RouteMatch{
+		Route:  match.route,
+		Params: match.parameters,
+	}
+	if result.Params == nil {
+		result.Params = map[string]string{}
+	}
+	switch match.route {
+	case notFoundRoute:
+		result.Err = ErrMatchNotFound
+	case methodNotAllowedRoute:
+		result.Err = ErrMatchMethodNotAllowed
+		result.AllowedMethods = match.allow()
+	case optionsRoute:
+		result.AllowedMethods = match.allow()
+	}
+	return result, result.Err == nil
+}
+
+func (r *Router) matchHTTPRequest(req *http.Request, match *routeMatch) {
+	match.currentPath = req.URL.Path
+	match.httpRequest = req
+	if r.server != nil {
+		match.trustedProxies = r.server.trustedProxies
+	}
+	r.match(req.Method, match)
+	if match.route == methodNotAllowedRoute && req.Method == http.MethodOptions {
+		// No route handles OPTIONS for this path: answer automatically
+		match.route = optionsRoute
+	}
+}
 
 func (r *Router) match(method string, match *routeMatch) bool {
+	var hostParams []string
+	if r.host != nil {
+		hostParams = r.host.regex.FindStringSubmatch(match.getHost())
+		if hostParams == nil {
+			return false
+		}
+	}
+	if !matchRequest(r.matchers, match) {
+		return false
+	}
+
 	// Check if router itself matches
 	var params []string
+	var fullMatch string
+	matched := true
 	if r.regex != nil {
 		i := -1
 		if len(match.currentPath) > 0 {
@@ -294,45 +494,96 @@ func (r *Router) match(method string, match *routeMatch) bool {
 		if i <= 0 {
 			i = len(match.currentPath)
 		}
-		currentPath := match.currentPath[:i]
-		params = r.regex.FindStringSubmatch(currentPath)
-	} else {
-		params = []string{""}
+		fullMatch = match.currentPath[:i]
+		params, matched = r.matchPrefix(fullMatch)
 	}
 
-	if params != nil {
-		match.trimCurrentPath(params[0])
+	if matched {
+		match.trimCurrentPath(fullMatch)
 		if len(params) > 1 {
 			match.mergeParams(r.makeParameters(params))
 		}
 
 		// Check in subrouters first
-		for _, router := range r.subrouters {
+		var subrouters [8]treeEntry[*Router]
+		currentPath, parameters := match.currentPath, match.parameters
+		next := 0
+		for _, entry := range r.subrouterTree.candidates(match.currentPath, true, subrouters[:0]) {
+			if r.skippedMethodNotAllowed(match, next, entry.index) {
+				match.route = methodNotAllowedRoute
+				return true
+			}
+			next = entry.index + 1
+			router := entry.value
 			if router.match(method, match) {
-				if router.prefix == "" && match.route == methodNotAllowedRoute {
-					// This allows route groups with subrouters having empty prefix.
-					continue
+				if router.prefix != "" || match.route != methodNotAllowedRoute {
+					r.mergeHostParams(match, hostParams)
+					return true
 				}
-				return true
+				// This allows route groups with subrouters having empty prefix.
 			}
+			// The rejected subrouter may have trimmed the path or merged parameters.
+			match.currentPath, match.parameters = currentPath, parameters
+		}
+		if r.skippedMethodNotAllowed(match, next, len(r.subrouters)) {
+			match.route = methodNotAllowedRoute
+			return true
 		}
 
 		// Check if any route matches
-		for _, route := range r.routes {
-			if route.match(method, match) {
+		var routes [8]treeEntry[*Route]
+		for _, entry := range r.routeTree.candidates(match.currentPath, false, routes[:0]) {
+			if entry.value.match(method, match) {
+				r.mergeHostParams(match, hostParams)
 				return true
 			}
 		}
 	}
 
-	if errors.Is(match.err, errMatchMethodNotAllowed) {
+	if errors.Is(match.err, ErrMatchMethodNotAllowed) {
 		match.route = methodNotAllowedRoute
 		return true
 	}
 
 	match.route = notFoundRoute
 	// Return true if the subrouter matched so we don't turn back and check other subrouters
-	return params != nil && len(params[0]) > 0
+	return matched && len(fullMatch) > 0
+}
+
+// skippedMethodNotAllowed returns true if one of the subrouters in the given index range,
+// which are not candidates for the current path, would have ended the search with
+// "405 Method Not Allowed" if it had been checked. Once a route matched the path but not
+// the method, the first subrouter whose prefix doesn't match ends the search.
+func (r *Router) skippedMethodNotAllowed(match *routeMatch, from, to int) bool {
+	if !errors.Is(match.err, ErrMatchMethodNotAllowed) {
+		return false
+	}
+	for _, router := range r.subrouters[from:to] {
+		if router.host != nil && !router.host.regex.MatchString(match.getHost()) {
+			continue
+		}
+		if matchRequest(router.matchers, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrefix the router's prefix accepts an optional trailing slash.
+func (r *Router) matchPrefix(path string) ([]string, bool) {
+	params, ok := r.matchURI(path)
+	if !ok && r.segmented && strings.HasSuffix(path, "/") {
+		return r.matchURI(path[:len(path)-1])
+	}
+	return params, ok
+}
+
+// mergeHostParams the host parameters are only merged once a route has been found
+// so they don't leak into the parameters of a route matched by another router.
+func (r *Router) mergeHostParams(match *routeMatch, hostParams []string) {
+	if len(hostParams) > 1 {
+		match.mergeParams(r.host.makeParameters(hostParams, r.host.parameters))
+	}
 }
 
 func nthIndex(str, substr string, n int) int {
@@ -380,11 +631,55 @@ func (r *Router) Subrouter(prefix string) *Router {
 	if prefix != "" {
 		router.compileParameters(router.prefix, false, r.regexCache)
 		router.slashCount = strings.Count(prefix, "/")
+		r.subrouterTree.insert(&router.parameterizable, router.slashCount, router, len(r.subrouters))
+	} else {
+		r.subrouterTree.insertFallback(router, len(r.subrouters))
 	}
 	r.subrouters = append(r.subrouters, router)
 	return router
 }
 
+// Host create a new sub-router with an empty prefix matching only the requests
+// whose host matches the given pattern. The host is resolved using `Request.Host()`,
+// meaning that the forwarding headers of trusted proxies are taken into account.
+// The port is ignored and the host is converted to lower case before matching,
+// so host parameters are always in lower case.
+//
+// Just like URIs, the pattern can contain parameters (e.g. "{tenant}.example.com")
+// which are merged into `Request.RouteParams`. By default, a host parameter matches
+// a single label (`[^.]+`). A custom pattern can be given: "{tenant:[a-z]+}.example.com".
+//
+// Routers matching on host cannot be nested.
+func (r *Router) Host(pattern string) *Router {
+	if parent := r.hostRouter(); parent != nil {
+		panic(errorutil.Errorf("cannot create host router %q: parent router already matches host %q", pattern, parent.hostPattern))
+	}
+	router := r.Subrouter("")
+	router.hostPattern = pattern
+	router.host = &parameterizable{}
+	router.host.compileHost(pattern, r.regexCache)
+	return router
+}
+
+// GetHost returns the host pattern this router matches against, inherited from its
+// parent routers. Returns an empty string if the router matches any host.
+func (r *Router) GetHost() string {
+	if router := r.hostRouter(); router != nil {
+		return router.hostPattern
+	}
+	return ""
+}
+
+// hostRouter returns the closest router matching on host, starting from this router.
+func (r *Router) hostRouter() *Router {
+	for router := r; router != nil; router = router.parent {
+		if router.host != nil {
+			return router
+		}
+	}
+	return nil
+}
+
 // Group create a new sub-router with an empty prefix.
 func (r *Router) Group() *Router {
 	return r.Subrouter("")
@@ -395,11 +690,18 @@ func (r *Router) Group() *Router {
 // Multiple methods can be passed.
 //
 // If the route matches the "GET" method, the "HEAD" method is automatically added
-// to the matcher if it's missing.
+// to the matcher if it's missing. The handler runs like it would for a "GET" request
+// so the response headers (including "Content-Length") are the same. The body
+// is then discarded by the HTTP server.
 //
 // If the router has the CORS middleware, the "OPTIONS" method is automatically added
 // to the matcher if it's missing, so it allows preflight requests.
 //
+// If no route handles the "OPTIONS" method for the requested path, the router
+// answers automatically with "204 No Content" and the "Allow" header listing the
+// methods of the routes matching the path. This header is also set on
+// "405 Method Not Allowed" responses.
+//
 // Returns the generated route.
 func (r *Router) Route(methods []string, uri string, handler Handler) *Route {
 	return r.registerRoute(methods, uri, handler)
@@ -474,6 +776,7 @@ func (r *Router) registerRoute(methods []string, uri string, handler Handler) *R
 		Meta:    make(map[string]any),
 	}
 	route.compileParameters(route.uri, true, r.regexCache)
+	r.routeTree.insert(&route.parameterizable, len(route.segments), route, len(r.routes))
 	r.routes = append(r.routes, route)
 	return route
 }
@@ -489,12 +792,17 @@ func (r *Router) Controller(controller Registrer) *Router {
 func (r *Router) requestHandler(match *routeMatch, w http.ResponseWriter, rawRequest *http.Request) {
 	request := NewRequest(rawRequest)
 	request.Route = match.route
+	request.trustedProxies = r.server.trustedProxies
+	request.forwarded = match.forwarded // Already resolved if a router matched on host
 	if match.parameters == nil {
 		request.RouteParams = map[string]string{}
 	} else {
 		request.RouteParams = match.parameters
 	}
 	response := NewResponse(r.server, request, w)
+	if match.route == methodNotAllowedRoute || match.route == optionsRoute {
+		response.Header().Set("Allow", strings.Join(match.allow(), ", "))
+	}
 	handler := match.route.handler
 
 	// Route-specific middleware is executed after router middleware
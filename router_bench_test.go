@@ -1,6 +1,7 @@
 package goyave
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,3 +26,70 @@ func BenchmarkServeHTTP(b *testing.B) {
 		s.router.ServeHTTP(httptest.NewRecorder(), req)
 	}
 }
+
+// prepareLargeRouter registers a few hundred routes: resources with subrouters
+// and constrained parameters, as well as flat static and parameterized routes.
+func prepareLargeRouter(b *testing.B) *Router {
+	s, err := New(Options{Config: config.LoadDefault()})
+	if err != nil {
+		b.Fatal(err)
+	}
+	router := NewRouter(s)
+	handler := func(_ *Response, _ *Request) {}
+	for i := range 50 {
+		resource := router.Subrouter(fmt.Sprintf("/resource%d", i))
+		resource.Get("/", handler)
+		resource.Post("/", handler)
+		resource.Get("/{id:[0-9]+}", handler)
+		resource.Patch("/{id:[0-9]+}", handler)
+		resource.Delete("/{id:[0-9]+}", handler)
+		resource.Get("/{id:[0-9]+}/comments/{commentID}", handler)
+	}
+	for i := range 100 {
+		router.Get(fmt.Sprintf("/static/page%d", i), handler)
+		router.Get(fmt.Sprintf("/users%d/{name}/profile", i), handler)
+	}
+	router.ClearRegexCache()
+	return router
+}
+
+func benchmarkMatch(b *testing.B, method, path string) {
+	router := prepareLargeRouter(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		match := routeMatch{currentPath: path}
+		router.match(method, &match)
+	}
+}
+
+func BenchmarkMatchLargeStatic(b *testing.B) {
+	benchmarkMatch(b, http.MethodGet, "/static/page99")
+}
+
+func BenchmarkMatchLargeSubrouterParams(b *testing.B) {
+	benchmarkMatch(b, http.MethodGet, "/resource49/123/comments/456")
+}
+
+func BenchmarkMatchLargeParams(b *testing.B) {
+	benchmarkMatch(b, http.MethodGet, "/users99/johndoe/profile")
+}
+
+func BenchmarkMatchLargeMethodNotAllowed(b *testing.B) {
+	benchmarkMatch(b, http.MethodPut, "/resource49/123")
+}
+
+func BenchmarkMatchLargeNotFound(b *testing.B) {
+	benchmarkMatch(b, http.MethodGet, "/not/found")
+}
+
+func BenchmarkServeHTTPLarge(b *testing.B) {
+	router := prepareLargeRouter(b)
+	req := httptest.NewRequest(http.MethodGet, "/resource49/123/comments/456", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
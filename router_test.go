@@ -164,6 +164,12 @@ func TestRouter(t *testing.T) {
 		for _, m := range router.globalMiddleware.middleware {
 			assert.NotNil(t, m.Server())
 		}
+
+		global := router.GetGlobalMiddleware()
+		assert.Equal(t, router.globalMiddleware.middleware, global)
+		global[0] = nil
+		assert.NotNil(t, router.globalMiddleware.middleware[0]) // Copy
+		assert.Equal(t, global[1:], router.Subrouter("/sub").GetGlobalMiddleware()[1:])
 	})
 
 	t.Run("Middleware", func(t *testing.T) {
@@ -242,6 +248,284 @@ func TestRouter(t *testing.T) {
 		assert.Equal(t, slash, group)
 	})
 
+	t.Run("Host", func(t *testing.T) {
+		router := prepareRouterTest()
+		tenant := router.Host("{tenant}.example.com")
+		assert.Equal(t, router, tenant.parent)
+		assert.Empty(t, tenant.prefix)
+		assert.Equal(t, []*Router{tenant}, router.subrouters)
+		assert.Equal(t, "{tenant}.example.com", tenant.GetHost())
+		assert.Equal(t, "{tenant}.example.com", tenant.Subrouter("/api").GetHost())
+		assert.Equal(t, []string{"tenant"}, tenant.host.parameters)
+		assert.Empty(t, router.GetHost())
+
+		assert.Panics(t, func() {
+			tenant.Subrouter("/api").Host("other.example.com")
+		})
+		assert.Panics(t, func() {
+			router.Host("{tenant:(a|b)}.example.com")
+		})
+	})
+
+	t.Run("match_host", func(t *testing.T) {
+		router := prepareRouterTest()
+		router.Get("/", nil).Name("root")
+
+		tenant := router.Host("{tenant}.example.com")
+		tenant.Get("/", nil).Name("tenant.root")
+		api := tenant.Subrouter("/api")
+		api.Get("/users/{id}", nil).Name("tenant.users.show")
+		tenant.Post("/only-tenant", nil).Name("tenant.only")
+
+		admin := router.Host("admin.{region:(?:eu|us)}.example.com")
+		admin.Get("/", nil).Name("admin.root")
+
+		router.Get("/only-tenant", nil).Name("only-tenant.get")
+
+		cases := []struct {
+			expectedParams map[string]string
+			host           string
+			path           string
+			method         string
+			expectedRoute  string
+		}{
+			{host: "acme.example.com", path: "/", method: http.MethodGet, expectedRoute: "tenant.root", expectedParams: map[string]string{"tenant": "acme"}},
+			{host: "ACME.Example.com", path: "/", method: http.MethodGet, expectedRoute: "tenant.root", expectedParams: map[string]string{"tenant": "acme"}},
+			{host: "acme.example.com", path: "/api/users/3", method: http.MethodGet, expectedRoute: "tenant.users.show", expectedParams: map[string]string{"tenant": "acme", "id": "3"}},
+			{host: "admin.eu.example.com", path: "/", method: http.MethodGet, expectedRoute: "admin.root", expectedParams: map[string]string{"region": "eu"}},
+			{host: "admin.asia.example.com", path: "/", method: http.MethodGet, expectedRoute: "root"},
+			{host: "a.b.example.com", path: "/", method: http.MethodGet, expectedRoute: "root"},
+			{host: "example.com", path: "/", method: http.MethodGet, expectedRoute: "root"},
+			{host: "example.org", path: "/api/users/3", method: http.MethodGet, expectedRoute: RouteNotFound},
+			{host: "acme.example.com", path: "/only-tenant", method: http.MethodPost, expectedRoute: "tenant.only", expectedParams: map[string]string{"tenant": "acme"}},
+			{host: "acme.example.com", path: "/only-tenant", method: http.MethodGet, expectedRoute: "only-tenant.get"}, // Host params don't leak
+			{host: "example.com", path: "/only-tenant", method: http.MethodPost, expectedRoute: RouteMethodNotAllowed},
+		}
+
+		for _, c := range cases {
+			t.Run(fmt.Sprintf("%s_%s_%s", c.method, c.host, strings.ReplaceAll(c.path, "/", "_")), func(t *testing.T) {
+				match := routeMatch{currentPath: c.path, host: normalizeHost(c.host)}
+				router.match(c.method, &match)
+				assert.Equal(t, c.expectedRoute, match.route.name)
+				assert.Equal(t, c.expectedParams, match.parameters)
+			})
+		}
+	})
+
+	t.Run("ServeHTTP_host", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("server.trustedProxies", []string{"192.0.2.1"})
+		server, err := New(Options{Config: cfg})
+		require.NoError(t, err)
+		router := NewRouter(server)
+		router.Host("{tenant}.example.com").Get("/hello", func(response *Response, request *Request) {
+			response.String(http.StatusOK, request.RouteParams["tenant"])
+		})
+
+		cases := []struct {
+			header     http.Header
+			host       string
+			remoteAddr string
+			expected   string
+			status     int
+		}{
+			{host: "acme.example.com", expected: "acme", status: http.StatusOK},
+			{host: "acme.example.com:8080", expected: "acme", status: http.StatusOK},
+			{host: "acme.example.com.", expected: "acme", status: http.StatusOK},
+			{host: "example.com", status: http.StatusNotFound},
+			{host: "localhost", remoteAddr: "192.0.2.1:1234", header: http.Header{"X-Forwarded-Host": {"proxied.example.com"}}, expected: "proxied", status: http.StatusOK},
+			{host: "localhost", remoteAddr: "192.0.2.2:1234", header: http.Header{"X-Forwarded-Host": {"proxied.example.com"}}, status: http.StatusNotFound}, // Untrusted proxy
+		}
+
+		for _, c := range cases {
+			t.Run(c.host, func(t *testing.T) {
+				recorder := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+				req.Host = c.host
+				if c.remoteAddr != "" {
+					req.RemoteAddr = c.remoteAddr
+				}
+				for k, v := range c.header {
+					req.Header[k] = v
+				}
+				router.ServeHTTP(recorder, req)
+				res := recorder.Result()
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, res.Body.Close())
+				require.NoError(t, err)
+				assert.Equal(t, c.status, res.StatusCode)
+				if c.status == http.StatusOK {
+					assert.Equal(t, c.expected, string(body))
+				}
+			})
+		}
+	})
+
+	t.Run("ServeHTTP_allow", func(t *testing.T) {
+		router := prepareRouterTest()
+		// Registered first: a subrouter registered after a group answering
+		// "405 Method Not Allowed" would end the search.
+		router.Subrouter("/cors").CORS(cors.Default()).Get("/", nil)
+		router.Get("/users", func(response *Response, _ *Request) {
+			response.String(http.StatusOK, "hello")
+		})
+		router.Post("/users", nil)
+		router.Group().Delete("/users", nil)
+		router.Options("/custom-options", func(response *Response, _ *Request) {
+			response.Status(http.StatusAccepted)
+		})
+		router.Put("/custom-options", nil)
+
+		cases := []struct {
+			method         string
+			url            string
+			expectedAllow  string
+			expectedBody   string
+			expectedStatus int
+		}{
+			{method: http.MethodPatch, url: "/users", expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "DELETE, GET, HEAD, POST, OPTIONS", expectedBody: "{\"error\":\"Method Not Allowed\"}\n"},
+			{method: http.MethodOptions, url: "/users", expectedStatus: http.StatusNoContent, expectedAllow: "DELETE, GET, HEAD, POST, OPTIONS"},
+			{method: http.MethodOptions, url: "/custom-options", expectedStatus: http.StatusAccepted},
+			{method: http.MethodPatch, url: "/custom-options", expectedStatus: http.StatusMethodNotAllowed, expectedAllow: "OPTIONS, PUT", expectedBody: "{\"error\":\"Method Not Allowed\"}\n"},
+			{method: http.MethodOptions, url: "/unknown", expectedStatus: http.StatusNotFound, expectedBody: "{\"error\":\"Not Found\"}\n"},
+			{method: http.MethodGet, url: "/users", expectedStatus: http.StatusOK, expectedBody: "hello"},
+		}
+
+		for _, c := range cases {
+			t.Run(c.method+strings.ReplaceAll(c.url, "/", "_"), func(t *testing.T) {
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, httptest.NewRequest(c.method, c.url, nil))
+				res := recorder.Result()
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, res.Body.Close())
+				require.NoError(t, err)
+				assert.Equal(t, c.expectedStatus, res.StatusCode)
+				assert.Equal(t, c.expectedAllow, res.Header.Get("Allow"))
+				assert.Equal(t, c.expectedBody, string(body))
+			})
+		}
+
+		t.Run("head_headers_match_get", func(t *testing.T) {
+			// A real server is needed: only net/http computes the automatic
+			// Content-Length and discards the body of HEAD responses.
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			get, err := server.Client().Get(server.URL + "/users")
+			require.NoError(t, err)
+			getBody, err := io.ReadAll(get.Body)
+			assert.NoError(t, get.Body.Close())
+			require.NoError(t, err)
+
+			head, err := server.Client().Head(server.URL + "/users")
+			require.NoError(t, err)
+			headBody, err := io.ReadAll(head.Body)
+			assert.NoError(t, head.Body.Close())
+			require.NoError(t, err)
+
+			assert.Equal(t, "hello", string(getBody))
+			assert.Empty(t, headBody)
+			assert.Equal(t, get.StatusCode, head.StatusCode)
+			assert.Equal(t, "5", get.Header.Get("Content-Length"))
+			assert.Equal(t, get.Header.Get("Content-Length"), head.Header.Get("Content-Length"))
+			assert.Equal(t, get.Header.Get("Content-Type"), head.Header.Get("Content-Type"))
+		})
+
+		t.Run("cors_preflight", func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodOptions, "/cors", nil)
+			req.Header.Set("Origin", "https://example.org")
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			router.ServeHTTP(recorder, req)
+			res := recorder.Result()
+			assert.NoError(t, res.Body.Close())
+			assert.Equal(t, http.StatusNoContent, res.StatusCode)
+			assert.Empty(t, res.Header.Get("Allow"))
+			assert.NotEmpty(t, res.Header.Get("Access-Control-Allow-Origin"))
+		})
+	})
+
+	t.Run("match_sibling_groups", func(t *testing.T) {
+		// A rejected group must not leave its trimmed path or its parameters
+		// to the next sibling group.
+		router := prepareRouterTest()
+		router.Group().Subrouter("/api").Get("/", nil)
+		group := router.Group()
+		group.Subrouter("/{id:[0-9]+}").Get("/", nil)
+		group.Post("", nil)
+
+		expected := &RouteMatch{Route: methodNotAllowedRoute, Params: map[string]string{}, Err: ErrMatchMethodNotAllowed, AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodOptions}}
+		match, ok := router.Match(http.MethodPost, "/api")
+		assert.False(t, ok)
+		assert.Equal(t, expected, match)
+
+		router = prepareRouterTest()
+		router.Group().Subrouter("/{any0}").Get("/x", nil)
+		router.Group().Subrouter("/other").Get("/", nil)
+
+		match, ok = router.Match(http.MethodPost, "/12/x")
+		assert.False(t, ok)
+		assert.Equal(t, expected, match)
+
+		// Once a group answered "405 Method Not Allowed", the next subrouter ends
+		// the search even if its prefix doesn't match.
+		router = prepareRouterTest()
+		router.Group().Get("/users", nil)
+		router.Subrouter("/other").Get("/", nil)
+		router.Post("/users", nil)
+
+		match, ok = router.Match(http.MethodPost, "/users")
+		assert.False(t, ok)
+		assert.Equal(t, expected, match)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		router := prepareRouterTest()
+		users := router.Subrouter("/users")
+		show := users.Get("/{id:[0-9]+}", nil).Name("users.show")
+		users.Delete("/{id:[0-9]+}", nil)
+		beta := users.Get("/beta", nil).Matcher(HeaderMatcher("X-Beta", "true"))
+		tenant := router.Host("{tenant}.example.com").Get("/dashboard", nil)
+
+		cases := []struct {
+			expected        *RouteMatch
+			method          string
+			path            string
+			expectedSuccess bool
+		}{
+			{method: http.MethodGet, path: "/users/1", expectedSuccess: true, expected: &RouteMatch{Route: show, Params: map[string]string{"id": "1"}}},
+			{method: http.MethodGet, path: "/users/1?query=value", expectedSuccess: true, expected: &RouteMatch{Route: show, Params: map[string]string{"id": "1"}}},
+			{method: http.MethodPost, path: "/users/1", expectedSuccess: false, expected: &RouteMatch{Route: methodNotAllowedRoute, Params: map[string]string{}, Err: ErrMatchMethodNotAllowed, AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions}}},
+			{method: http.MethodOptions, path: "/users/1", expectedSuccess: true, expected: &RouteMatch{Route: optionsRoute, Params: map[string]string{}, AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions}}},
+			{method: http.MethodGet, path: "/users/abc", expectedSuccess: false, expected: &RouteMatch{Route: notFoundRoute, Params: map[string]string{}, Err: ErrMatchNotFound}},
+			{method: http.MethodGet, path: "/users/beta", expectedSuccess: false, expected: &RouteMatch{Route: notFoundRoute, Params: map[string]string{}, Err: ErrMatchNotFound}},
+			{method: http.MethodGet, path: "/dashboard", expectedSuccess: false, expected: &RouteMatch{Route: notFoundRoute, Params: map[string]string{}, Err: ErrMatchNotFound}},
+			{method: "invalid method", path: "/users/1", expectedSuccess: false, expected: &RouteMatch{Route: notFoundRoute, Params: map[string]string{}, Err: ErrMatchNotFound}},
+		}
+
+		for _, c := range cases {
+			t.Run(c.method+strings.ReplaceAll(c.path, "/", "_"), func(t *testing.T) {
+				match, ok := router.Match(c.method, c.path)
+				assert.Equal(t, c.expectedSuccess, ok)
+				assert.Equal(t, c.expected, match)
+			})
+		}
+
+		t.Run("MatchRequest", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/beta", nil)
+			req.Header.Set("X-Beta", "true")
+			match, ok := router.MatchRequest(req)
+			assert.True(t, ok)
+			assert.Equal(t, &RouteMatch{Route: beta, Params: map[string]string{}}, match)
+
+			req = httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+			req.Host = "acme.example.com"
+			match, ok = router.MatchRequest(req)
+			assert.True(t, ok)
+			assert.Equal(t, &RouteMatch{Route: tenant, Params: map[string]string{"tenant": "acme"}}, match)
+		})
+	})
+
 	t.Run("Route", func(t *testing.T) {
 		router := prepareRouterTest()
 
@@ -578,6 +862,16 @@ func TestRouter(t *testing.T) {
 		subrouter.Get("/subroute", nil).Name("multiple-segments.subroute.show")
 		subrouter.Get("/subroute/{name}", nil).Name("multiple-segments.subroute.name")
 
+		// Priority: the first registered route matching wins, even if a
+		// static route registered later matches too.
+		priority := router.Subrouter("/priority")
+		priority.Get("/{name}", nil).Name("priority.param")
+		priority.Get("/static", nil).Name("priority.static")
+		priority.Get("/{id:[0-9]+}/{name}", nil).Name("priority.constrained")
+		priority.Get("/files/{path:.*}", nil).Name("priority.files")
+		priority.Get("/{a}/{b}", nil).Name("priority.wildcard")
+		priority.Get("/dot/{id}.json", nil).Name("priority.dot")
+
 		cases := []struct {
 			path          string
 			method        string
@@ -613,6 +907,14 @@ func TestRouter(t *testing.T) {
 			{path: "/subrouter/value/subroute", method: http.MethodGet, expectedRoute: "multiple-segments.subroute.show"},
 			{path: "/subrouter/value/subroute/", method: http.MethodGet, expectedRoute: RouteNotFound},
 			{path: "/subrouter/value/subroute/johndoe", method: http.MethodGet, expectedRoute: "multiple-segments.subroute.name"},
+			{path: "/priority/static", method: http.MethodGet, expectedRoute: "priority.param"},
+			{path: "/priority/123/abc", method: http.MethodGet, expectedRoute: "priority.constrained"},
+			{path: "/priority/files/abc", method: http.MethodGet, expectedRoute: "priority.files"},
+			{path: "/priority/files/a/b/c", method: http.MethodGet, expectedRoute: "priority.files"},
+			{path: "/priority/abc/def", method: http.MethodGet, expectedRoute: "priority.wildcard"},
+			{path: "/priority/dot/1/json", method: http.MethodGet, expectedRoute: "priority.dot"}, // "." matches any character
+			{path: "/priority/abc/def/ghi", method: http.MethodGet, expectedRoute: RouteNotFound},
+			{path: "/priority", method: http.MethodGet, expectedRoute: RouteNotFound},
 		}
 
 		for _, c := range cases {
@@ -0,0 +1,119 @@
+package goyave
+
+import "strings"
+
+// treeEntry a route or subrouter indexed in a `treeNode`. The index is the position
+// of the value in its parent router, used to preserve the registration priority.
+type treeEntry[T routeMatcher] struct {
+	value T
+	index int
+}
+
+// treeNode a node of the segment trie used by routers to find the routes and
+// subrouters that may match a path without checking every one of them.
+//
+// Each edge corresponds to a path segment. Literal segments are indexed in a map
+// and all the segments containing parameters share the same wildcard child.
+// The tree only selects candidates: they are verified afterwards using their
+// own matcher, which checks the parameters' patterns.
+type treeNode[T routeMatcher] struct {
+	static   map[string]*treeNode[T]
+	wildcard *treeNode[T]
+
+	// entries the values whose URI ends at this node.
+	entries []treeEntry[T]
+
+	// fallback the values whose URI cannot be matched segment by segment. They are
+	// indexed using their leading literal segments and are candidates for any path
+	// going through this node.
+	fallback []treeEntry[T]
+}
+
+// insert the given value in the tree. If `segmentCount` is not equal to the number of
+// segments of the value's URI, the value is inserted as a fallback.
+func (n *treeNode[T]) insert(p *parameterizable, segmentCount int, value T, index int) {
+	entry := treeEntry[T]{value: value, index: index}
+	if !p.segmented || len(p.segments) != segmentCount {
+		node := n
+		for _, literal := range p.literalPrefix {
+			node = node.staticChild(literal)
+		}
+		node.fallback = append(node.fallback, entry)
+		return
+	}
+
+	node := n
+	for _, segment := range p.segments {
+		if segment.kind == segmentLiteral {
+			node = node.staticChild(segment.literal)
+			continue
+		}
+		if node.wildcard == nil {
+			node.wildcard = &treeNode[T]{}
+		}
+		node = node.wildcard
+	}
+	node.entries = append(node.entries, entry)
+}
+
+// insertFallback insert the given value as a candidate for all paths.
+func (n *treeNode[T]) insertFallback(value T, index int) {
+	n.fallback = append(n.fallback, treeEntry[T]{value: value, index: index})
+}
+
+func (n *treeNode[T]) staticChild(literal string) *treeNode[T] {
+	if n.static == nil {
+		n.static = make(map[string]*treeNode[T], 1)
+	}
+	child, ok := n.static[literal]
+	if !ok {
+		child = &treeNode[T]{}
+		n.static[literal] = child
+	}
+	return child
+}
+
+// candidates appends to `dst` the values that may match the given path, sorted
+// in registration order. If `prefix` is true, the values whose URI match the
+// beginning of the path are also returned.
+func (n *treeNode[T]) candidates(path string, prefix bool, dst []treeEntry[T]) []treeEntry[T] {
+	if path != "" && path[0] != '/' {
+		dst = append(dst, n.fallback...)
+	} else {
+		dst = n.collect(path, prefix, dst)
+	}
+
+	// Insertion sort: there are very few candidates in most cases
+	for i := 1; i < len(dst); i++ {
+		for j := i; j > 0 && dst[j].index < dst[j-1].index; j-- {
+			dst[j], dst[j-1] = dst[j-1], dst[j]
+		}
+	}
+	return dst
+}
+
+// collect the candidates of this node and its children. `rest` is the part of the
+// path that has not been consumed yet. It is either empty or starts with a slash.
+func (n *treeNode[T]) collect(rest string, prefix bool, dst []treeEntry[T]) []treeEntry[T] {
+	dst = append(dst, n.fallback...)
+	if rest == "" || prefix {
+		dst = append(dst, n.entries...)
+	}
+	if rest == "" {
+		return dst
+	}
+
+	var segment string
+	if i := strings.IndexByte(rest[1:], '/'); i >= 0 {
+		segment, rest = rest[1:i+1], rest[i+1:]
+	} else {
+		segment, rest = rest[1:], ""
+	}
+	if child, ok := n.static[segment]; ok {
+		dst = child.collect(rest, prefix, dst)
+	}
+	if n.wildcard != nil {
+		dst = n.wildcard.collect(rest, prefix, dst)
+	}
+	return dst
+}
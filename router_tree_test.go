@@ -0,0 +1,63 @@
+package goyave
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func treeValues[T routeMatcher](entries []treeEntry[T]) []T {
+	return lo.Map(entries, func(e treeEntry[T], _ int) T { return e.value })
+}
+
+func TestTreeNode(t *testing.T) {
+	t.Run("routes", func(t *testing.T) {
+		router := prepareRouterTest()
+		routes := []*Route{
+			router.Get("/users/{id}", nil),
+			router.Get("/users/me", nil),
+			router.Get("/files/{path:.*}", nil),
+			router.Get("/users/{id:[0-9]+}/edit", nil),
+			router.Get("/", nil),
+			router.Get("{all:.*}", nil),
+		}
+
+		cases := []struct {
+			path     string
+			expected []*Route
+		}{
+			{path: "/users/me", expected: []*Route{routes[0], routes[1], routes[5]}},
+			{path: "/users/123", expected: []*Route{routes[0], routes[5]}},
+			{path: "/users/123/edit", expected: []*Route{routes[3], routes[5]}},
+			{path: "/files", expected: []*Route{routes[2], routes[5]}},
+			{path: "/files/a/b", expected: []*Route{routes[2], routes[5]}},
+			{path: "/", expected: []*Route{routes[4], routes[5]}},
+			{path: "", expected: []*Route{routes[5]}},
+			{path: "*", expected: []*Route{routes[5]}},
+			{path: "/unknown", expected: []*Route{routes[5]}},
+		}
+
+		for _, c := range cases {
+			t.Run(c.path, func(t *testing.T) {
+				assert.Equal(t, c.expected, treeValues(router.routeTree.candidates(c.path, false, nil)))
+			})
+		}
+	})
+
+	t.Run("subrouters", func(t *testing.T) {
+		router := prepareRouterTest()
+		users := router.Subrouter("/users")
+		group := router.Group()
+		user := router.Subrouter("/users/{id}")
+		files := router.Subrouter("/files{path:.*}")
+		slash := router.Subrouter("/{name:[^/]+}") // Slash in pattern: slashCount differs from the segment count
+		router.Subrouter("/categories")
+
+		candidates := router.subrouterTree.candidates("/users/123/edit", true, nil)
+		assert.Equal(t, []*Router{users, group, user, files, slash}, treeValues(candidates))
+
+		candidates = router.subrouterTree.candidates("/users", true, nil)
+		assert.Equal(t, []*Router{users, group, files, slash}, treeValues(candidates))
+	})
+}
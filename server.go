@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
 	"strconv"
@@ -111,6 +113,8 @@ type Server struct {
 	baseURL      string
 	proxyBaseURL string
 
+	trustedProxies []netip.Prefix
+
 	stopChannel chan struct{}
 	sigChannel  chan os.Signal
 
@@ -153,6 +157,11 @@ func New(opts Options) (*Server, error) {
 		return nil, err
 	}
 
+	trustedProxies, err := parseTrustedProxies(cfg.GetStringSlice("server.trustedProxies"))
+	if err != nil {
+		return nil, err
+	}
+
 	host := cfg.GetString("server.host")
 	port := cfg.GetInt("server.port")
 
@@ -181,6 +190,7 @@ func New(opts Options) (*Server, error) {
 		port:          port,
 		Logger:        slogger,
 	}
+	server.trustedProxies = trustedProxies
 	server.server.BaseContext = server.internalBaseContext
 	server.refreshURLs()
 	server.server.ErrorLog = log.New(&errLogWriter{server: server}, "", 0)
@@ -466,6 +476,7 @@ func (s *Server) Start() error {
 		if err := s.CloseDB(); err != nil {
 			s.Logger.Error(err)
 		}
+		s.flushLogger()
 	}()
 
 	s.state.Store(2)
@@ -507,6 +518,11 @@ func (s *Server) RegisterRoutes(routeRegistrer func(*Server, *Router)) {
 //
 // If registered, the OS signal channel is closed.
 //
+// The HTTP server is given `server.shutdownTimeout` seconds to shut down gracefully.
+// Once the shutdown hooks have been executed and the database connection closed,
+// the logger is flushed if its handler buffers records (see `slog.AsyncHandler`),
+// within the same timeout.
+//
 // Make sure the program doesn't exit before `Stop()` returns.
 //
 // After being stopped, a `Server` is not meant to be re-used.
@@ -523,7 +539,7 @@ func (s *Server) Stop() {
 		signal.Stop(s.sigChannel)
 		close(s.sigChannel)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
 	defer cancel()
 	err := s.server.Shutdown(ctx)
 	if err != nil {
@@ -533,6 +549,31 @@ func (s *Server) Stop() {
 	<-s.stopChannel // Wait for stop channel before returning
 }
 
+// flushErrorWriter the output of the error returned when flushing the logger fails.
+var flushErrorWriter io.Writer = os.Stderr
+
+// flushLogger is the last step of the shutdown process. If the logger's handler buffers
+// records (implements `slog.Flusher`), waits for all of them to be written, within
+// `server.shutdownTimeout` seconds.
+//
+// The flush is not registered as a shutdown hook on purpose: hooks are executed in
+// registration order and can be removed with `ClearShutdownHooks()`. Records logged by
+// hooks registered afterwards or when closing the database would not be written.
+//
+// If the timeout expires, the error is written to the standard error output instead of
+// the logger: the handler that failed to drain could block forever.
+func (s *Server) flushLogger() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
+	defer cancel()
+	if err := slog.Flush(ctx, s.Logger.Handler()); err != nil {
+		slog.New(slog.NewHandler(s.config.GetBool("app.debug"), flushErrorWriter)).Error(err)
+	}
+}
+
+func (s *Server) shutdownTimeout() time.Duration {
+	return time.Duration(s.config.GetInt("server.shutdownTimeout")) * time.Second
+}
+
 // RegisterSignalHook creates a channel listening on SIGINT and SIGTERM. When receiving such
 // signal, the server is stopped automatically and the listener on these signals is removed.
 func (s *Server) RegisterSignalHook() {
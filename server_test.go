@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	stdslog "log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -577,6 +578,85 @@ func TestServer(t *testing.T) {
 		assert.Empty(t, server.shutdownHooks)
 	})
 
+	t.Run("Stop_flushes_logger", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("server.port", 0)
+		buf := &bytes.Buffer{}
+		handler := slog.NewAsyncHandler(slog.NewHandler(false, buf), nil)
+		t.Cleanup(func() { _ = handler.Close() })
+		server, err := New(Options{Config: cfg, Logger: slog.New(handler)})
+		require.NoError(t, err)
+
+		server.RegisterShutdownHook(func(s *Server) {
+			s.Logger.Info("shutdown hook")
+		})
+		server.RegisterStartupHook(func(s *Server) {
+			s.Stop()
+		})
+
+		require.NoError(t, server.Start())
+		assert.Contains(t, buf.String(), "shutdown hook")
+	})
+
+	t.Run("Stop_flush_timeout", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("server.port", 0)
+		cfg.Set("server.shutdownTimeout", 0)
+		buf := &bytes.Buffer{}
+		handler := &blockingFlushHandler{Handler: slog.NewHandler(false, buf)}
+		server, err := New(Options{Config: cfg, Logger: slog.New(handler)})
+		require.NoError(t, err)
+
+		errBuf := &bytes.Buffer{}
+		flushErrorWriter = errBuf
+		t.Cleanup(func() { flushErrorWriter = os.Stderr })
+
+		server.RegisterStartupHook(func(s *Server) {
+			s.Stop()
+		})
+
+		require.NoError(t, server.Start())
+		assert.Empty(t, buf.String())
+		assert.Contains(t, errBuf.String(), context.DeadlineExceeded.Error())
+	})
+
+	t.Run("Stop_flush_timeout_stuck_handler", func(t *testing.T) {
+		cfg := config.LoadDefault()
+		cfg.Set("server.port", 0)
+		cfg.Set("server.shutdownTimeout", 0)
+		release := make(chan struct{})
+		handler := slog.NewAsyncHandler(&stuckHandler{Handler: slog.NewHandler(false, io.Discard), release: release}, &slog.AsyncHandlerOptions{QueueSize: 1, Block: true})
+		t.Cleanup(func() {
+			close(release)
+			_ = handler.Close()
+		})
+		server, err := New(Options{Config: cfg, Logger: slog.New(handler)})
+		require.NoError(t, err)
+
+		errBuf := &bytes.Buffer{}
+		flushErrorWriter = errBuf
+		t.Cleanup(func() { flushErrorWriter = os.Stderr })
+
+		server.RegisterStartupHook(func(s *Server) {
+			// The first record blocks the handler, the second fills the queue.
+			s.Logger.Info("first")
+			s.Logger.Info("second")
+			s.Stop()
+		})
+
+		done := make(chan error, 1)
+		go func() {
+			done <- server.Start()
+		}()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "Stop blocked by the stuck log handler")
+		}
+		assert.Contains(t, errBuf.String(), context.DeadlineExceeded.Error())
+	})
+
 	t.Run("SignalHook", func(t *testing.T) {
 		cfg := config.LoadDefault()
 		cfg.Set("server.port", 8889)
@@ -813,3 +893,22 @@ func TestErrLogWriter(t *testing.T) {
 		buf.String(),
 	)
 }
+
+type stuckHandler struct {
+	stdslog.Handler
+	release chan struct{}
+}
+
+func (h *stuckHandler) Handle(_ context.Context, _ stdslog.Record) error {
+	<-h.release
+	return nil
+}
+
+type blockingFlushHandler struct {
+	stdslog.Handler
+}
+
+func (h *blockingFlushHandler) Flush(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
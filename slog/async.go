@@ -0,0 +1,217 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// Flusher is implemented by handlers that buffer records and can write
+// them synchronously on demand.
+type Flusher interface {
+	// Flush blocks until all the records buffered before the call have been
+	// written, or until the given context is done.
+	Flush(ctx context.Context) error
+}
+
+// Flush the given handler if it implements `Flusher`. Does nothing and
+// returns `nil` otherwise.
+func Flush(ctx context.Context, handler slog.Handler) error {
+	if f, ok := handler.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// AsyncHandlerOptions options for the asynchronous handler.
+type AsyncHandlerOptions struct {
+	// QueueSize the maximum number of records waiting to be written.
+	// Defaults to 1024.
+	QueueSize int
+
+	// Block if true, `Handle` blocks when the queue is full until there is room for
+	// the new record. Otherwise, the record is dropped. Dropped records are counted
+	// and reported with a warning once the queue is available again.
+	Block bool
+}
+
+type asyncRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	flushed chan struct{}
+	record  slog.Record
+}
+
+type asyncQueue struct {
+	opts    *AsyncHandlerOptions
+	root    slog.Handler
+	records chan asyncRecord
+	done    chan struct{}
+	dropped atomic.Int64
+	mu      sync.RWMutex
+	closed  bool
+}
+
+// AsyncHandler is a `slog.Handler` wrapper writing records asynchronously.
+// Records are pushed to a bounded queue and written by the wrapped handler in a separate
+// goroutine, in order. This removes the cost of writing logs from the calling goroutine.
+//
+// Because records are written asynchronously, buffered records may be lost if the program
+// exits before they are written. Use `Flush` or `Close` to ensure they are written.
+// The server automatically flushes its logger's handler when it stops.
+//
+// The errors returned by the wrapped handler are discarded.
+//
+// Handlers derived using `WithAttrs` or `WithGroup` share the same queue.
+type AsyncHandler struct {
+	handler slog.Handler
+	queue   *asyncQueue
+}
+
+// NewAsyncHandler creates a new `AsyncHandler` wrapping the given handler and starts
+// the goroutine writing the records.
+// If `opts` is `nil`, the default options are used.
+func NewAsyncHandler(handler slog.Handler, opts *AsyncHandlerOptions) *AsyncHandler {
+	o := AsyncHandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	queue := &asyncQueue{
+		opts:    &o,
+		root:    handler,
+		records: make(chan asyncRecord, o.QueueSize),
+		done:    make(chan struct{}),
+	}
+	go queue.run()
+	return &AsyncHandler{
+		handler: handler,
+		queue:   queue,
+	}
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for item := range q.records {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		q.reportDropped()
+		_ = item.handler.Handle(item.ctx, item.record)
+	}
+	q.reportDropped()
+}
+
+func (q *asyncQueue) reportDropped() {
+	dropped := q.dropped.Swap(0)
+	if dropped == 0 {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, "async log handler queue is full, records were dropped", 0)
+	r.AddAttrs(slog.Int64("dropped", dropped))
+	_ = q.root.Handle(context.Background(), r)
+}
+
+// Handle pushes a copy of the record to the queue. If the queue is full, the
+// record is either dropped or the call blocks, depending on the handler's options.
+// If the handler is closed, the record is written synchronously.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	q := h.queue
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return h.handler.Handle(ctx, r)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	item := asyncRecord{
+		ctx:     context.WithoutCancel(ctx),
+		handler: h.handler,
+		record:  r.Clone(),
+	}
+	if q.opts.Block {
+		q.records <- item
+		return nil
+	}
+	select {
+	case q.records <- item:
+	default:
+		q.dropped.Add(1)
+	}
+	return nil
+}
+
+// Flush blocks until all the records queued before the call have been written,
+// or until the given context is done.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	q := h.queue
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case q.records <- asyncRecord{flushed: flushed}:
+		q.mu.RUnlock()
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return errors.New(ctx.Err())
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return errors.New(ctx.Err())
+	}
+}
+
+// Close writes all the queued records and stops the writing goroutine. Records
+// handled after the handler is closed are written synchronously.
+// Calling this method several times is safe.
+func (h *AsyncHandler) Close() error {
+	q := h.queue
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.records)
+	q.mu.Unlock()
+	<-q.done
+	return nil
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new `AsyncHandler` wrapping the result of `WithAttrs`
+// on the wrapped handler. The new handler shares the queue of h.
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{
+		handler: h.handler.WithAttrs(attrs),
+		queue:   h.queue,
+	}
+}
+
+// WithGroup returns a new `AsyncHandler` wrapping the result of `WithGroup`
+// on the wrapped handler. The new handler shares the queue of h.
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{
+		handler: h.handler.WithGroup(name),
+		queue:   h.queue,
+	}
+}
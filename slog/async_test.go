@@ -0,0 +1,138 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingHandler struct {
+	*recordingHandler
+	unblock chan struct{}
+	mu      *sync.Mutex
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, r slog.Record) error {
+	<-h.unblock
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.recordingHandler.Handle(ctx, r)
+}
+
+func TestAsyncHandler(t *testing.T) {
+	t.Run("New", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewAsyncHandler(inner, nil)
+		t.Cleanup(func() { _ = h.Close() })
+		assert.Equal(t, inner, h.handler)
+		assert.Equal(t, &AsyncHandlerOptions{QueueSize: 1024}, h.queue.opts)
+		assert.Equal(t, 1024, cap(h.queue.records))
+
+		opts := &AsyncHandlerOptions{Block: true}
+		h = NewAsyncHandler(inner, opts)
+		t.Cleanup(func() { _ = h.Close() })
+		assert.Equal(t, &AsyncHandlerOptions{Block: true}, opts) // The caller's options are not modified
+		assert.Equal(t, &AsyncHandlerOptions{Block: true, QueueSize: 1024}, h.queue.opts)
+	})
+
+	t.Run("Handle_and_Flush", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewAsyncHandler(inner, nil)
+		t.Cleanup(func() { _ = h.Close() })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for range 10 {
+			assert.NoError(t, h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)))
+		}
+		assert.NoError(t, h.WithAttrs([]slog.Attr{slog.String("a", "b")}).Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "derived", 0)))
+
+		require.NoError(t, h.Flush(context.Background()))
+		assert.Len(t, *inner.records, 11)
+		assert.Equal(t, "derived", (*inner.records)[10].Message)
+	})
+
+	t.Run("drop_when_full", func(t *testing.T) {
+		inner := &blockingHandler{recordingHandler: newRecordingHandler(), unblock: make(chan struct{}), mu: &sync.Mutex{}}
+		h := NewAsyncHandler(inner, &AsyncHandlerOptions{QueueSize: 1})
+
+		// The first record is being handled (blocked), the second fills the queue, the others are dropped.
+		for range 5 {
+			assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)))
+			time.Sleep(10 * time.Millisecond)
+		}
+		close(inner.unblock)
+		require.NoError(t, h.Close())
+
+		inner.mu.Lock()
+		defer inner.mu.Unlock()
+		assert.Equal(t, []string{"message", "async log handler queue is full, records were dropped", "message"}, inner.messages())
+		(*inner.records)[1].Attrs(func(a slog.Attr) bool {
+			assert.Equal(t, slog.Int64("dropped", 3), a)
+			return true
+		})
+	})
+
+	t.Run("block_when_full", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewAsyncHandler(inner, &AsyncHandlerOptions{QueueSize: 1, Block: true})
+		for range 20 {
+			assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)))
+		}
+		require.NoError(t, h.Close())
+		assert.Len(t, *inner.records, 20)
+	})
+
+	t.Run("Flush_context_done", func(t *testing.T) {
+		inner := &blockingHandler{recordingHandler: newRecordingHandler(), unblock: make(chan struct{}), mu: &sync.Mutex{}}
+		h := NewAsyncHandler(inner, nil)
+		t.Cleanup(func() {
+			close(inner.unblock)
+			_ = h.Close()
+		})
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, h.Flush(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewAsyncHandler(inner, nil)
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "queued", 0)))
+		require.NoError(t, h.Close())
+		require.NoError(t, h.Close()) // Can be called several times
+
+		// Handled synchronously after close
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "sync", 0)))
+		assert.NoError(t, h.Flush(context.Background()))
+		assert.Equal(t, []string{"queued", "sync"}, inner.messages())
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		h := NewAsyncHandler(newRecordingHandler(), nil)
+		t.Cleanup(func() { _ = h.Close() })
+		assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+	})
+
+	t.Run("WithGroup", func(t *testing.T) {
+		h := NewAsyncHandler(newRecordingHandler(), nil)
+		t.Cleanup(func() { _ = h.Close() })
+		assert.Same(t, h.queue, h.WithGroup("group").(*AsyncHandler).queue)
+	})
+}
+
+func TestFlush(t *testing.T) {
+	inner := newRecordingHandler()
+	assert.NoError(t, Flush(context.Background(), inner))
+	assert.True(t, inner.flushed)
+
+	assert.NoError(t, Flush(context.Background(), slog.NewTextHandler(nil, nil)))
+}
@@ -0,0 +1,182 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+// errorCtxKey the key used to store the `*errors.Error` being logged into the
+// context given to handlers.
+type errorCtxKey struct{}
+
+func contextWithError(ctx context.Context, err *errors.Error) context.Context {
+	return context.WithValue(ctx, errorCtxKey{}, err)
+}
+
+func errorFromContext(ctx context.Context) *errors.Error {
+	if ctx == nil {
+		return nil
+	}
+	err, _ := ctx.Value(errorCtxKey{}).(*errors.Error)
+	return err
+}
+
+// DedupHandlerOptions options for the deduplication handler.
+type DedupHandlerOptions struct {
+	// Level reports the minimum record level that will be deduplicated.
+	// Records with a lower level are always passed to the wrapped handler.
+	// If Level is nil, the handler assumes `LevelError`.
+	Level slog.Leveler
+
+	// Window the duration during which repeated records are suppressed after
+	// one has been logged. Defaults to one minute.
+	Window time.Duration
+}
+
+type dedupKey struct {
+	message string
+	pc      uintptr
+}
+
+type dedupEntry struct {
+	expiresAt  time.Time
+	suppressed int
+}
+
+type deduplicator struct {
+	opts      *DedupHandlerOptions
+	now       func() time.Time
+	entries   map[dedupKey]*dedupEntry
+	nextPurge time.Time
+	mu        sync.Mutex
+}
+
+// DedupHandler is a `slog.Handler` wrapper suppressing repeated records.
+//
+// Two records are considered identical if they have the same message and
+// the same caller. When logging an `*errors.Error` using `Logger.Error()`, the caller
+// is the location where the error was created. Otherwise, the record's source is used.
+//
+// When a record is logged, identical records are suppressed for the duration of the
+// window. The first identical record received after the window has expired is logged
+// with an additional "duplicates" attribute containing the number of suppressed records.
+// Suppression counts of keys that are not seen again during the window following
+// their expiry are discarded.
+//
+// Handlers derived using `WithAttrs` or `WithGroup` share the same state.
+type DedupHandler struct {
+	handler      slog.Handler
+	deduplicator *deduplicator
+}
+
+// NewDedupHandler creates a new `DedupHandler` wrapping the given handler.
+// If `opts` is `nil`, the default options are used.
+func NewDedupHandler(handler slog.Handler, opts *DedupHandlerOptions) *DedupHandler {
+	o := DedupHandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Window <= 0 {
+		o.Window = time.Minute
+	}
+	return &DedupHandler{
+		handler: handler,
+		deduplicator: &deduplicator{
+			opts:    &o,
+			now:     time.Now,
+			entries: make(map[dedupKey]*dedupEntry),
+		},
+	}
+}
+
+// Handle passes the record to the wrapped handler unless an identical
+// record has already been logged during the current window.
+func (h *DedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	minLevel := slog.LevelError
+	if h.deduplicator.opts.Level != nil {
+		minLevel = h.deduplicator.opts.Level.Level()
+	}
+	if r.Level < minLevel {
+		return h.handler.Handle(ctx, r)
+	}
+
+	key := dedupKey{message: r.Message, pc: r.PC}
+	if err := errorFromContext(ctx); err != nil {
+		if callers := err.Callers(); len(callers) > 0 {
+			key.pc = callers[0]
+		}
+	}
+
+	log, duplicates := h.deduplicator.check(key)
+	if !log {
+		return nil
+	}
+	if duplicates > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int("duplicates", duplicates))
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// check returns true if the record identified by the given key should be logged,
+// and the number of records that were suppressed since the last time it was.
+func (d *deduplicator) check(key dedupKey) (bool, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if !now.Before(d.nextPurge) {
+		for k, e := range d.entries {
+			if !now.Before(e.expiresAt.Add(d.opts.Window)) {
+				delete(d.entries, k)
+			}
+		}
+		d.nextPurge = now.Add(d.opts.Window)
+	}
+
+	entry, ok := d.entries[key]
+	if !ok {
+		d.entries[key] = &dedupEntry{expiresAt: now.Add(d.opts.Window)}
+		return true, 0
+	}
+	if now.Before(entry.expiresAt) {
+		entry.suppressed++
+		return false, 0
+	}
+	duplicates := entry.suppressed
+	entry.suppressed = 0
+	entry.expiresAt = now.Add(d.opts.Window)
+	return true, duplicates
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *DedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new `DedupHandler` wrapping the result of `WithAttrs`
+// on the wrapped handler. The new handler shares the state of h.
+func (h *DedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DedupHandler{
+		handler:      h.handler.WithAttrs(attrs),
+		deduplicator: h.deduplicator,
+	}
+}
+
+// WithGroup returns a new `DedupHandler` wrapping the result of `WithGroup`
+// on the wrapped handler. The new handler shares the state of h.
+func (h *DedupHandler) WithGroup(name string) slog.Handler {
+	return &DedupHandler{
+		handler:      h.handler.WithGroup(name),
+		deduplicator: h.deduplicator,
+	}
+}
+
+// Flush flushes the wrapped handler if it implements `Flusher`.
+func (h *DedupHandler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.handler)
+}
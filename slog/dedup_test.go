@@ -0,0 +1,115 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+func TestDedupHandler(t *testing.T) {
+	t.Run("New", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewDedupHandler(inner, nil)
+		assert.Equal(t, inner, h.handler)
+		assert.Equal(t, &DedupHandlerOptions{Window: time.Minute}, h.deduplicator.opts)
+		assert.NotNil(t, h.deduplicator.entries)
+
+		opts := &DedupHandlerOptions{}
+		h = NewDedupHandler(inner, opts)
+		assert.Equal(t, &DedupHandlerOptions{}, opts) // The caller's options are not modified
+		assert.Equal(t, time.Minute, h.deduplicator.opts.Window)
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewDedupHandler(inner, &DedupHandlerOptions{Window: time.Second})
+		now := time.Now()
+		h.deduplicator.now = func() time.Time { return now }
+
+		for range 3 {
+			assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "error", 1)))
+		}
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "error", 2))) // Different source
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "other", 1))) // Different message
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "error", 1)))  // Lower level
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "error", 1)))  // Lower level
+		assert.Equal(t, []string{"error", "error", "other", "error", "error"}, inner.messages())
+
+		*inner.records = nil
+		now = now.Add(time.Second)
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "error", 1)))
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "error", 1)))
+		if assert.Len(t, *inner.records, 1) {
+			r := (*inner.records)[0]
+			assert.Equal(t, 1, r.NumAttrs())
+			r.Attrs(func(a slog.Attr) bool {
+				assert.Equal(t, slog.Int("duplicates", 2), a)
+				return true
+			})
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		h := NewDedupHandler(newRecordingHandler(), &DedupHandlerOptions{Window: time.Second})
+		now := time.Now()
+		h.deduplicator.now = func() time.Time { return now }
+
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "error", 1)))
+		assert.Len(t, h.deduplicator.entries, 1)
+		now = now.Add(time.Second)
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "other", 1)))
+		assert.Len(t, h.deduplicator.entries, 2) // Expired but kept for one more window
+		now = now.Add(2 * time.Second)
+		assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelError, "other", 2)))
+		assert.Len(t, h.deduplicator.entries, 1)
+	})
+
+	t.Run("custom_level", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewDedupHandler(inner, &DedupHandlerOptions{Level: slog.LevelWarn})
+		for range 2 {
+			assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelWarn, "warn", 1)))
+		}
+		assert.Equal(t, []string{"warn"}, inner.messages())
+	})
+
+	t.Run("keyed_on_error_caller", func(t *testing.T) {
+		inner := newRecordingHandler()
+		l := New(NewDedupHandler(inner, nil))
+
+		errs := make([]error, 0, 2)
+		for range 2 {
+			errs = append(errs, errors.New("error"))
+		}
+
+		// Different logging locations, same error creation location
+		l.Error(errs[0])
+		l.Error(errs[1])
+		// Different error creation location
+		l.Error(errors.New("error"))
+		assert.Equal(t, []string{"error", "error"}, inner.messages())
+	})
+
+	t.Run("derived_handlers_share_state", func(t *testing.T) {
+		h := NewDedupHandler(newRecordingHandler(), nil)
+		assert.Same(t, h.deduplicator, h.WithAttrs([]slog.Attr{slog.String("a", "b")}).(*DedupHandler).deduplicator)
+		assert.Same(t, h.deduplicator, h.WithGroup("group").(*DedupHandler).deduplicator)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		h := NewDedupHandler(newRecordingHandler(), nil)
+		assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewDedupHandler(inner, nil)
+		assert.NoError(t, h.Flush(context.Background()))
+		assert.True(t, inner.flushed)
+	})
+}
@@ -0,0 +1,155 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+)
+
+// SamplingRule defines how many records are let through during a tick.
+type SamplingRule struct {
+	// First the number of records with the same level and message that
+	// are always logged during a tick.
+	First int
+
+	// Thereafter once `First` records have been logged during a tick, only every
+	// `Thereafter`th record with the same level and message is logged.
+	// If zero or negative, all records exceeding `First` are dropped until
+	// the next tick.
+	Thereafter int
+}
+
+// SamplingHandlerOptions options for the sampling handler.
+type SamplingHandlerOptions struct {
+	// Levels overrides the `Default` rule for specific levels. A level
+	// that is not present in this map uses the `Default` rule.
+	//
+	// To disable sampling for a level, set a rule with `First` lower than or
+	// equal to zero.
+	Levels map[slog.Level]SamplingRule
+
+	// Default the rule applied to records whose level doesn't have a
+	// specific rule in `Levels`.
+	Default SamplingRule
+
+	// Tick the duration of a sampling period. Counters are reset at the
+	// start of every tick. Defaults to one second.
+	Tick time.Duration
+}
+
+type samplingKey struct {
+	message string
+	level   slog.Level
+}
+
+type sampler struct {
+	opts    *SamplingHandlerOptions
+	now     func() time.Time
+	counts  map[samplingKey]int
+	mu      sync.Mutex
+	resetAt time.Time
+}
+
+// SamplingHandler is a `slog.Handler` wrapper limiting the amount of records
+// with the same level and message written during a period of time (tick).
+// This is useful to prevent log floods, for example during error storms.
+//
+// During a tick, the first `First` records with the same level and message are passed
+// to the wrapped handler. After that, only every `Thereafter`th record is passed.
+// Sampling rules can be defined per level.
+//
+// Handlers derived using `WithAttrs` or `WithGroup` share the same counters.
+type SamplingHandler struct {
+	handler slog.Handler
+	sampler *sampler
+}
+
+// NewSamplingHandler creates a new `SamplingHandler` wrapping the given handler.
+// If `opts` is `nil`, the default options are used: one second ticks,
+// the first 100 records are logged then every 100th record.
+func NewSamplingHandler(handler slog.Handler, opts *SamplingHandlerOptions) *SamplingHandler {
+	o := SamplingHandlerOptions{
+		Default: SamplingRule{First: 100, Thereafter: 100},
+	}
+	if opts != nil {
+		o = *opts
+		o.Levels = maps.Clone(opts.Levels)
+	}
+	if o.Tick <= 0 {
+		o.Tick = time.Second
+	}
+	return &SamplingHandler{
+		handler: handler,
+		sampler: &sampler{
+			opts:   &o,
+			now:    time.Now,
+			counts: make(map[samplingKey]int),
+		},
+	}
+}
+
+// Handle passes the record to the wrapped handler if it is sampled.
+// Dropped records are silently discarded.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.sample(r.Level, r.Message) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (s *sampler) sample(level slog.Level, message string) bool {
+	rule, ok := s.opts.Levels[level]
+	if !ok {
+		rule = s.opts.Default
+	}
+	if rule.First <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !now.Before(s.resetAt) {
+		clear(s.counts)
+		s.resetAt = now.Add(s.opts.Tick)
+	}
+
+	key := samplingKey{level: level, message: message}
+	n := s.counts[key] + 1
+	s.counts[key] = n
+	if n <= rule.First {
+		return true
+	}
+	return rule.Thereafter > 0 && (n-rule.First)%rule.Thereafter == 0
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new `SamplingHandler` wrapping the result of `WithAttrs`
+// on the wrapped handler. The new handler shares the sampling counters of h.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{
+		handler: h.handler.WithAttrs(attrs),
+		sampler: h.sampler,
+	}
+}
+
+// WithGroup returns a new `SamplingHandler` wrapping the result of `WithGroup`
+// on the wrapped handler. The new handler shares the sampling counters of h.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{
+		handler: h.handler.WithGroup(name),
+		sampler: h.sampler,
+	}
+}
+
+// Flush flushes the wrapped handler if it implements `Flusher`.
+func (h *SamplingHandler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.handler)
+}
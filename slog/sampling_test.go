@@ -0,0 +1,137 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingHandler struct {
+	handler  slog.Handler
+	records  *[]slog.Record
+	flushErr error
+	flushed  bool
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{
+		handler: slog.NewTextHandler(bytes.NewBuffer(nil), nil),
+		records: &[]slog.Record{},
+	}
+}
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func (h *recordingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recordingHandler{handler: h.handler.WithAttrs(attrs), records: h.records}
+}
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler {
+	return &recordingHandler{handler: h.handler.WithGroup(name), records: h.records}
+}
+
+func (h *recordingHandler) Flush(_ context.Context) error {
+	h.flushed = true
+	return h.flushErr
+}
+
+func (h *recordingHandler) messages() []string {
+	messages := make([]string, 0, len(*h.records))
+	for _, r := range *h.records {
+		messages = append(messages, r.Message)
+	}
+	return messages
+}
+
+func TestSamplingHandler(t *testing.T) {
+	t.Run("New", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewSamplingHandler(inner, nil)
+		assert.Equal(t, inner, h.handler)
+		assert.Equal(t, &SamplingHandlerOptions{Default: SamplingRule{First: 100, Thereafter: 100}, Tick: time.Second}, h.sampler.opts)
+		assert.NotNil(t, h.sampler.counts)
+
+		h = NewSamplingHandler(inner, &SamplingHandlerOptions{Tick: time.Minute})
+		assert.Equal(t, time.Minute, h.sampler.opts.Tick)
+
+		opts := &SamplingHandlerOptions{Levels: map[slog.Level]SamplingRule{slog.LevelError: {First: 1}}}
+		h = NewSamplingHandler(inner, opts)
+		assert.Equal(t, time.Duration(0), opts.Tick) // The caller's options are not modified
+		assert.Equal(t, time.Second, h.sampler.opts.Tick)
+		opts.Levels[slog.LevelError] = SamplingRule{First: 2}
+		assert.Equal(t, SamplingRule{First: 1}, h.sampler.opts.Levels[slog.LevelError])
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewSamplingHandler(inner, &SamplingHandlerOptions{
+			Default: SamplingRule{First: 2, Thereafter: 3},
+			Levels: map[slog.Level]SamplingRule{
+				slog.LevelError: {First: 1},
+				slog.LevelWarn:  {First: 0},
+			},
+		})
+		now := time.Now()
+		h.sampler.now = func() time.Time { return now }
+
+		logN := func(level slog.Level, message string, n int) {
+			for range n {
+				assert.NoError(t, h.Handle(context.Background(), slog.NewRecord(now, level, message, 0)))
+			}
+		}
+
+		logN(slog.LevelInfo, "info", 8)
+		logN(slog.LevelInfo, "other", 1)
+		logN(slog.LevelError, "error", 5)
+		logN(slog.LevelWarn, "warn", 3)
+
+		// Info: 1, 2 (first), 5, 8 (every 3rd thereafter)
+		assert.Equal(t, []string{"info", "info", "info", "info", "other", "error", "warn", "warn", "warn"}, inner.messages())
+
+		// Next tick resets the counters
+		*inner.records = nil
+		now = now.Add(time.Second)
+		logN(slog.LevelError, "error", 2)
+		assert.Equal(t, []string{"error"}, inner.messages())
+	})
+
+	t.Run("derived_handlers_share_counters", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewSamplingHandler(inner, &SamplingHandlerOptions{Default: SamplingRule{First: 1}})
+		withAttrs := h.WithAttrs([]slog.Attr{slog.String("a", "b")})
+		withGroup := h.WithGroup("group")
+
+		assert.Same(t, h.sampler, withAttrs.(*SamplingHandler).sampler)
+		assert.Same(t, h.sampler, withGroup.(*SamplingHandler).sampler)
+
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+		assert.NoError(t, h.Handle(context.Background(), r))
+		assert.NoError(t, withAttrs.Handle(context.Background(), r))
+		assert.NoError(t, withGroup.Handle(context.Background(), r))
+		assert.Len(t, *inner.records, 1)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		h := NewSamplingHandler(newRecordingHandler(), nil)
+		assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		inner := newRecordingHandler()
+		h := NewSamplingHandler(inner, nil)
+		assert.NoError(t, h.Flush(context.Background()))
+		assert.True(t, inner.flushed)
+	})
+}
@@ -114,6 +114,7 @@ func (l *Logger) makeRecord(level slog.Level, msg string, pc uintptr, args ...an
 }
 
 func (l *Logger) handleError(ctx context.Context, err *errors.Error, record slog.Record) {
+	ctx = contextWithError(ctx, err)
 	trace := slog.String("trace", err.StackFrames().String())
 	if err.Len() == 0 {
 		record.AddAttrs(trace)
@@ -0,0 +1,212 @@
+package sse
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"goyave.dev/goyave/v5"
+)
+
+// HubOptions options for the server-sent events `Hub`.
+type HubOptions struct {
+	// History the number of published events kept in memory and replayed to clients
+	// reconnecting with a `Last-Event-ID` header. Defaults to 0 (no replay).
+	History int
+
+	// BufferSize the number of events that can be queued for each subscriber.
+	// If a subscriber's queue is full when an event is published, the subscriber
+	// is considered too slow and its subscription is closed. Clients then reconnect
+	// and resume from the last event they received if it is still in the history.
+	// Defaults to 16.
+	BufferSize int
+
+	// Heartbeat the interval at which the handler returned by `Hub.Handler()`
+	// sends a heartbeat to the clients. No heartbeat is sent if zero or negative.
+	Heartbeat time.Duration
+}
+
+// Subscription to a `Hub`. Published events are received through the `Events()` channel.
+type Subscription struct {
+	hub    *Hub
+	events chan *Event
+}
+
+// Events returns the channel the published events are sent to. The channel is
+// closed when the subscription is closed.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Close unsubscribes from the hub. Calling Close several times has no effect.
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// Hub a publish/subscribe hub for server-sent events. Events published to the hub
+// are broadcast to all subscribers. The hub is safe for concurrent use and can be shared
+// between several handlers.
+//
+// Published events without an ID are assigned a sequential ID so clients can resume the stream
+// using `Last-Event-ID`. Published events must not be modified afterwards.
+//
+// Because the server waits for active requests to end before shutting down, the hub should
+// be closed in a shutdown hook:
+//
+//	hub := sse.NewHub(&sse.HubOptions{History: 100, Heartbeat: 15 * time.Second})
+//	server.RegisterShutdownHook(func(_ *goyave.Server) {
+//		hub.Close()
+//	})
+//	router.Get("/events", hub.Handler())
+type Hub struct {
+	opts        *HubOptions
+	subscribers map[*Subscription]struct{}
+	history     []*Event
+	lastID      uint64
+	mu          sync.Mutex
+	closed      bool
+}
+
+// NewHub creates a new `Hub`. If `opts` is `nil`, the default options are used.
+func NewHub(opts *HubOptions) *Hub {
+	o := HubOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.BufferSize <= 0 {
+		o.BufferSize = 16
+	}
+	return &Hub{
+		opts:        &o,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish broadcasts the given event to all subscribers. Subscribers that cannot
+// keep up are unsubscribed.
+func (h *Hub) Publish(event *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	if event.ID == "" {
+		e := *event
+		e.ID = strconv.FormatUint(h.lastID, 10)
+		event = &e
+	}
+	if h.opts.History > 0 {
+		if len(h.history) == h.opts.History {
+			copy(h.history, h.history[1:])
+			h.history = h.history[:len(h.history)-1]
+		}
+		h.history = append(h.history, event)
+	}
+
+	for s := range h.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			delete(h.subscribers, s)
+			close(s.events)
+		}
+	}
+}
+
+// Subscribe creates a new subscription. If `lastEventID` matches an event in the history,
+// all the events published after this one are immediately available in the subscription's
+// channel. If the hub is closed, the returned subscription's channel is closed.
+func (h *Hub) Subscribe(lastEventID string) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []*Event
+	if lastEventID != "" {
+		for i, e := range h.history {
+			if e.ID == lastEventID {
+				replay = h.history[i+1:]
+				break
+			}
+		}
+	}
+
+	s := &Subscription{
+		hub:    h,
+		events: make(chan *Event, h.opts.BufferSize+len(replay)),
+	}
+	for _, e := range replay {
+		s.events <- e
+	}
+	if h.closed {
+		close(s.events)
+		return s
+	}
+	h.subscribers[s] = struct{}{}
+	return s
+}
+
+func (h *Hub) unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
+
+// Serve subscribes to the hub and sends the published events through the given writer
+// until the client disconnects, the subscription is closed or a write fails.
+// The `Last-Event-ID` sent by the client is used to replay the missed events.
+//
+// Returns `nil` if the client disconnected or if the subscription was closed.
+func (h *Hub) Serve(w *Writer) error {
+	s := h.Subscribe(w.LastEventID())
+	defer s.Close()
+	for {
+		select {
+		case <-w.Done():
+			return nil
+		case event, ok := <-s.Events():
+			if !ok {
+				return nil
+			}
+			if err := w.Send(event); err != nil {
+				if w.request.Context().Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
+
+// Handler returns a `goyave.Handler` streaming the events published to this hub
+// to the client using `Serve()`.
+func (h *Hub) Handler() goyave.Handler {
+	return func(response *goyave.Response, request *goyave.Request) {
+		w := NewWriter(response, request)
+		defer func() {
+			_ = w.Close()
+		}()
+		if h.opts.Heartbeat > 0 {
+			w.Heartbeat(h.opts.Heartbeat)
+		}
+		if err := h.Serve(w); err != nil {
+			response.Error(err)
+		}
+	}
+}
+
+// Close unsubscribes all the subscribers and prevents new subscriptions.
+// Streams served by the hub end, allowing the server to shut down gracefully.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for s := range h.subscribers {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
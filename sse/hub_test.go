@@ -0,0 +1,191 @@
+package sse
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+func receiveAll(s *Subscription) []string {
+	ids := []string{}
+	for {
+		select {
+		case e, ok := <-s.Events():
+			if !ok {
+				return ids
+			}
+			ids = append(ids, e.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestHub(t *testing.T) {
+	t.Run("NewHub", func(t *testing.T) {
+		hub := NewHub(nil)
+		assert.Equal(t, &HubOptions{BufferSize: 16}, hub.opts)
+		assert.NotNil(t, hub.subscribers)
+
+		opts := &HubOptions{History: 2}
+		hub = NewHub(opts)
+		assert.Equal(t, &HubOptions{History: 2}, opts) // The caller's options are not modified
+		assert.Equal(t, &HubOptions{History: 2, BufferSize: 16}, hub.opts)
+	})
+
+	t.Run("Publish", func(t *testing.T) {
+		hub := NewHub(nil)
+		s1 := hub.Subscribe("")
+		s2 := hub.Subscribe("")
+
+		event := &Event{Data: "hello"}
+		hub.Publish(event)
+		hub.Publish(&Event{ID: "custom", Data: "world"})
+
+		assert.Empty(t, event.ID) // The original event is not modified
+		assert.Equal(t, []string{"1", "custom"}, receiveAll(s1))
+		assert.Equal(t, []string{"1", "custom"}, receiveAll(s2))
+		assert.Empty(t, hub.history)
+	})
+
+	t.Run("history_and_replay", func(t *testing.T) {
+		hub := NewHub(&HubOptions{History: 3})
+		for range 5 {
+			hub.Publish(&Event{Data: "hello"})
+		}
+		require.Len(t, hub.history, 3)
+		assert.Equal(t, "3", hub.history[0].ID)
+
+		assert.Equal(t, []string{"4", "5"}, receiveAll(hub.Subscribe("3")))
+		assert.Empty(t, receiveAll(hub.Subscribe("5")))
+		assert.Empty(t, receiveAll(hub.Subscribe("1"))) // Too old
+		assert.Empty(t, receiveAll(hub.Subscribe("")))
+	})
+
+	t.Run("slow_subscriber", func(t *testing.T) {
+		hub := NewHub(&HubOptions{BufferSize: 1})
+		slow := hub.Subscribe("")
+		hub.Publish(&Event{Data: "hello"})
+		fast := hub.Subscribe("")
+		hub.Publish(&Event{Data: "hello"})
+
+		assert.Equal(t, []string{"1"}, receiveAll(slow))
+		_, ok := <-slow.Events()
+		assert.False(t, ok)
+		assert.Equal(t, []string{"2"}, receiveAll(fast))
+		assert.Len(t, hub.subscribers, 1)
+	})
+
+	t.Run("Subscription_Close", func(t *testing.T) {
+		hub := NewHub(nil)
+		s := hub.Subscribe("")
+		s.Close()
+		s.Close()
+		assert.Empty(t, hub.subscribers)
+		_, ok := <-s.Events()
+		assert.False(t, ok)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		hub := NewHub(&HubOptions{History: 10})
+		hub.Publish(&Event{Data: "hello"})
+		hub.Publish(&Event{Data: "hello"})
+		s := hub.Subscribe("")
+		hub.Close()
+
+		_, ok := <-s.Events()
+		assert.False(t, ok)
+		assert.Empty(t, hub.subscribers)
+
+		hub.Publish(&Event{Data: "hello"}) // Ignored
+		assert.Len(t, hub.history, 2)
+
+		// Subscribing to a closed hub still replays the history
+		assert.Equal(t, []string{"2"}, receiveAll(hub.Subscribe("1")))
+		assert.Empty(t, hub.subscribers)
+	})
+
+	t.Run("Serve", func(t *testing.T) {
+		hub := NewHub(&HubOptions{History: 10})
+		hub.Publish(&Event{Data: "first"})
+		hub.Publish(&Event{Data: "second"})
+
+		w, request, recorder := prepareWriterTest(t)
+		request.Header().Set("Last-Event-ID", "1")
+
+		done := make(chan error, 1)
+		go func() {
+			done <- hub.Serve(w)
+		}()
+		hub.Publish(&Event{Name: "update", Data: "third"})
+		time.Sleep(10 * time.Millisecond)
+		hub.Close()
+
+		require.NoError(t, <-done)
+		assert.Equal(t, "id: 2\ndata: second\n\nid: 3\nevent: update\ndata: third\n\n", recorder.Body.String())
+	})
+
+	t.Run("Serve_client_disconnected", func(t *testing.T) {
+		hub := NewHub(nil)
+		w, request, _ := prepareWriterTest(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		request.WithContext(ctx)
+		cancel()
+
+		require.NoError(t, hub.Serve(w))
+		assert.Empty(t, hub.subscribers)
+	})
+
+	t.Run("Serve_write_error", func(t *testing.T) {
+		hub := NewHub(&HubOptions{History: 10})
+		hub.Publish(&Event{Data: "first"})
+		hub.Publish(&Event{Data: "second"})
+
+		w, request, _ := prepareWriterTest(t)
+		request.Header().Set("Last-Event-ID", "1")
+		require.NoError(t, w.Close())
+
+		require.ErrorIs(t, hub.Serve(w), ErrClosed)
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+		hub := NewHub(&HubOptions{Heartbeat: time.Millisecond})
+		server.RegisterRoutes(func(_ *goyave.Server, router *goyave.Router) {
+			router.Get("/events", hub.Handler())
+		})
+
+		go func() {
+			for {
+				hub.mu.Lock()
+				subscribed := len(hub.subscribers) > 0
+				hub.mu.Unlock()
+				if subscribed {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			hub.Publish(&Event{Data: "hello"})
+			time.Sleep(10 * time.Millisecond)
+			hub.Close()
+		}()
+		res := server.TestRequest(httptest.NewRequest(http.MethodGet, "/events", nil))
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, res.Body.Close())
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream; charset=utf-8", res.Header.Get("Content-Type"))
+		assert.Contains(t, string(body), "id: 1\ndata: hello\n\n")
+		assert.Contains(t, string(body), ":\n\n") // Heartbeat
+	})
+}
@@ -0,0 +1,268 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+var (
+	// ErrClosed returned when trying to send an event through a `Writer`
+	// that has been closed.
+	ErrClosed = stderrors.New("sse: writer closed")
+
+	// ErrInvalidField returned when an event ID or name contains a line break
+	// or a NULL character, which would break the stream format.
+	ErrInvalidField = stderrors.New("sse: event ID and name cannot contain line breaks or NULL characters")
+)
+
+// Event a server-sent event.
+type Event struct {
+	// Data the payload of the event. Strings and byte slices are sent as-is,
+	// other values are encoded as JSON. Multi-line data is split into several
+	// "data" fields. If `nil`, no "data" field is written.
+	Data any
+
+	// ID the event ID. Clients send the ID of the last event they received
+	// in the `Last-Event-ID` header when they reconnect.
+	ID string
+
+	// Name the event type. Clients receive events without a name
+	// as "message" events.
+	Name string
+
+	// Retry the reconnection time hint sent to the client. Ignored if zero or negative.
+	Retry time.Duration
+}
+
+// Writer writes server-sent events to a `*goyave.Response`.
+//
+// All the methods of the writer are safe for concurrent use. The writer must be closed
+// using `Close()` before the handler returns. Once closed, or once the client
+// has disconnected, sending events returns an error.
+//
+//	func (ctrl *Controller) Stream(response *goyave.Response, request *goyave.Request) {
+//		w := sse.NewWriter(response, request)
+//		defer w.Close()
+//		w.Heartbeat(15 * time.Second)
+//
+//		for {
+//			select {
+//			case <-w.Done():
+//				return
+//			case n := <-ctrl.notifications:
+//				if err := w.Send(&sse.Event{Name: "notification", Data: n}); err != nil {
+//					return
+//				}
+//			}
+//		}
+//	}
+type Writer struct {
+	response  *goyave.Response
+	request   *goyave.Request
+	heartbeat chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	closed    bool
+}
+
+// NewWriter sets the response headers for an event stream, writes them to the client
+// and returns a new `Writer`.
+//
+// The write deadline of the connection is removed so long-lived streams are not
+// interrupted by the server's `writeTimeout`. If the response has a compress middleware,
+// the stream is not compressed so the events are not buffered.
+func NewWriter(response *goyave.Response, request *goyave.Request) *Writer {
+	header := response.Header()
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // Disable buffering in reverse proxies such as Nginx
+	header.Del("Content-Length")
+
+	// Not all http.ResponseWriter support deadlines, in which case there is nothing to remove.
+	_ = http.NewResponseController(response).SetWriteDeadline(time.Time{})
+
+	// PreWrite lets the chained writers see the headers before they are sent. This is how
+	// the compress middleware detects the event stream and disables compression.
+	response.Status(http.StatusOK)
+	response.PreWrite([]byte{})
+	response.Flush()
+
+	return &Writer{
+		response: response,
+		request:  request,
+	}
+}
+
+// LastEventID returns the value of the `Last-Event-ID` request header,
+// sent by clients reconnecting to the stream. Returns an empty string if the header is
+// not present.
+func (w *Writer) LastEventID() string {
+	return w.request.Header().Get("Last-Event-ID")
+}
+
+// Done returns a channel that is closed when the client disconnects.
+func (w *Writer) Done() <-chan struct{} {
+	return w.request.Context().Done()
+}
+
+// Send writes the given event to the stream and flushes it to the client.
+//
+// Returns `ErrClosed` if the writer is closed, the context error if the client has
+// disconnected, or `ErrInvalidField` if the event ID or name is invalid.
+func (w *Writer) Send(event *Event) error {
+	if !isValidField(event.ID) || !isValidField(event.Name) {
+		return errors.New(ErrInvalidField)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 64))
+	if event.ID != "" {
+		writeField(buf, "id", event.ID)
+	}
+	if event.Name != "" {
+		writeField(buf, "event", event.Name)
+	}
+	if event.Retry > 0 {
+		writeField(buf, "retry", strconv.FormatInt(event.Retry.Milliseconds(), 10))
+	}
+	if event.Data != nil {
+		data, err := marshalData(event.Data)
+		if err != nil {
+			return errors.New(err)
+		}
+		for _, line := range splitLines(data) {
+			writeField(buf, "data", line)
+		}
+	}
+	buf.WriteByte('\n')
+	return w.write(buf.Bytes())
+}
+
+// Comment writes a comment to the stream. Comments are ignored by clients but
+// keep the connection alive.
+func (w *Writer) Comment(comment string) error {
+	buf := bytes.NewBuffer(make([]byte, 0, len(comment)+3))
+	for _, line := range splitLines(comment) {
+		buf.WriteByte(':')
+		if line != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(line)
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return w.write(buf.Bytes())
+}
+
+// Heartbeat starts sending an empty comment to the client at the given interval
+// to prevent proxies and clients from closing the idle connection.
+// The heartbeat stops when the writer is closed, when the client disconnects, or
+// when a write fails. Calling `Heartbeat` again, even concurrently, replaces the
+// previous heartbeat. A zero or negative interval stops the current heartbeat.
+func (w *Writer) Heartbeat(interval time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	// Stopping the previous heartbeat and starting the new one under the same lock
+	// ensures only one heartbeat goroutine is running at a time.
+	w.stopHeartbeat()
+	if interval <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	w.heartbeat = stop
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-w.Done():
+				return
+			case <-ticker.C:
+				if err := w.Comment(""); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the heartbeat and prevents further writes. Close doesn't close the
+// underlying connection: the response is ended when the handler returns.
+// Calling Close several times has no effect.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	w.stopHeartbeat()
+	w.closed = true
+	w.mu.Unlock()
+	w.wg.Wait()
+	return nil
+}
+
+// stopHeartbeat signals the running heartbeat goroutine to stop, if any.
+// The caller must hold the lock.
+func (w *Writer) stopHeartbeat() {
+	if w.heartbeat != nil {
+		close(w.heartbeat)
+		w.heartbeat = nil
+	}
+}
+
+func (w *Writer) write(b []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New(ErrClosed)
+	}
+	if err := w.request.Context().Err(); err != nil {
+		return errors.New(err)
+	}
+	if _, err := w.response.Write(b); err != nil {
+		return errors.New(err)
+	}
+	w.response.Flush()
+	return nil
+}
+
+func writeField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func marshalData(data any) (string, error) {
+	switch d := data.(type) {
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	default:
+		b, err := json.Marshal(d)
+		return string(b), err
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(s, "\n")
+}
+
+func isValidField(s string) bool {
+	return !strings.ContainsAny(s, "\r\n\x00")
+}
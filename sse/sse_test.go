@@ -0,0 +1,197 @@
+package sse
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/config"
+	"goyave.dev/goyave/v5/middleware/compress"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+func prepareWriterTest(t *testing.T) (*Writer, *goyave.Request, *httptest.ResponseRecorder) {
+	server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+	request := server.NewTestRequest(http.MethodGet, "/events", nil)
+	response, recorder := server.NewTestResponse(request)
+	return NewWriter(response, request), request, recorder
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("NewWriter", func(t *testing.T) {
+		_, _, recorder := prepareWriterTest(t)
+		res := recorder.Result()
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream; charset=utf-8", res.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", res.Header.Get("Cache-Control"))
+		assert.Equal(t, "no", res.Header.Get("X-Accel-Buffering"))
+		assert.True(t, recorder.Flushed)
+	})
+
+	t.Run("compress", func(t *testing.T) {
+		server := testutil.NewTestServerWithOptions(t, goyave.Options{Config: config.LoadDefault()})
+		request := server.NewTestRequest(http.MethodGet, "/events", nil)
+		request.Header().Set("Accept-Encoding", "gzip")
+		middleware := &compress.Middleware{Encoders: []compress.Encoder{&compress.Gzip{Level: gzip.BestCompression}}}
+
+		result := server.TestMiddleware(middleware, request, func(response *goyave.Response, request *goyave.Request) {
+			w := NewWriter(response, request)
+			require.NoError(t, w.Send(&Event{Data: "first"}))
+			require.NoError(t, w.Send(&Event{Data: "second"}))
+			require.NoError(t, w.Close())
+		})
+
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Empty(t, result.Header.Get("Content-Encoding"))
+		body, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		assert.NoError(t, result.Body.Close())
+		assert.Equal(t, "data: first\n\ndata: second\n\n", string(body))
+	})
+
+	t.Run("Send", func(t *testing.T) {
+		cases := []struct {
+			event *Event
+			want  string
+		}{
+			{event: &Event{Data: "hello"}, want: "data: hello\n\n"},
+			{event: &Event{ID: "1", Name: "update", Retry: 3 * time.Second, Data: "hello"}, want: "id: 1\nevent: update\nretry: 3000\ndata: hello\n\n"},
+			{event: &Event{Data: "line 1\nline 2\r\nline 3"}, want: "data: line 1\ndata: line 2\ndata: line 3\n\n"},
+			{event: &Event{Data: []byte("bytes")}, want: "data: bytes\n\n"},
+			{event: &Event{Data: map[string]any{"id": 1}}, want: "data: {\"id\":1}\n\n"},
+			{event: &Event{Name: "ping"}, want: "event: ping\n\n"},
+		}
+
+		for _, c := range cases {
+			t.Run(c.want, func(t *testing.T) {
+				w, _, recorder := prepareWriterTest(t)
+				require.NoError(t, w.Send(c.event))
+				assert.Equal(t, c.want, recorder.Body.String())
+			})
+		}
+	})
+
+	t.Run("Send_invalid_field", func(t *testing.T) {
+		w, _, recorder := prepareWriterTest(t)
+		require.ErrorIs(t, w.Send(&Event{ID: "1\n2"}), ErrInvalidField)
+		require.ErrorIs(t, w.Send(&Event{Name: "a\rb"}), ErrInvalidField)
+		require.Error(t, w.Send(&Event{Data: func() {}})) // Cannot be marshaled
+		assert.Empty(t, recorder.Body.String())
+	})
+
+	t.Run("Comment", func(t *testing.T) {
+		w, _, recorder := prepareWriterTest(t)
+		require.NoError(t, w.Comment(""))
+		require.NoError(t, w.Comment("hello\nworld"))
+		assert.Equal(t, ":\n\n: hello\n: world\n\n", recorder.Body.String())
+	})
+
+	t.Run("LastEventID", func(t *testing.T) {
+		w, request, _ := prepareWriterTest(t)
+		assert.Empty(t, w.LastEventID())
+		request.Header().Set("Last-Event-ID", "42")
+		assert.Equal(t, "42", w.LastEventID())
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		w, _, _ := prepareWriterTest(t)
+		require.NoError(t, w.Close())
+		require.NoError(t, w.Close())
+		require.ErrorIs(t, w.Send(&Event{Data: "hello"}), ErrClosed)
+	})
+
+	t.Run("client_disconnected", func(t *testing.T) {
+		w, request, recorder := prepareWriterTest(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		request.WithContext(ctx)
+		cancel()
+
+		<-w.Done()
+		require.ErrorIs(t, w.Send(&Event{Data: "hello"}), context.Canceled)
+		assert.Empty(t, recorder.Body.String())
+	})
+
+	t.Run("Heartbeat", func(t *testing.T) {
+		w, _, recorder := prepareWriterTest(t)
+		w.Heartbeat(time.Millisecond)
+		w.Heartbeat(time.Millisecond) // Replaces the previous heartbeat
+		time.Sleep(20 * time.Millisecond)
+		require.NoError(t, w.Close())
+
+		body := recorder.Body.String()
+		assert.NotEmpty(t, body)
+		assert.Empty(t, strings.ReplaceAll(body, ":\n\n", ""))
+
+		// Stopped after close
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(t, body, recorder.Body.String())
+
+		w.Heartbeat(time.Millisecond) // Doesn't start if closed
+		assert.Nil(t, w.heartbeat)
+	})
+
+	t.Run("Heartbeat_invalid_interval", func(t *testing.T) {
+		w, _, recorder := prepareWriterTest(t)
+		w.Heartbeat(0)
+		assert.Nil(t, w.heartbeat)
+		w.Heartbeat(-time.Second)
+		assert.Nil(t, w.heartbeat)
+
+		// Stops the current heartbeat
+		w.Heartbeat(time.Millisecond)
+		assert.NotNil(t, w.heartbeat)
+		w.Heartbeat(0)
+		assert.Nil(t, w.heartbeat)
+		require.NoError(t, w.Close())
+		body := recorder.Body.String()
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(t, body, recorder.Body.String())
+	})
+
+	t.Run("Heartbeat_concurrent", func(t *testing.T) {
+		w, _, _ := prepareWriterTest(t)
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				w.Heartbeat(time.Millisecond)
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		closed := make(chan struct{})
+		go func() {
+			assert.NoError(t, w.Close())
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			require.FailNow(t, "Close blocked by a leaked heartbeat goroutine")
+		}
+	})
+
+	t.Run("Heartbeat_client_disconnected", func(t *testing.T) {
+		w, request, _ := prepareWriterTest(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		request.WithContext(ctx)
+		w.Heartbeat(time.Millisecond)
+		cancel()
+		w.wg.Wait() // The goroutine exits by itself
+		require.NoError(t, w.Close())
+	})
+}
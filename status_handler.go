@@ -49,6 +49,27 @@ func (*ErrorStatusHandler) Handle(response *Response, _ *Request) {
 	response.JSON(response.GetStatus(), message)
 }
 
+// ForbiddenStatusHandler for HTTP 403 errors. Writes the reason stored in the
+// request's `ExtraForbiddenReason` by the middleware that denied the request
+// (e.g. CSRF protection or authorization). Otherwise, writes the status text,
+// like `ErrorStatusHandler`.
+type ForbiddenStatusHandler struct {
+	Component
+}
+
+// Handle forbidden responses.
+func (*ForbiddenStatusHandler) Handle(response *Response, request *Request) {
+	errorMessage, ok := request.Extra[ExtraForbiddenReason{}].(string)
+	if !ok {
+		errorMessage = http.StatusText(response.GetStatus())
+	}
+
+	message := map[string]string{
+		"error": errorMessage,
+	}
+	response.JSON(response.GetStatus(), message)
+}
+
 // ParseErrorStatusHandler a generic (error) status handler for requests.
 type ParseErrorStatusHandler struct {
 	Component
@@ -101,6 +122,10 @@ func (*ValidationStatusHandler) Handle(response *Response, request *Request) {
 		errs.Query = e.(*validation.Errors)
 	}
 
+	if e, ok := request.Extra[ExtraParamsValidationError{}]; ok {
+		errs.Params = e.(*validation.Errors)
+	}
+
 	message := map[string]*validation.ErrorResponse{"error": errs}
 	response.JSON(response.GetStatus(), message)
 }
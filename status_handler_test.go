@@ -109,6 +109,40 @@ func TestErrorStatusHandler(t *testing.T) {
 	assert.Equal(t, `{"error":"Not Found"}`+"\n", string(body))
 }
 
+func TestForbiddenStatusHandler(t *testing.T) {
+	t.Run("reason", func(t *testing.T) {
+		req, resp, recorder := prepareStatusHandlerTest()
+		handler := &ForbiddenStatusHandler{}
+		handler.Init(resp.server)
+
+		req.Extra[ExtraForbiddenReason{}] = "You are not allowed to do this."
+		resp.Status(http.StatusForbidden)
+		handler.Handle(resp, req)
+
+		res := recorder.Result()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, res.Body.Close())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Equal(t, `{"error":"You are not allowed to do this."}`+"\n", string(body))
+	})
+
+	t.Run("no_reason", func(t *testing.T) {
+		req, resp, recorder := prepareStatusHandlerTest()
+		handler := &ForbiddenStatusHandler{}
+		handler.Init(resp.server)
+
+		resp.Status(http.StatusForbidden)
+		handler.Handle(resp, req)
+
+		res := recorder.Result()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, res.Body.Close())
+		require.NoError(t, err)
+		assert.Equal(t, `{"error":"Forbidden"}`+"\n", string(body))
+	})
+}
+
 func TestValidationStatusHandler(t *testing.T) {
 	req, resp, recorder := prepareStatusHandlerTest()
 	handler := &ValidationStatusHandler{}
@@ -125,6 +159,11 @@ func TestValidationStatusHandler(t *testing.T) {
 			"query": &validation.Errors{Errors: []string{"The query is required"}},
 		},
 	}
+	req.Extra[ExtraParamsValidationError{}] = &validation.Errors{
+		Fields: validation.FieldsErrors{
+			"id": &validation.Errors{Errors: []string{"The id must be at least 5."}},
+		},
+	}
 
 	handler.Handle(resp, req)
 
@@ -133,7 +172,7 @@ func TestValidationStatusHandler(t *testing.T) {
 	assert.NoError(t, res.Body.Close())
 	require.NoError(t, err)
 
-	assert.Equal(t, `{"error":{"body":{"fields":{"field":{"errors":["The field is required"]}},"errors":["The body is required"]},"query":{"fields":{"query":{"errors":["The query is required"]}}}}}`+"\n", string(body))
+	assert.Equal(t, `{"error":{"body":{"fields":{"field":{"errors":["The field is required"]}},"errors":["The body is required"]},"query":{"fields":{"query":{"errors":["The query is required"]}}},"params":{"fields":{"id":{"errors":["The id must be at least 5."]}}}}}`+"\n", string(body))
 }
 
 func TestParseErrorStatusHandler(t *testing.T) {
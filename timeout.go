@@ -0,0 +1,341 @@
+package goyave
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	errorutil "goyave.dev/goyave/v5/util/errors"
+)
+
+// timeoutMiddleware applies the timeout defined by the closest `MetaTimeout` meta
+// to the request's context. The deadline therefore propagates to everything using the
+// request's context, such as database queries or outgoing HTTP requests.
+//
+// The rest of the middleware stack and the handler are executed in a separate goroutine,
+// with their own `*Response` and a copy of the `*Request`. The response header is held back
+// until it is written.
+//
+// If the deadline is exceeded before the response header is written, the middleware returns
+// immediately with the status "503 Service Unavailable" and the matching status handler is
+// executed as usual, with the request's original context. The handler is not interrupted:
+// everything it writes afterwards is silently discarded and the writers it chained are closed
+// when it returns. Changes it made to the request are not visible to the previous middleware.
+//
+// If the handler returns before the deadline is exceeded without writing the response but with
+// an error caused by a deadline (for example if a database query was canceled), the status
+// is replaced by "504 Gateway Timeout".
+//
+// If the response header was already written when the deadline is exceeded, the response
+// status cannot be changed anymore. The middleware waits for the handler to return and
+// subsequent writes fail with `context.DeadlineExceeded` so streaming handlers can stop.
+type timeoutMiddleware struct {
+	Component
+}
+
+func (m *timeoutMiddleware) Handle(next Handler) Handler {
+	return func(response *Response, request *Request) {
+		t, _ := request.Route.LookupMeta(MetaTimeout)
+		timeout, ok := t.(time.Duration)
+		if !ok || timeout <= 0 {
+			next(response, request)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(request.Context(), timeout)
+		defer cancel()
+
+		handlerRequest := &Request{}
+		*handlerRequest = *request
+		handlerRequest.Extra = maps.Clone(request.Extra)
+		handlerRequest.WithContext(ctx)
+
+		writer := &timeoutWriter{ctx: ctx, wr: response.writer, headerSent: response.wroteHeader}
+		responseWriter := &timeoutResponseWriter{
+			ResponseWriter: response.responseWriter,
+			writer:         writer,
+			header:         response.Header().Clone(),
+		}
+		writer.responseWriter = responseWriter
+		handlerResponse := &Response{
+			writer:         writer,
+			responseWriter: responseWriter,
+			server:         response.server,
+			request:        handlerRequest,
+			err:            response.err,
+			status:         response.status,
+			empty:          response.empty,
+			wroteHeader:    response.wroteHeader,
+			hijacked:       response.hijacked,
+		}
+
+		done := make(chan struct{})
+		var panicErr error
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicErr = errorutil.NewSkip(p, 4) // Skipped: runtime.Callers, NewSkip, this func, runtime.panic
+				}
+				writer.mu.Lock()
+				writer.finished = true
+				abandoned := writer.timedOut
+				writer.mu.Unlock()
+				if abandoned {
+					// The timeout response has already been sent: nobody is waiting for the handler.
+					if panicErr != nil {
+						m.Logger().Error(panicErr)
+					}
+					_ = handlerResponse.close() // The output is discarded
+				}
+				close(done)
+			}()
+			next(handlerResponse, handlerRequest)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && writer.timeout() {
+				response.status = http.StatusServiceUnavailable
+				return
+			}
+			// The header has been written, the handler returned in the meantime or the
+			// client disconnected: wait for the handler to return.
+			<-done
+		}
+
+		originalCtx := request.Context()
+		*request = *handlerRequest
+		request.WithContext(originalCtx)
+
+		writer.mu.Lock()
+		headerSent := writer.headerSent
+		deadlineErr := handlerResponse.err != nil && errors.Is(handlerResponse.err, context.DeadlineExceeded)
+		expired := !headerSent && (errors.Is(ctx.Err(), context.DeadlineExceeded) || (handlerResponse.empty && deadlineErr))
+		writer.timedOut = expired
+		writer.done = !expired
+		writer.mu.Unlock()
+
+		response.err = handlerResponse.err
+		if expired {
+			// Discard everything the handler did to the response, including the writers it
+			// chained. If the handler panicked, the recovery middleware overrides the status.
+			_ = handlerResponse.close()
+			response.status = http.StatusServiceUnavailable
+			if deadlineErr {
+				response.status = http.StatusGatewayTimeout
+			}
+		} else {
+			if !handlerResponse.wroteHeader {
+				clear(response.Header())
+				maps.Copy(response.Header(), responseWriter.header)
+			}
+			response.writer = handlerResponse.writer
+			response.status = handlerResponse.status
+			response.empty = handlerResponse.empty
+			response.wroteHeader = handlerResponse.wroteHeader
+			response.hijacked = handlerResponse.hijacked
+		}
+
+		if panicErr != nil {
+			panic(panicErr)
+		}
+	}
+}
+
+// timeoutWriter chained writer at the end of the handler's writer chain. It holds
+// back the response header until it is written by the handler, and discards the
+// response written after the deadline of the given context is exceeded.
+type timeoutWriter struct {
+	ctx            context.Context
+	wr             io.Writer
+	responseWriter *timeoutResponseWriter
+
+	// preWrite the data given to the first `PreWrite()` call, waiting for the header to be written.
+	preWrite []byte
+
+	mu sync.Mutex
+	// headerSent true if the response header has been written before the deadline.
+	headerSent bool
+	// timedOut true if the deadline has been exceeded before the response header was written.
+	timedOut bool
+	// hasPreWrite true if `PreWrite()` has been called before the header was written.
+	hasPreWrite bool
+	// finished true once the handler returned.
+	finished bool
+	// done true once the handler returned and its response has been kept.
+	// The writer then lets everything through.
+	done bool
+}
+
+// timeout marks the response as timed out if its header hasn't been written
+// and the handler is still running. Returns true if the response timed out.
+func (w *timeoutWriter) timeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.headerSent && !w.finished {
+		w.timedOut = true
+	}
+	return w.timedOut
+}
+
+// commitHeader copies the handler's header to the response and writes it, after calling
+// `PreWrite()` and `PreWriteHeader()` on the child writer. Does nothing if the header
+// has already been written or if the response timed out.
+func (w *timeoutWriter) commitHeader(status int) {
+	if !w.sendHeader() {
+		return
+	}
+
+	header := w.responseWriter.ResponseWriter.Header()
+	clear(header)
+	maps.Copy(header, w.responseWriter.header)
+	if w.hasPreWrite {
+		if pr, ok := w.wr.(PreWriter); ok {
+			pr.PreWrite(w.preWrite)
+		}
+		w.preWrite = nil
+	}
+	if hw, ok := w.wr.(HeaderPreWriter); ok {
+		hw.PreWriteHeader(status)
+	}
+	w.responseWriter.ResponseWriter.WriteHeader(status)
+}
+
+// sendHeader returns true if the response header can be written. If the deadline is
+// exceeded, the response is marked as timed out instead. Once taken, the decision is kept
+// for the rest of the handler's execution.
+func (w *timeoutWriter) sendHeader() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.headerSent || w.timedOut {
+		return false
+	}
+	if w.expired() {
+		w.timedOut = true
+		return false
+	}
+	w.headerSent = true
+	return true
+}
+
+// state returns the current state of the writer.
+func (w *timeoutWriter) state() (headerSent, timedOut, done bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.headerSent, w.timedOut, w.done
+}
+
+func (w *timeoutWriter) expired() bool {
+	return errors.Is(w.ctx.Err(), context.DeadlineExceeded)
+}
+
+// PreWrite calls PreWrite on the child writer if the response header has already been written.
+// Otherwise, the call is held back until the header is written.
+func (w *timeoutWriter) PreWrite(b []byte) {
+	headerSent, timedOut, done := w.state()
+	switch {
+	case done || headerSent:
+		if pr, ok := w.wr.(PreWriter); ok {
+			pr.PreWrite(b)
+		}
+	case !timedOut:
+		w.preWrite = b
+		w.hasPreWrite = true
+	}
+}
+
+// PreWriteHeader calls PreWriteHeader on the child writer once the handler returned.
+// Before that, the call is held back until the header is written.
+func (w *timeoutWriter) PreWriteHeader(status int) {
+	if _, _, done := w.state(); done {
+		if hw, ok := w.wr.(HeaderPreWriter); ok {
+			hw.PreWriteHeader(status)
+		}
+	}
+}
+
+// Write writes to the child writer. If the deadline was exceeded before the response
+// header was written, the data is discarded so the handler can complete normally.
+// If the deadline was exceeded after, returns the context error.
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	_, timedOut, done := w.state()
+	if done {
+		return w.wr.Write(b)
+	}
+	if timedOut {
+		return len(b), nil
+	}
+	if w.expired() {
+		return 0, w.ctx.Err()
+	}
+	return w.wr.Write(b)
+}
+
+// Flush flushes the child writer if the deadline is not exceeded.
+func (w *timeoutWriter) Flush() error {
+	_, timedOut, done := w.state()
+	if !done && (timedOut || w.expired()) {
+		return nil
+	}
+	switch flusher := w.wr.(type) {
+	case Flusher:
+		return flusher.Flush()
+	case http.Flusher:
+		flusher.Flush()
+	}
+	return nil
+}
+
+// Close closes the child writer if it implements `io.Closer`. If the response
+// timed out, the child writer is not closed: it is not part of the response anymore.
+func (w *timeoutWriter) Close() error {
+	if _, timedOut, _ := w.state(); timedOut {
+		return nil
+	}
+	if wr, ok := w.wr.(io.Closer); ok {
+		return wr.Close()
+	}
+	return nil
+}
+
+// timeoutResponseWriter the `http.ResponseWriter` of the handler's response. The handler
+// has its own header, copied to the actual response when it is written.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	writer *timeoutWriter
+	header http.Header
+}
+
+func (w *timeoutResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutResponseWriter) WriteHeader(status int) {
+	if _, _, done := w.writer.state(); done {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.writer.commitHeader(status)
+}
+
+func (w *timeoutResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, ErrNotHijackable
+	}
+	if headerSent, _, _ := w.writer.state(); !headerSent && !w.writer.sendHeader() {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	return hijacker.Hijack()
+}
+
+func (w *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
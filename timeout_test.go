@@ -0,0 +1,249 @@
+package goyave
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFuncMiddleware struct {
+	Component
+	handle func(next Handler) Handler
+}
+
+func (m *testFuncMiddleware) Handle(next Handler) Handler {
+	return m.handle(next)
+}
+
+type closeNotifyWriter struct {
+	io.Writer
+	closed chan struct{}
+}
+
+func (w *closeNotifyWriter) Close() error {
+	close(w.closed)
+	return nil
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Run("Timeout", func(t *testing.T) {
+		router := prepareRouterTest()
+		router.Timeout(time.Second)
+		assert.Equal(t, time.Second, router.Meta[MetaTimeout])
+		assert.True(t, hasMiddleware[*timeoutMiddleware](router.globalMiddleware.middleware))
+
+		router.Subrouter("/sub").Timeout(0)
+		router.Get("/route", nil).Timeout(time.Minute)
+		assert.Len(t, router.globalMiddleware.middleware, 3)
+
+		router = prepareRouterTest()
+		router.Timeout(0)
+		assert.False(t, hasMiddleware[*timeoutMiddleware](router.globalMiddleware.middleware))
+
+		route := router.Get("/route", nil).Timeout(time.Minute)
+		assert.Equal(t, time.Minute, route.Meta[MetaTimeout])
+		assert.True(t, hasMiddleware[*timeoutMiddleware](router.globalMiddleware.middleware))
+	})
+
+	t.Run("Handle", func(t *testing.T) {
+		router := prepareRouterTest()
+		router.Timeout(time.Millisecond)
+
+		router.Get("/ok", func(response *Response, request *Request) {
+			_, ok := request.Context().Deadline()
+			assert.True(t, ok)
+			response.String(http.StatusOK, "ok")
+		})
+		router.Get("/overrun", func(response *Response, request *Request) {
+			<-request.Context().Done()
+			response.Header().Set("X-Handler", "value")
+			response.String(http.StatusOK, "too late")
+		})
+		router.Get("/overrun-status", func(response *Response, request *Request) {
+			<-request.Context().Done()
+			response.Status(http.StatusNotFound)
+		})
+		router.Get("/canceled", func(response *Response, request *Request) {
+			// For example a database query with a shorter timeout
+			ctx, cancel := context.WithTimeout(request.Context(), time.Microsecond)
+			defer cancel()
+			<-ctx.Done()
+			response.Error(ctx.Err())
+		}).Timeout(time.Minute)
+		router.Get("/panic", func(_ *Response, _ *Request) {
+			panic("test panic")
+		})
+		router.Get("/panic-overrun", func(response *Response, request *Request) {
+			<-request.Context().Done()
+			response.String(http.StatusOK, "too late")
+			panic("test panic")
+		})
+		router.Get("/stream", func(response *Response, request *Request) {
+			response.String(http.StatusOK, "first")
+			response.Flush()
+			<-request.Context().Done()
+			_, err := response.Write([]byte("second"))
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		}).Timeout(5 * time.Millisecond)
+		router.Get("/disabled", func(response *Response, request *Request) {
+			_, ok := request.Context().Deadline()
+			assert.False(t, ok)
+			response.String(http.StatusOK, "ok")
+		}).Timeout(0)
+		router.Get("/override", func(response *Response, request *Request) {
+			deadline, ok := request.Context().Deadline()
+			assert.True(t, ok)
+			assert.Greater(t, time.Until(deadline), time.Minute)
+			response.String(http.StatusOK, "ok")
+		}).Timeout(time.Hour)
+
+		cases := []struct {
+			url            string
+			expectedBody   string
+			expectedStatus int
+		}{
+			{url: "/ok", expectedStatus: http.StatusOK, expectedBody: "ok"},
+			{url: "/overrun", expectedStatus: http.StatusServiceUnavailable, expectedBody: "{\"error\":\"Service Unavailable\"}\n"},
+			{url: "/overrun-status", expectedStatus: http.StatusServiceUnavailable, expectedBody: "{\"error\":\"Service Unavailable\"}\n"},
+			{url: "/canceled", expectedStatus: http.StatusGatewayTimeout, expectedBody: "{\"error\":\"Gateway Timeout\"}\n"},
+			{url: "/panic", expectedStatus: http.StatusInternalServerError, expectedBody: "{\"error\":\"Internal Server Error\"}\n"},
+			{url: "/panic-overrun", expectedStatus: http.StatusServiceUnavailable, expectedBody: "{\"error\":\"Service Unavailable\"}\n"},
+			{url: "/stream", expectedStatus: http.StatusOK, expectedBody: "first"},
+			{url: "/disabled", expectedStatus: http.StatusOK, expectedBody: "ok"},
+			{url: "/override", expectedStatus: http.StatusOK, expectedBody: "ok"},
+		}
+
+		for _, c := range cases {
+			t.Run(strings.TrimPrefix(c.url, "/"), func(t *testing.T) {
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, c.url, nil))
+				res := recorder.Result()
+				body, err := io.ReadAll(res.Body)
+				assert.NoError(t, res.Body.Close())
+				require.NoError(t, err)
+				assert.Equal(t, c.expectedStatus, res.StatusCode)
+				assert.Equal(t, c.expectedBody, string(body))
+				assert.Empty(t, res.Header.Get("X-Handler"))
+			})
+		}
+	})
+
+	t.Run("handler_state", func(t *testing.T) {
+		router := prepareRouterTest()
+		router.GlobalMiddleware(&testFuncMiddleware{handle: func(next Handler) Handler {
+			return func(response *Response, request *Request) {
+				response.Header().Set("X-Before", "value")
+				next(response, request)
+				assert.Equal(t, true, request.Extra["handler"])
+				_, ok := request.Context().Deadline()
+				assert.False(t, ok)
+				assert.Equal(t, http.StatusCreated, response.GetStatus())
+			}
+		}})
+		router.Timeout(time.Minute)
+		router.Get("/headers", func(response *Response, request *Request) {
+			response.Header().Set("X-Handler", "value")
+			response.Header().Del("X-Before")
+			request.Extra["handler"] = true
+			response.Status(http.StatusCreated)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/headers", nil))
+		res := recorder.Result()
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "value", res.Header.Get("X-Handler"))
+		assert.Empty(t, res.Header.Get("X-Before"))
+	})
+
+	t.Run("respond_at_deadline", func(t *testing.T) {
+		router := prepareRouterTest()
+		router.Timeout(10 * time.Millisecond)
+		release := make(chan struct{})
+		writer := &closeNotifyWriter{Writer: io.Discard, closed: make(chan struct{})}
+		router.Get("/ignore-context", func(response *Response, _ *Request) {
+			response.SetWriter(writer)
+			<-release // The handler ignores the context
+			response.String(http.StatusOK, "too late")
+		})
+
+		start := time.Now()
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ignore-context", nil))
+		assert.Less(t, time.Since(start), time.Second)
+		res := recorder.Result()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, res.Body.Close())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, "{\"error\":\"Service Unavailable\"}\n", string(body))
+
+		close(release)
+		select {
+		case <-writer.closed:
+			// The writers chained by the handler are closed once it returns
+		case <-time.After(time.Second):
+			assert.Fail(t, "the writer chained by the handler was not closed")
+		}
+	})
+
+	t.Run("status_handler_context", func(t *testing.T) {
+		router := prepareRouterTest()
+		router.Timeout(time.Millisecond)
+		router.StatusHandler(&testStatusHandler{
+			override: func(response *Response, request *Request) {
+				assert.NoError(t, request.Context().Err())
+				_, ok := request.Context().Deadline()
+				assert.False(t, ok)
+				response.String(response.GetStatus(), "status handler")
+			},
+		}, http.StatusServiceUnavailable)
+		router.Get("/overrun", func(_ *Response, request *Request) {
+			<-request.Context().Done()
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/overrun", nil))
+		res := recorder.Result()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, res.Body.Close())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, "status handler", string(body))
+	})
+
+	t.Run("timeoutResponseWriter", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		recorder.Header().Set("X-Before", "value")
+		tw := &timeoutWriter{ctx: context.Background(), wr: recorder}
+		writer := &timeoutResponseWriter{ResponseWriter: recorder, writer: tw, header: http.Header{}}
+		tw.responseWriter = writer
+		assert.Equal(t, recorder, writer.Unwrap())
+
+		_, _, err := writer.Hijack()
+		assert.ErrorIs(t, err, ErrNotHijackable)
+
+		writer.Header().Set("X-Handler", "value")
+		assert.Empty(t, recorder.Header().Get("X-Handler"))
+		writer.WriteHeader(http.StatusAccepted)
+		assert.Equal(t, http.StatusAccepted, recorder.Code)
+		assert.Equal(t, "value", recorder.Header().Get("X-Handler"))
+		assert.Empty(t, recorder.Header().Get("X-Before"))
+	})
+
+	t.Run("hijack_after_timeout", func(t *testing.T) {
+		recorder := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+		tw := &timeoutWriter{ctx: context.Background(), wr: recorder, timedOut: true}
+		writer := &timeoutResponseWriter{ResponseWriter: recorder, writer: tw, header: http.Header{}}
+		_, _, err := writer.Hijack()
+		assert.ErrorIs(t, err, http.ErrHandlerTimeout)
+	})
+}
@@ -97,15 +97,6 @@ func (file *File) UnmarshalJSON(data []byte) error {
 //
 // Returns the actual file name.
 func (file *File) Save(fs WritableFS, path string, name string) (filename string, err error) {
-	filename = timestampFileName(name)
-
-	if mkdirFS, ok := fs.(MkdirFS); ok {
-		if err = mkdirFS.MkdirAll(path, os.ModePerm); err != nil {
-			err = errors.New(err)
-			return
-		}
-	}
-
 	var f multipart.File
 	f, err = file.Header.Open()
 	if err != nil {
@@ -118,6 +109,25 @@ func (file *File) Save(fs WritableFS, path string, name string) (filename string
 			err = errors.New(closeError)
 		}
 	}()
+	return SaveReader(fs, path, name, f)
+}
+
+// SaveReader writes the content of the given reader to a new file in the given file system.
+// Appends a timestamp to the given file name to avoid duplicate file names.
+// The reader is consumed but not closed.
+//
+// Creates directories if needed.
+//
+// Returns the actual file name.
+func SaveReader(fs WritableFS, path string, name string, reader io.Reader) (filename string, err error) {
+	filename = timestampFileName(name)
+
+	if mkdirFS, ok := fs.(MkdirFS); ok {
+		if err = mkdirFS.MkdirAll(path, os.ModePerm); err != nil {
+			err = errors.New(err)
+			return
+		}
+	}
 
 	var writer io.ReadWriteCloser
 	writer, err = fs.OpenFile(pathutil.Join(path, filename), os.O_WRONLY|os.O_CREATE, 0660)
@@ -131,7 +141,7 @@ func (file *File) Save(fs WritableFS, path string, name string) (filename string
 			err = errors.New(closeError)
 		}
 	}()
-	_, err = io.Copy(writer, f)
+	_, err = io.Copy(writer, reader)
 	if err != nil {
 		err = errors.New(err)
 	}
@@ -417,6 +417,23 @@ func TestSave(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestSaveReader(t *testing.T) {
+	fs := &osfs.FS{}
+	path := toAbsolutePath("./subdir")
+	actualName, err := SaveReader(fs, path, "saved.txt", strings.NewReader("content"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(actualName, "saved-"))
+	assert.True(t, strings.HasSuffix(actualName, ".txt"))
+
+	content, err := os.ReadFile(toAbsolutePath("./subdir/" + actualName))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+	assert.NoError(t, os.RemoveAll(path))
+
+	_, err = SaveReader(fs, toAbsolutePath("./go.mod"), "saved", strings.NewReader("content"))
+	assert.Error(t, err)
+}
+
 func TestMarshalFile(t *testing.T) {
 	type testDTO struct {
 		Files []File `json:"files"`
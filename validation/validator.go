@@ -39,8 +39,9 @@ const (
 
 // ErrorResponse HTTP response format for validation errors.
 type ErrorResponse struct {
-	Body  *Errors `json:"body,omitempty"`
-	Query *Errors `json:"query,omitempty"`
+	Body   *Errors `json:"body,omitempty"`
+	Query  *Errors `json:"query,omitempty"`
+	Params *Errors `json:"params,omitempty"`
 }
 
 // Composable is a partial clone of `goyave.Component`, only
@@ -0,0 +1,408 @@
+package websocket
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/errors"
+)
+
+const (
+	// SlowClientCloseMessage the message sent with the close frame when a
+	// client is disconnected because its send queue is full.
+	SlowClientCloseMessage = "Client too slow"
+)
+
+var (
+	// ErrClientClosed returned when trying to send a message to a client that
+	// has been unregistered from its hub.
+	ErrClientClosed = stderrors.New("websocket: client closed")
+
+	// ErrSendQueueFull returned when a client's send queue is full. The client is
+	// unregistered and disconnected.
+	ErrSendQueueFull = stderrors.New("websocket: client send queue is full")
+)
+
+// HubOptions options for the websocket `Hub`.
+type HubOptions struct {
+	// QueueSize the number of messages that can be queued for each client.
+	// Defaults to 64.
+	QueueSize int
+
+	// SendTimeout the maximum duration `Client.Send()` blocks when the client's
+	// send queue is full. If the message still cannot be queued after this duration,
+	// the client is considered too slow and disconnected. If zero or negative,
+	// slow clients are disconnected immediately.
+	SendTimeout time.Duration
+
+	// WriteTimeout the write deadline for each message. Defaults to 10 seconds.
+	WriteTimeout time.Duration
+}
+
+type message struct {
+	data        []byte
+	messageType int
+}
+
+// Client a websocket connection registered in a `Hub`.
+//
+// Messages sent to the client are queued and written by a dedicated goroutine.
+// Therefore, once registered, the connection must not be written to directly:
+// use `Send()` instead. Reading from the connection is still the responsibility
+// of the `Controller`.
+type Client struct {
+	conn     *Conn
+	hub      *Hub
+	request  *goyave.Request
+	queue    chan *message
+	stop     chan struct{}
+	done     chan struct{}
+	rooms    map[string]struct{} // Protected by the hub's mutex
+	stopOnce sync.Once
+}
+
+// Conn returns the client's underlying connection.
+func (c *Client) Conn() *Conn {
+	return c.conn
+}
+
+// Request returns the original upgraded HTTP request.
+func (c *Client) Request() *goyave.Request {
+	return c.request
+}
+
+// Send queues a message for this client. If the queue is full, Send waits for
+// `HubOptions.SendTimeout`. If the message cannot be queued in time, the client is
+// unregistered, disconnected and `ErrSendQueueFull` is returned.
+//
+// Returns `ErrClientClosed` if the client has been unregistered.
+func (c *Client) Send(messageType int, data []byte) error {
+	select {
+	case <-c.stop:
+		return errors.New(ErrClientClosed)
+	default:
+	}
+
+	m := &message{messageType: messageType, data: data}
+	select {
+	case c.queue <- m:
+		return nil
+	default:
+	}
+
+	if timeout := c.hub.opts.SendTimeout; timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case c.queue <- m:
+			return nil
+		case <-c.stop:
+			return errors.New(ErrClientClosed)
+		case <-timer.C:
+		}
+	}
+
+	go func() {
+		c.hub.Unregister(c)
+		_ = c.conn.Close(ws.CloseTryAgainLater, SlowClientCloseMessage)
+	}()
+	return errors.New(ErrSendQueueFull)
+}
+
+// SendJSON encodes the given value as JSON and queues it as a text message.
+// See `Send()`.
+func (c *Client) SendJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.New(err)
+	}
+	return c.Send(ws.TextMessage, data)
+}
+
+// Join adds the client to the given room. Has no effect if the client
+// is not registered anymore.
+func (c *Client) Join(room string) {
+	h := c.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	c.rooms[room] = struct{}{}
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[*Client]struct{})
+		h.rooms[room] = members
+	}
+	members[c] = struct{}{}
+}
+
+// Leave removes the client from the given room.
+func (c *Client) Leave(room string) {
+	h := c.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leave(c, room)
+}
+
+// Rooms returns the rooms the client has joined.
+func (c *Client) Rooms() []string {
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+func (c *Client) writePump() {
+	defer close(c.done)
+	for {
+		select {
+		case m := <-c.queue:
+			if err := c.write(m); err != nil {
+				return
+			}
+		case <-c.stop:
+			// Drain the messages queued before the client was unregistered.
+			for {
+				select {
+				case m := <-c.queue:
+					if err := c.write(m); err != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) write(m *message) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.hub.opts.WriteTimeout)); err != nil {
+		return errors.New(err)
+	}
+	return errors.New(c.conn.WriteMessage(m.messageType, m.data))
+}
+
+// Hub a registry of websocket connections supporting rooms and broadcasting.
+//
+// Connections are registered by the `Controller`'s `Serve` function and must be
+// unregistered before it returns:
+//
+//	func (c *ChatController) Serve(conn *websocket.Conn, request *goyave.Request) error {
+//		client := c.hub.Register(conn, request)
+//		defer c.hub.Unregister(client)
+//		client.Join("general")
+//
+//		for {
+//			_, message, err := conn.ReadMessage()
+//			if err != nil {
+//				return errors.New(err)
+//			}
+//			c.hub.BroadcastTo("general", websocket.TextMessage, message)
+//		}
+//	}
+//
+// When the server stops, all the registered connections are closed using `CloseNormal()`
+// and the hub waits for them to be unregistered, within the `server.websocketCloseTimeout`
+// configuration entry.
+type Hub struct {
+	goyave.Component
+	opts    *HubOptions
+	clients map[*Client]struct{}
+	rooms   map[string]map[*Client]struct{}
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+}
+
+// NewHub creates a new `Hub` and registers a shutdown hook closing all
+// the registered connections when the server stops.
+// If `opts` is `nil`, the default options are used.
+func NewHub(server *goyave.Server, opts *HubOptions) *Hub {
+	o := HubOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 64
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = 10 * time.Second
+	}
+	h := &Hub{
+		opts:    &o,
+		clients: make(map[*Client]struct{}),
+		rooms:   make(map[string]map[*Client]struct{}),
+	}
+	h.Init(server)
+	server.RegisterShutdownHook(func(_ *goyave.Server) {
+		h.Close()
+	})
+	return h
+}
+
+// Register adds the given connection to the hub and starts writing the messages sent to it.
+// The returned client must be unregistered using `Unregister()` before the controller's `Serve`
+// function returns.
+//
+// If the hub is closed, the connection is closed and sending messages to the returned client
+// returns `ErrClientClosed`.
+func (h *Hub) Register(conn *Conn, request *goyave.Request) *Client {
+	c := &Client{
+		conn:    conn,
+		hub:     h,
+		request: request,
+		queue:   make(chan *message, h.opts.QueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		rooms:   make(map[string]struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		c.stopOnce.Do(func() { close(c.stop) })
+		close(c.done)
+		go func() {
+			_ = conn.CloseNormal()
+		}()
+		return c
+	}
+	h.clients[c] = struct{}{}
+	h.wg.Add(1)
+	go c.writePump()
+	return c
+}
+
+// Unregister removes the client from the hub and all its rooms. Messages already
+// queued are written before Unregister returns. Calling Unregister several
+// times has no effect.
+func (h *Hub) Unregister(c *Client) {
+	h.mu.Lock()
+	_, registered := h.clients[c]
+	if registered {
+		delete(h.clients, c)
+		for room := range c.rooms {
+			h.leave(c, room)
+		}
+	}
+	h.mu.Unlock()
+
+	c.stopOnce.Do(func() { close(c.stop) })
+	<-c.done
+	if registered {
+		h.wg.Done()
+	}
+}
+
+func (h *Hub) leave(c *Client, room string) {
+	delete(c.rooms, room)
+	if members, ok := h.rooms[room]; ok {
+		delete(members, c)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Clients returns all the registered clients.
+func (h *Hub) Clients() []*Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// RoomClients returns the clients that joined the given room.
+func (h *Hub) RoomClients(room string) []*Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	members := h.rooms[room]
+	clients := make([]*Client, 0, len(members))
+	for c := range members {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// Broadcast sends a message to all the registered clients.
+// Clients that cannot keep up are disconnected.
+func (h *Hub) Broadcast(messageType int, data []byte) {
+	broadcast(h.Clients(), messageType, data)
+}
+
+// BroadcastJSON encodes the given value as JSON and sends it as a text message
+// to all the registered clients.
+func (h *Hub) BroadcastJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.New(err)
+	}
+	h.Broadcast(ws.TextMessage, data)
+	return nil
+}
+
+// BroadcastTo sends a message to all the clients that joined the given room.
+// Clients that cannot keep up are disconnected.
+func (h *Hub) BroadcastTo(room string, messageType int, data []byte) {
+	broadcast(h.RoomClients(room), messageType, data)
+}
+
+// BroadcastToJSON encodes the given value as JSON and sends it as a text message
+// to all the clients that joined the given room.
+func (h *Hub) BroadcastToJSON(room string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.New(err)
+	}
+	h.BroadcastTo(room, ws.TextMessage, data)
+	return nil
+}
+
+func broadcast(clients []*Client, messageType int, data []byte) {
+	for _, c := range clients {
+		_ = c.Send(messageType, data)
+	}
+}
+
+// Close prevents new registrations and closes all the registered connections using
+// `CloseNormal()`. Close then waits for all the clients to be unregistered, or for the
+// `server.websocketCloseTimeout` to expire.
+//
+// Close is automatically called when the server stops.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	timeout := time.Duration(h.Config().GetInt("server.websocketCloseTimeout")) * time.Second
+	for _, c := range h.Clients() {
+		go func(c *Client) {
+			_ = c.conn.CloseNormal()
+		}(c)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
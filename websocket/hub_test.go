@@ -0,0 +1,240 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/testutil"
+)
+
+// runHubTest starts a server with a websocket route using the given serve function,
+// then runs the test function. The server is stopped once the test function returns.
+func runHubTest(t *testing.T, opts *HubOptions, serve func(hub *Hub, conn *Conn, r *goyave.Request) error, test func(hub *Hub, routeURL string)) {
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	server := testutil.NewTestServerWithOptions(t, prepareTestConfig())
+	hub := NewHub(server.Server, opts)
+	server.RegisterRoutes(func(_ *goyave.Server, r *goyave.Router) {
+		upgrader := New(&testController{
+			t:  t,
+			wg: &sync.WaitGroup{},
+			serve: func(conn *Conn, r *goyave.Request) error {
+				return serve(hub, conn, r)
+			},
+			checkOrigin: func(_ *goyave.Request) bool { return true },
+		})
+		r.Subrouter("/websocket").Controller(upgrader)
+	})
+
+	server.RegisterStartupHook(func(s *goyave.Server) {
+		defer func() {
+			server.Stop()
+			wg.Done()
+		}()
+		route := s.Router().GetSubrouters()[0].GetRoutes()[0]
+		test(hub, "ws"+strings.TrimPrefix(route.BuildURL(), "http"))
+	})
+
+	go func() {
+		assert.NoError(t, server.Start())
+		wg.Done()
+	}()
+	wg.Wait()
+}
+
+func dialHub(t *testing.T, routeURL string) *ws.Conn {
+	conn, resp, err := ws.DefaultDialer.Dial(routeURL, nil)
+	require.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func waitClients(hub *Hub, n int) {
+	for len(hub.Clients()) != n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func readText(t *testing.T, conn *ws.Conn) string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	mt, message, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, ws.TextMessage, mt)
+	return string(message)
+}
+
+func TestHub(t *testing.T) {
+	t.Run("NewHub", func(t *testing.T) {
+		server := testutil.NewTestServerWithOptions(t, prepareTestConfig())
+		hub := NewHub(server.Server, nil)
+		assert.Equal(t, &HubOptions{QueueSize: 64, WriteTimeout: 10 * time.Second}, hub.opts)
+		assert.Equal(t, server.Server, hub.Server())
+		assert.NotNil(t, hub.clients)
+		assert.NotNil(t, hub.rooms)
+
+		opts := &HubOptions{QueueSize: 8}
+		hub = NewHub(server.Server, opts)
+		assert.Equal(t, &HubOptions{QueueSize: 8}, opts) // The caller's options are not modified
+		assert.Equal(t, &HubOptions{QueueSize: 8, WriteTimeout: 10 * time.Second}, hub.opts)
+	})
+
+	t.Run("rooms_broadcast_and_shutdown", func(t *testing.T) {
+		serve := func(hub *Hub, conn *Conn, r *goyave.Request) error {
+			client := hub.Register(conn, r)
+			defer hub.Unregister(client)
+			room := r.Request().URL.Query().Get("room")
+			client.Join(room)
+			for {
+				_, message, err := conn.ReadMessage()
+				if err != nil {
+					return err
+				}
+				hub.BroadcastTo(room, ws.TextMessage, message)
+			}
+		}
+
+		runHubTest(t, nil, serve, func(hub *Hub, routeURL string) {
+			a1 := dialHub(t, routeURL+"?room=a")
+			a2 := dialHub(t, routeURL+"?room=a")
+			b := dialHub(t, routeURL+"?room=b")
+			waitClients(hub, 3)
+			for len(hub.RoomClients("a")) != 2 || len(hub.RoomClients("b")) != 1 {
+				time.Sleep(time.Millisecond)
+			}
+
+			require.NoError(t, a1.WriteMessage(ws.TextMessage, []byte("hello a")))
+			assert.Equal(t, "hello a", readText(t, a1))
+			assert.Equal(t, "hello a", readText(t, a2))
+
+			require.NoError(t, hub.BroadcastJSON(map[string]string{"msg": "hello all"}))
+			for _, c := range []*ws.Conn{a1, a2, b} {
+				// b didn't receive the message sent to room a
+				assert.Equal(t, `{"msg":"hello all"}`, readText(t, c))
+			}
+
+			require.NoError(t, hub.BroadcastToJSON("b", "hello b"))
+			assert.Equal(t, `"hello b"`, readText(t, b))
+			require.Error(t, hub.BroadcastJSON(func() {}))
+			require.Error(t, hub.BroadcastToJSON("b", func() {}))
+
+			// Server stop closes all connections gracefully
+			go func() {
+				for _, c := range []*ws.Conn{a1, a2, b} {
+					assert.NoError(t, c.SetReadDeadline(time.Now().Add(2*time.Second)))
+					_, _, err := c.ReadMessage()
+					assert.Equal(t, &ws.CloseError{Code: ws.CloseNormalClosure, Text: NormalClosureMessage}, err)
+				}
+			}()
+		})
+	})
+
+	t.Run("Client", func(t *testing.T) {
+		serve := func(hub *Hub, conn *Conn, r *goyave.Request) error {
+			client := hub.Register(conn, r)
+			defer hub.Unregister(client)
+
+			assert.Same(t, conn, client.Conn())
+			assert.Same(t, r, client.Request())
+
+			client.Join("a")
+			client.Join("b")
+			assert.ElementsMatch(t, []string{"a", "b"}, client.Rooms())
+			client.Leave("a")
+			assert.Equal(t, []string{"b"}, client.Rooms())
+			assert.Empty(t, hub.RoomClients("a"))
+			assert.NotContains(t, hub.rooms, "a")
+
+			assert.NoError(t, client.SendJSON(map[string]string{"msg": "hello"}))
+			assert.Error(t, client.SendJSON(func() {}))
+
+			hub.Unregister(client)
+			hub.Unregister(client) // Can be called several times
+			assert.Empty(t, hub.Clients())
+			assert.Empty(t, hub.RoomClients("b"))
+			assert.ErrorIs(t, client.Send(ws.TextMessage, []byte("hello")), ErrClientClosed)
+			client.Join("c") // Not registered anymore
+			assert.Empty(t, client.Rooms())
+			return nil
+		}
+
+		runHubTest(t, nil, serve, func(_ *Hub, routeURL string) {
+			conn := dialHub(t, routeURL)
+			// Queued messages are written before Unregister returns
+			assert.Equal(t, `{"msg":"hello"}`, readText(t, conn))
+			_, _, err := conn.ReadMessage()
+			assert.Equal(t, &ws.CloseError{Code: ws.CloseNormalClosure, Text: NormalClosureMessage}, err)
+		})
+	})
+
+	t.Run("slow_client", func(t *testing.T) {
+		serve := func(hub *Hub, conn *Conn, r *goyave.Request) error {
+			client := hub.Register(conn, r)
+			defer hub.Unregister(client)
+
+			// The client doesn't read, the write pump eventually blocks and the queue fills up.
+			data := make([]byte, 1<<20)
+			for range 256 {
+				if err := client.Send(ws.BinaryMessage, data); err != nil {
+					assert.ErrorIs(t, err, ErrSendQueueFull)
+					return nil
+				}
+			}
+			return fmt.Errorf("queue never filled up")
+		}
+
+		runHubTest(t, &HubOptions{QueueSize: 1, SendTimeout: time.Millisecond}, serve, func(hub *Hub, routeURL string) {
+			_ = dialHub(t, routeURL)
+			waitClients(hub, 1)
+			waitClients(hub, 0)
+		})
+	})
+
+	t.Run("Register_closed", func(t *testing.T) {
+		serve := func(hub *Hub, conn *Conn, r *goyave.Request) error {
+			hub.Close()
+			client := hub.Register(conn, r)
+			defer hub.Unregister(client)
+			assert.Empty(t, hub.Clients())
+			assert.ErrorIs(t, client.Send(ws.TextMessage, []byte("hello")), ErrClientClosed)
+			return nil
+		}
+
+		runHubTest(t, nil, serve, func(_ *Hub, routeURL string) {
+			conn := dialHub(t, routeURL)
+			_, _, err := conn.ReadMessage()
+			assert.Equal(t, &ws.CloseError{Code: ws.CloseNormalClosure, Text: NormalClosureMessage}, err)
+		})
+	})
+
+	t.Run("Close_timeout", func(t *testing.T) {
+		serve := func(hub *Hub, conn *Conn, r *goyave.Request) error {
+			client := hub.Register(conn, r)
+			// Never reads nor unregisters before the hub is closed
+			<-client.stop
+			return nil
+		}
+
+		runHubTest(t, nil, serve, func(hub *Hub, routeURL string) {
+			_ = dialHub(t, routeURL)
+			waitClients(hub, 1)
+
+			start := time.Now()
+			hub.Close()
+			assert.GreaterOrEqual(t, time.Since(start), time.Second) // websocketCloseTimeout
+			for _, c := range hub.Clients() {
+				hub.Unregister(c)
+			}
+		})
+	})
+}
@@ -0,0 +1,264 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"gorm.io/gorm"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/util/errors"
+	"goyave.dev/goyave/v5/util/typeutil"
+	"goyave.dev/goyave/v5/validation"
+)
+
+const (
+	// ErrorMessageType the type of the messages sent by the `MessageRouter`
+	// when an incoming message cannot be handled.
+	ErrorMessageType = "error"
+)
+
+// Envelope the JSON structure of the messages handled by the `MessageRouter`.
+//
+//	{"type": "chat.send", "id": "1", "payload": {"text": "hello"}}
+//
+// The optional ID is set by the client and copied in the replies so the client can
+// correlate them with its messages.
+type Envelope struct {
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+}
+
+// ErrorEnvelope the JSON structure of the messages sent by the `MessageRouter`
+// when an incoming message cannot be handled. `Status` uses HTTP status codes semantics:
+//   - 400: the message is not a valid envelope
+//   - 404: no handler is registered for the message type
+//   - 422: the payload failed validation, `Error` contains the `*validation.Errors`
+//   - 500: the handler returned an error
+//
+// For other statuses, `Error` contains a translated message.
+type ErrorEnvelope struct {
+	Error  any    `json:"error"`
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status"`
+}
+
+// MessageHandler function handling a message dispatched by the `MessageRouter`.
+// If the handler returns an error, the error is logged and an `ErrorEnvelope` with
+// the status 500 is sent to the client. The connection is not closed.
+type MessageHandler func(ctx *MessageContext) error
+
+// Typed returns a `MessageHandler` converting the validated payload to `T` using
+// `typeutil.Convert` before calling the given handler.
+//
+//	router.Handle("chat.send", websocket.Typed(ctrl.Send)).Validate(ctrl.SendRules)
+//
+//	func (ctrl *ChatController) Send(ctx *websocket.MessageContext, payload dto.ChatMessage) error {
+//		return ctx.Reply(map[string]string{"status": "sent"})
+//	}
+func Typed[T any](handler func(ctx *MessageContext, payload T) error) MessageHandler {
+	return func(ctx *MessageContext) error {
+		payload, err := typeutil.Convert[T](ctx.Data)
+		if err != nil {
+			return errors.New(err)
+		}
+		return handler(ctx, payload)
+	}
+}
+
+// MessageRoute a handler registered in a `MessageRouter` for a message type.
+type MessageRoute struct {
+	handler MessageHandler
+	rules   goyave.RuleSetFunc
+}
+
+// Validate sets the validation rules applied to the message payloads before they are
+// passed to the handler. The request given to the `RuleSetFunc` is the original
+// upgraded HTTP request.
+func (r *MessageRoute) Validate(rules goyave.RuleSetFunc) *MessageRoute {
+	r.rules = rules
+	return r
+}
+
+// MessageContext the context of a message dispatched by the `MessageRouter`.
+// It gives access to the same accessors as a `goyave.Component`.
+type MessageContext struct {
+	goyave.Component
+
+	// Data the payload of the message, after validation if the route has validation rules.
+	Data any
+
+	Conn *Conn
+
+	// Request the original upgraded HTTP request. Its `Lang` is used to translate
+	// the validation and error messages.
+	Request *goyave.Request
+
+	send func(v any) error
+
+	Type string
+	ID   string
+}
+
+// Reply sends the given payload to the client using the type and ID of the
+// message being handled.
+func (c *MessageContext) Reply(payload any) error {
+	return c.Send(c.Type, payload)
+}
+
+// Send sends the given payload to the client with the given message type.
+// The ID of the message being handled is copied in the envelope.
+func (c *MessageContext) Send(messageType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errors.New(err)
+	}
+	return c.send(&Envelope{Type: messageType, ID: c.ID, Payload: data})
+}
+
+// MessageRouter dispatches incoming JSON messages to handlers depending
+// on their `type` field. The messages are expected to follow the `Envelope` structure.
+//
+// The messages read from a connection are handled sequentially.
+//
+//	func (c *ChatController) Init(server *goyave.Server) {
+//		c.Component.Init(server)
+//		c.router = websocket.NewMessageRouter(server)
+//		c.router.Handle("chat.send", websocket.Typed(c.Send)).Validate(c.SendRules)
+//	}
+//
+//	func (c *ChatController) Serve(conn *websocket.Conn, request *goyave.Request) error {
+//		return c.router.Serve(conn, request)
+//	}
+type MessageRouter struct {
+	goyave.Component
+	routes map[string]*MessageRoute
+}
+
+// NewMessageRouter creates a new `MessageRouter` without any route.
+func NewMessageRouter(server *goyave.Server) *MessageRouter {
+	r := &MessageRouter{
+		routes: make(map[string]*MessageRoute),
+	}
+	r.Init(server)
+	return r
+}
+
+// Handle registers a handler for the given message type. If a handler
+// is already registered for this type, it is replaced.
+func (r *MessageRouter) Handle(messageType string, handler MessageHandler) *MessageRoute {
+	route := &MessageRoute{handler: handler}
+	r.routes[messageType] = route
+	return route
+}
+
+// Serve reads messages from the given connection and dispatches them until a read
+// error occurs. The read error is returned so it can be handled by the `Upgrader`.
+//
+// Replies are written directly to the connection. If the connection is registered
+// in a `Hub`, use `ServeClient` instead.
+func (r *MessageRouter) Serve(conn *Conn, request *goyave.Request) error {
+	return r.serve(conn, request, func(v any) error {
+		return errors.New(conn.WriteJSON(v))
+	})
+}
+
+// ServeClient reads messages from the connection of the given hub client and dispatches
+// them until a read error occurs. Replies are queued using `Client.SendJSON`.
+func (r *MessageRouter) ServeClient(client *Client) error {
+	return r.serve(client.Conn(), client.Request(), client.SendJSON)
+}
+
+func (r *MessageRouter) serve(conn *Conn, request *goyave.Request, send func(v any) error) error {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return errors.New(err)
+		}
+		if err := r.dispatch(conn, request, message, send); err != nil {
+			return err
+		}
+	}
+}
+
+// dispatch handles a single message. Only errors occurring while
+// writing a reply are returned.
+func (r *MessageRouter) dispatch(conn *Conn, request *goyave.Request, message []byte, send func(v any) error) error {
+	envelope := &Envelope{}
+	if err := json.Unmarshal(message, envelope); err != nil || envelope.Type == "" {
+		return r.sendError(send, envelope.ID, http.StatusBadRequest, request.Lang.Get("websocket.invalid-message"))
+	}
+
+	route, ok := r.routes[envelope.Type]
+	if !ok {
+		return r.sendError(send, envelope.ID, http.StatusNotFound, request.Lang.Get("websocket.unknown-message-type"))
+	}
+
+	var data any
+	if len(envelope.Payload) > 0 {
+		if err := json.Unmarshal(envelope.Payload, &data); err != nil {
+			return r.sendError(send, envelope.ID, http.StatusBadRequest, request.Lang.Get("websocket.invalid-message"))
+		}
+	}
+
+	if route.rules != nil {
+		// The request context is canceled once the connection is upgraded.
+		ctx := context.WithoutCancel(request.Context())
+		var db *gorm.DB
+		if r.Server().HasDB() {
+			db = r.DB().WithContext(ctx)
+		}
+		opt := &validation.Options{
+			Context:  ctx,
+			Data:     data,
+			Rules:    route.rules(request).AsRules(),
+			Language: request.Lang,
+			DB:       db,
+			Config:   r.Config(),
+			Logger:   r.Logger(),
+			Extra: map[any]any{
+				validation.ExtraRequest{}: request,
+			},
+		}
+		errsBag, errs := validation.Validate(opt)
+		if len(errs) != 0 {
+			r.Logger().Error(errors.New(errs))
+			return r.sendError(send, envelope.ID, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		}
+		if errsBag != nil {
+			return r.sendError(send, envelope.ID, http.StatusUnprocessableEntity, errsBag)
+		}
+		data = opt.Data
+	}
+
+	ctx := &MessageContext{
+		Component: r.Component,
+		Data:      data,
+		Conn:      conn,
+		Request:   request,
+		Type:      envelope.Type,
+		ID:        envelope.ID,
+		send:      send,
+	}
+	if err := route.handler(ctx); err != nil {
+		e := errors.New(err)
+		r.Logger().Error(e)
+		var message any = http.StatusText(http.StatusInternalServerError)
+		if r.Config().GetBool("app.debug") {
+			message = e.Error()
+		}
+		return r.sendError(send, envelope.ID, http.StatusInternalServerError, message)
+	}
+	return nil
+}
+
+func (r *MessageRouter) sendError(send func(v any) error, id string, status int, err any) error {
+	return send(&ErrorEnvelope{
+		Type:   ErrorMessageType,
+		ID:     id,
+		Status: status,
+		Error:  err,
+	})
+}
@@ -0,0 +1,147 @@
+package websocket
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/validation"
+)
+
+type testChatMessage struct {
+	Text string `json:"text"`
+}
+
+func prepareMessageRouter(server *goyave.Server) *MessageRouter {
+	router := NewMessageRouter(server)
+	router.Handle("chat.send", Typed(func(ctx *MessageContext, payload testChatMessage) error {
+		if ctx.Server() != server || ctx.Config() == nil || ctx.Request == nil || ctx.Conn == nil {
+			return fmt.Errorf("missing accessors")
+		}
+		return ctx.Reply(map[string]string{"echo": payload.Text})
+	})).Validate(func(_ *goyave.Request) validation.RuleSet {
+		return validation.RuleSet{
+			{Path: validation.CurrentElement, Rules: validation.List{validation.Required(), validation.Object()}},
+			{Path: "text", Rules: validation.List{validation.Required(), validation.String()}},
+		}
+	})
+	router.Handle("chat.notify", func(ctx *MessageContext) error {
+		return ctx.Send("chat.notification", ctx.Data)
+	})
+	router.Handle("fail", func(_ *MessageContext) error {
+		return fmt.Errorf("handler error")
+	})
+	router.Handle("convert_fail", Typed(func(_ *MessageContext, _ int) error {
+		return nil
+	}))
+	router.Handle("reply_fail", func(ctx *MessageContext) error {
+		return ctx.Reply(func() {})
+	})
+	return router
+}
+
+func exchangeJSON(t *testing.T, conn *ws.Conn, message string) map[string]any {
+	require.NoError(t, conn.WriteMessage(ws.TextMessage, []byte(message)))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	reply := map[string]any{}
+	require.NoError(t, conn.ReadJSON(&reply))
+	return reply
+}
+
+func TestMessageRouter(t *testing.T) {
+	cases := []struct {
+		want    map[string]any
+		desc    string
+		message string
+	}{
+		{
+			desc:    "typed_handler",
+			message: `{"type":"chat.send","id":"1","payload":{"text":"hello"}}`,
+			want:    map[string]any{"type": "chat.send", "id": "1", "payload": map[string]any{"echo": "hello"}},
+		},
+		{
+			desc:    "send_other_type",
+			message: `{"type":"chat.notify","payload":["a","b"]}`,
+			want:    map[string]any{"type": "chat.notification", "payload": []any{"a", "b"}},
+		},
+		{
+			desc:    "validation_error",
+			message: `{"type":"chat.send","id":"2","payload":{"text":1}}`,
+			want: map[string]any{"type": "error", "id": "2", "status": float64(http.StatusUnprocessableEntity), "error": map[string]any{
+				"fields": map[string]any{"text": map[string]any{"errors": []any{"The text must be a string."}}},
+			}},
+		},
+		{
+			desc:    "validation_error_no_payload",
+			message: `{"type":"chat.send","id":"3"}`,
+			want: map[string]any{"type": "error", "id": "3", "status": float64(http.StatusUnprocessableEntity), "error": map[string]any{
+				"errors": []any{"The body is required.", "The body must be an object."},
+			}},
+		},
+		{
+			desc:    "unknown_type",
+			message: `{"type":"unknown","id":"4"}`,
+			want:    map[string]any{"type": "error", "id": "4", "status": float64(http.StatusNotFound), "error": "Unknown message type."},
+		},
+		{
+			desc:    "invalid_json",
+			message: `{"type":`,
+			want:    map[string]any{"type": "error", "status": float64(http.StatusBadRequest), "error": `The message must be a JSON object with a "type" field and an optional "payload".`},
+		},
+		{
+			desc:    "missing_type",
+			message: `{"id":"5"}`,
+			want:    map[string]any{"type": "error", "id": "5", "status": float64(http.StatusBadRequest), "error": `The message must be a JSON object with a "type" field and an optional "payload".`},
+		},
+		{
+			desc:    "handler_error",
+			message: `{"type":"fail","id":"6"}`,
+			want:    map[string]any{"type": "error", "id": "6", "status": float64(http.StatusInternalServerError), "error": http.StatusText(http.StatusInternalServerError)},
+		},
+		{
+			desc:    "conversion_error",
+			message: `{"type":"convert_fail","payload":"not an int"}`,
+			want:    map[string]any{"type": "error", "status": float64(http.StatusInternalServerError), "error": http.StatusText(http.StatusInternalServerError)},
+		},
+		{
+			desc:    "reply_error",
+			message: `{"type":"reply_fail"}`,
+			want:    map[string]any{"type": "error", "status": float64(http.StatusInternalServerError), "error": http.StatusText(http.StatusInternalServerError)},
+		},
+	}
+
+	serveConn := func(hub *Hub, conn *Conn, r *goyave.Request) error {
+		return prepareMessageRouter(hub.Server()).Serve(conn, r)
+	}
+	serveClient := func(hub *Hub, conn *Conn, r *goyave.Request) error {
+		client := hub.Register(conn, r)
+		defer hub.Unregister(client)
+		return prepareMessageRouter(hub.Server()).ServeClient(client)
+	}
+
+	for desc, serve := range map[string]func(*Hub, *Conn, *goyave.Request) error{"Serve": serveConn, "ServeClient": serveClient} {
+		t.Run(desc, func(t *testing.T) {
+			runHubTest(t, nil, serve, func(_ *Hub, routeURL string) {
+				conn := dialHub(t, routeURL)
+				for _, c := range cases {
+					t.Run(c.desc, func(t *testing.T) {
+						assert.Equal(t, c.want, exchangeJSON(t, conn, c.message))
+					})
+				}
+			})
+		})
+	}
+
+	t.Run("Handle_replaces", func(t *testing.T) {
+		router := NewMessageRouter(nil)
+		first := router.Handle("type", func(_ *MessageContext) error { return nil })
+		second := router.Handle("type", func(_ *MessageContext) error { return nil })
+		assert.NotSame(t, first, second)
+		assert.Same(t, second, router.routes["type"])
+	})
+}
@@ -42,8 +42,8 @@ type Controller interface {
 	// of the HTTP handler that upgraded the connection.
 	//
 	// By default, the server shutdown doesn't wait for hijacked connections to be closed gracefully.
-	// It is advised to register a shutdown hook blocking until all the connections are gracefully
-	// closed using `*websocket.Conn.CloseNormal()`.
+	// It is advised to register the connections in a `websocket.Hub`, which gracefully closes
+	// them using `*websocket.Conn.CloseNormal()` when the server stops.
 	//
 	// The following websocket Handler is a simple example of an "echo" feature using websockets:
 	//
@@ -122,6 +122,20 @@ type HeaderUpgrader interface {
 	UpgradeHeaders(r *goyave.Request) http.Header
 }
 
+// HandshakeAuthenticator authenticates the client during the opening handshake, before
+// the connection is upgraded. `*auth.Handler[T]` implements this interface, allowing the use
+// of any `auth.Authenticator[T]`:
+//
+//	upgrader := websocket.New(controller)
+//	upgrader.Authenticator = auth.Middleware[dto.User](&auth.JWTAuthenticator[dto.User]{})
+type HandshakeAuthenticator interface {
+	// AuthenticateRequest returns true if the client is authenticated. In this case,
+	// the user should be available in the request's `context.Context` and retrievable
+	// using `auth.UserFromContext`. Otherwise, the implementation writes the error response
+	// and returns false.
+	AuthenticateRequest(response *goyave.Response, request *goyave.Request) bool
+}
+
 // Upgrader is responsible for the upgrade of HTTP connections to
 // websocket connections.
 type Upgrader struct {
@@ -129,9 +143,38 @@ type Upgrader struct {
 
 	Controller Controller
 
+	// Authenticator if not nil, the client is authenticated during the opening handshake.
+	// The connection is not upgraded if the authentication fails. The authenticated user
+	// is available in `Controller.Serve` using `auth.UserFromContext(request.Context())`.
+	Authenticator HandshakeAuthenticator
+
 	// Settings the parameters for upgrading the connection. "Error" and "CheckOrigin" are
 	// ignored: use implementations of the interfaces `UpgradeErrorHandler` and `ErrorHandler`.
 	Settings ws.Upgrader
+
+	// PingInterval if greater than zero, a ping is sent to the client at this interval.
+	// The read deadline of the connection is extended each time a pong is received.
+	// If the client doesn't answer, reading from the connection fails after
+	// `PingInterval + PongTimeout`.
+	PingInterval time.Duration
+
+	// PongTimeout the time allowed to the client to answer a ping. Only used if
+	// `PingInterval` is greater than zero. Defaults to `PingInterval`.
+	PongTimeout time.Duration
+
+	// MaxMessageSize the maximum size in bytes of the messages read from the client.
+	// If a message exceeds the limit, the connection is closed with the status code 1009
+	// (message too big) and reading from the connection fails. Ignored if zero or negative.
+	MaxMessageSize int64
+
+	// CompressionLevel the flate compression level used for outgoing messages if
+	// `Compression` is enabled. Defaults to `flate.DefaultCompression` if zero.
+	CompressionLevel int
+
+	// Compression enables the negotiation of the per message compression extension
+	// (permessage-deflate, RFC 7692). Messages are only compressed if the client supports it.
+	// This is equivalent to setting `Settings.EnableCompression`.
+	Compression bool
 }
 
 // New create a new Upgrader with default settings.
@@ -184,6 +227,7 @@ func (u *Upgrader) makeUpgrader(request *goyave.Request) *ws.Upgrader {
 	upgrader := u.Settings
 	upgrader.Error = a.onError
 	upgrader.CheckOrigin = a.getCheckOriginFunc()
+	upgrader.EnableCompression = upgrader.EnableCompression || u.Compression
 	return &upgrader
 }
 
@@ -210,9 +254,19 @@ func (u *Upgrader) makeUpgrader(request *goyave.Request) *ws.Upgrader {
 // This HTTP Handler returns once the connection has been successfully upgraded. That means
 // that, for example, logging middleware will log the request right away instead of waiting
 // for the websocket connection to be closed.
+//
+// If the Upgrader has an `Authenticator`, the client is authenticated before the
+// connection is upgraded.
 func (u *Upgrader) Handler() goyave.Handler {
 	u.Controller.Init(u.Server())
+	if c, ok := u.Authenticator.(goyave.Composable); ok {
+		c.Init(u.Server())
+	}
 	return func(response *goyave.Response, request *goyave.Request) {
+		if u.Authenticator != nil && !u.Authenticator.AuthenticateRequest(response, request) {
+			return
+		}
+
 		var headers http.Header
 		if headerUpgrader, ok := u.Controller.(HeaderUpgrader); ok {
 			headers = headerUpgrader.UpgradeHeaders(request)
@@ -230,9 +284,12 @@ func (u *Upgrader) Handler() goyave.Handler {
 
 func (u *Upgrader) serve(c *ws.Conn, request *goyave.Request, handler func(*Conn, *goyave.Request) error) {
 	conn := newConn(c, time.Duration(u.Config().GetInt("server.websocketCloseTimeout"))*time.Second)
+	u.configureConn(conn)
+	stopPing := u.startPing(conn)
 	panicked := true
 	var err error
 	defer func() { // Panic recovery
+		stopPing()
 		if panicReason := recover(); panicReason != nil || panicked {
 			err = errors.NewSkip(panicReason, 4) // Skipped: runtime.Callers, NewSkip, this func, runtime.panic
 		}
@@ -260,6 +317,57 @@ func (u *Upgrader) serve(c *ws.Conn, request *goyave.Request, handler func(*Conn
 	panicked = false
 }
 
+func (u *Upgrader) configureConn(conn *Conn) {
+	if u.MaxMessageSize > 0 {
+		conn.SetReadLimit(u.MaxMessageSize)
+	}
+	if u.CompressionLevel != 0 {
+		if err := conn.SetCompressionLevel(u.CompressionLevel); err != nil {
+			u.Logger().Error(errors.New(err))
+		}
+	}
+}
+
+// startPing sends pings to the client at the `PingInterval` and extends the read deadline
+// of the connection every time a pong is received. Returns a function stopping the pings and
+// waiting for the goroutine to exit.
+func (u *Upgrader) startPing(conn *Conn) func() {
+	if u.PingInterval <= 0 {
+		return func() {}
+	}
+	pongTimeout := u.PongTimeout
+	if pongTimeout <= 0 {
+		pongTimeout = u.PingInterval
+	}
+	wait := u.PingInterval + pongTimeout
+	_ = conn.SetReadDeadline(time.Now().Add(wait))
+	conn.SetPongHandler(func(_ string) error {
+		return conn.SetReadDeadline(time.Now().Add(wait))
+	})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(u.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(ws.PingMessage, nil, time.Now().Add(pongTimeout)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
 type adapter struct {
 	upgradeErrorHandler upgradeErrorHandlerFunc
 	checkOrigin         func(r *goyave.Request) bool
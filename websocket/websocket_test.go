@@ -2,17 +2,21 @@ package websocket
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"goyave.dev/goyave/v5"
+	"goyave.dev/goyave/v5/auth"
 	"goyave.dev/goyave/v5/config"
 	"goyave.dev/goyave/v5/slog"
 	"goyave.dev/goyave/v5/util/errors"
@@ -246,6 +250,12 @@ func TestMakeUpgrader(t *testing.T) {
 	u = upgrader.makeUpgrader(req)
 	assert.Equal(t, upgrader.Settings.EnableCompression, u.EnableCompression)
 
+	upgrader.Settings.EnableCompression = false
+	upgrader.Compression = true
+	u = upgrader.makeUpgrader(req)
+	assert.True(t, u.EnableCompression)
+	upgrader.Compression = false
+
 	upgradeErrorExecuted := false
 	checkOriginExecuted := false
 	upgrader.Controller = &testControllerWithErrorHandler{
@@ -657,3 +667,188 @@ func TestCloseWriteTimeout(t *testing.T) {
 	}()
 	wg.Wait()
 }
+
+// runUpgraderTest starts a server with a websocket route using the given serve function
+// and the upgrader configured by the given function, then runs the test function.
+// The server is stopped once the test function returns.
+func runUpgraderTest(t *testing.T, opts goyave.Options, configure func(*Upgrader), serve func(conn *Conn, r *goyave.Request) error, test func(routeURL string)) {
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	server := testutil.NewTestServerWithOptions(t, opts)
+	server.Logger = slog.New(slog.NewHandler(false, &bytes.Buffer{}))
+	server.RegisterRoutes(func(_ *goyave.Server, r *goyave.Router) {
+		upgrader := New(&testController{
+			t:           t,
+			wg:          &sync.WaitGroup{},
+			serve:       serve,
+			checkOrigin: func(_ *goyave.Request) bool { return true },
+		})
+		configure(upgrader)
+		r.Subrouter("/websocket").Controller(upgrader)
+	})
+
+	server.RegisterStartupHook(func(s *goyave.Server) {
+		defer func() {
+			server.Stop()
+			wg.Done()
+		}()
+		route := s.Router().GetSubrouters()[0].GetRoutes()[0]
+		test("ws" + strings.TrimPrefix(route.BuildURL(), "http"))
+	})
+
+	go func() {
+		assert.NoError(t, server.Start())
+		wg.Done()
+	}()
+	wg.Wait()
+}
+
+func TestUpgraderOptions(t *testing.T) {
+	t.Run("ping", func(t *testing.T) {
+		serveErr := make(chan error, 1)
+		serve := func(conn *Conn, _ *goyave.Request) error {
+			_, _, err := conn.ReadMessage()
+			serveErr <- err
+			return err
+		}
+		configure := func(u *Upgrader) {
+			u.PingInterval = 10 * time.Millisecond
+			u.PongTimeout = 20 * time.Millisecond
+		}
+
+		runUpgraderTest(t, prepareTestConfig(), configure, serve, func(routeURL string) {
+			conn := dialHub(t, routeURL)
+			pings := atomic.Int32{}
+			defaultPingHandler := conn.PingHandler()
+			conn.SetPingHandler(func(appData string) error {
+				pings.Add(1)
+				return defaultPingHandler(appData)
+			})
+
+			// Reading answers the pings, keeping the connection alive
+			go func() {
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+			time.Sleep(100 * time.Millisecond)
+			select {
+			case err := <-serveErr:
+				assert.Fail(t, "connection should be alive", err)
+			default:
+			}
+			assert.GreaterOrEqual(t, pings.Load(), int32(2))
+			assert.NoError(t, conn.WriteControl(ws.CloseMessage, ws.FormatCloseMessage(ws.CloseNormalClosure, ""), time.Now().Add(time.Second)))
+			assert.True(t, IsCloseError(<-serveErr))
+		})
+	})
+
+	t.Run("pong_timeout", func(t *testing.T) {
+		serveErr := make(chan error, 1)
+		serve := func(conn *Conn, _ *goyave.Request) error {
+			_, _, err := conn.ReadMessage()
+			serveErr <- err
+			return err
+		}
+		configure := func(u *Upgrader) {
+			u.PingInterval = 10 * time.Millisecond
+		}
+
+		runUpgraderTest(t, prepareTestConfig(), configure, serve, func(routeURL string) {
+			_ = dialHub(t, routeURL) // Never reads so never answers pings
+			select {
+			case err := <-serveErr:
+				var netErr interface{ Timeout() bool }
+				require.ErrorAs(t, err, &netErr)
+				assert.True(t, netErr.Timeout())
+			case <-time.After(time.Second):
+				assert.Fail(t, "read deadline not exceeded")
+			}
+		})
+	})
+
+	t.Run("max_message_size", func(t *testing.T) {
+		serveErr := make(chan error, 1)
+		serve := func(conn *Conn, _ *goyave.Request) error {
+			_, _, err := conn.ReadMessage()
+			serveErr <- err
+			return err
+		}
+		configure := func(u *Upgrader) {
+			u.MaxMessageSize = 8
+		}
+
+		runUpgraderTest(t, prepareTestConfig(), configure, serve, func(routeURL string) {
+			conn := dialHub(t, routeURL)
+			assert.NoError(t, conn.WriteMessage(ws.TextMessage, []byte("this message is too long")))
+			assert.ErrorIs(t, <-serveErr, ws.ErrReadLimit)
+			_, _, err := conn.ReadMessage()
+			closeErr := &ws.CloseError{}
+			require.ErrorAs(t, err, &closeErr)
+			assert.Equal(t, ws.CloseMessageTooBig, closeErr.Code)
+		})
+	})
+
+	t.Run("compression", func(t *testing.T) {
+		configure := func(u *Upgrader) {
+			u.Compression = true
+			u.CompressionLevel = 9
+		}
+
+		runUpgraderTest(t, prepareTestConfig(), configure, nil, func(routeURL string) {
+			dialer := *ws.DefaultDialer
+			dialer.EnableCompression = true
+			conn, resp, err := dialer.Dial(routeURL, nil)
+			require.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+			defer func() {
+				assert.NoError(t, conn.Close())
+			}()
+			assert.Contains(t, resp.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate")
+
+			message := []byte(strings.Repeat("hello world ", 100))
+			assert.NoError(t, conn.WriteMessage(ws.TextMessage, message))
+			_, data, err := conn.ReadMessage()
+			require.NoError(t, err)
+			assert.Equal(t, message, data)
+			assert.NoError(t, conn.WriteControl(ws.CloseMessage, ws.FormatCloseMessage(ws.CloseNormalClosure, ""), time.Now().Add(time.Second)))
+		})
+	})
+
+	t.Run("authenticator", func(t *testing.T) {
+		opts := prepareTestConfig()
+		opts.Config.Set("auth.basic.username", "admin")
+		opts.Config.Set("auth.basic.password", "secret")
+
+		users := make(chan *auth.BasicUser, 1)
+		serve := func(_ *Conn, r *goyave.Request) error {
+			users <- auth.UserFromContext[auth.BasicUser](r.Context())
+			return nil
+		}
+		configure := func(u *Upgrader) {
+			u.Authenticator = auth.Middleware[auth.BasicUser](&auth.ConfigBasicAuthenticator{})
+		}
+
+		runUpgraderTest(t, opts, configure, serve, func(routeURL string) {
+			conn, resp, err := ws.DefaultDialer.Dial(routeURL, nil)
+			require.ErrorIs(t, err, ws.ErrBadHandshake)
+			assert.Nil(t, conn)
+			assert.NoError(t, resp.Body.Close())
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			assert.Equal(t, `Basic realm="Authorization required", charset="UTF-8"`, resp.Header.Get("WWW-Authenticate"))
+
+			headers := http.Header{}
+			headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:secret")))
+			conn, resp, err = ws.DefaultDialer.Dial(routeURL, headers)
+			require.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+			defer func() {
+				assert.NoError(t, conn.Close())
+			}()
+			assert.Equal(t, &auth.BasicUser{Name: "admin"}, <-users)
+		})
+	})
+}